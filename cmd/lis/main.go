@@ -0,0 +1,221 @@
+// Command lis holds maintenance commands that run against the database
+// outside the HTTP server.
+//
+//	lis pii genkey [-id ID] [-index]
+//	lis pii migrate [-batch N]
+//	lis pii rotate [-batch N]
+//	lis search reindex [-batch N]
+//	lis config check [-print] [-connect]
+//
+// Every command except pii genkey also takes -config FILE to read a
+// configuration file other than $LIS_CONFIG.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/config"
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+)
+
+const usage = `usage: lis <command> [arguments]
+
+commands:
+  pii genkey [-id ID] [-index]   generate a PII encryption key
+  pii migrate [-batch N]         encrypt patient PII still stored as plaintext
+  pii rotate [-batch N]          re-encrypt patient PII under the active key
+  search reindex [-batch N]      rebuild the patient name search index
+  config check [-print] [-connect]
+                                 validate the configuration
+
+Every command except pii genkey also takes -config FILE.
+`
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] + " " + os.Args[2] {
+	case "pii genkey":
+		err = genKey(os.Args[3:])
+	case "pii migrate", "pii rotate":
+		err = reencrypt(os.Args[2], os.Args[3:])
+	case "search reindex":
+		err = reindex(os.Args[3:])
+	case "config check":
+		err = checkConfig(os.Args[3:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("lis %s %s: %v", os.Args[1], os.Args[2], err)
+	}
+}
+
+func genKey(args []string) error {
+	flags := flag.NewFlagSet("pii genkey", flag.ExitOnError)
+	id := flags.String("id", time.Now().Format("2006-01"), "key ID")
+	index := flags.Bool("index", false, "also generate a blind index key")
+	flags.Parse(args)
+
+	key, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("PII_KEYS entry:  %s:%s\n", *id, key)
+
+	if *index {
+		indexKey, err := fieldcrypt.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("PII_INDEX_KEY:   %s\n", indexKey)
+	}
+
+	return nil
+}
+
+// reencrypt backs both migrate and rotate: each pass encrypts plaintext
+// values and rewraps values sealed under a key other than the active one.
+func reencrypt(command string, args []string) error {
+	flags := flag.NewFlagSet("pii "+command, flag.ExitOnError)
+	batch := flags.Int("batch", 500, "patients per transaction")
+	configFile := flags.String("config", "", "configuration file (default $LIS_CONFIG)")
+	flags.Parse(args)
+
+	cfg, db, keyring, err := connect(*configFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	piiUC := usecase.NewPIIUsecase(db, repository.NewPatientRepository(db, cfg.Database.Dialect, keyring))
+
+	log.Printf("Re-encrypting patient PII with key %s", keyring.ActiveKeyID())
+
+	scanned, updated, err := piiUC.Reencrypt(context.Background(), *batch, func(scanned, updated int) {
+		log.Printf("  %d patients checked, %d re-encrypted", scanned, updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Done: %d patients checked, %d re-encrypted", scanned, updated)
+	return nil
+}
+
+// reindex rebuilds the name tokens of every patient, for patients
+// registered before the search index existed.
+func reindex(args []string) error {
+	flags := flag.NewFlagSet("search reindex", flag.ExitOnError)
+	batch := flags.Int("batch", 500, "patients per transaction")
+	configFile := flags.String("config", "", "configuration file (default $LIS_CONFIG)")
+	flags.Parse(args)
+
+	cfg, db, keyring, err := connect(*configFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	searchUC := usecase.NewSearchIndexUsecase(db, repository.NewPatientRepository(db, cfg.Database.Dialect, keyring))
+
+	log.Printf("Rebuilding the patient search index")
+
+	indexed, err := searchUC.Reindex(context.Background(), *batch, func(indexed int) {
+		log.Printf("  %d patients indexed", indexed)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Done: %d patients indexed", indexed)
+	return nil
+}
+
+// checkConfig validates the configuration the server would start with,
+// including the PII keys and the TLS certificate, and optionally connects
+// to the database.
+func checkConfig(args []string) error {
+	flags := flag.NewFlagSet("config check", flag.ExitOnError)
+	configFile := flags.String("config", "", "configuration file (default $LIS_CONFIG)")
+	printConfig := flags.Bool("print", false, "print the resulting configuration, with secrets masked")
+	connectDB := flags.Bool("connect", false, "also connect to the database")
+	flags.Parse(args)
+
+	cfg, err := config.Load(&config.Flags{File: *configFile})
+	if err != nil {
+		return err
+	}
+
+	if _, err := config.LoadKeyring(cfg.PII); err != nil {
+		return err
+	}
+
+	if cfg.TLS.Enabled() {
+		if _, err := config.LoadCertificates(cfg.TLS); err != nil {
+			return err
+		}
+	}
+
+	if *printConfig {
+		redacted, err := cfg.Redacted()
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(redacted)
+	}
+
+	if *connectDB {
+		db, err := config.NewDatabaseConnection(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		db.Close()
+		log.Printf("Connected to the %s database", cfg.Database.Dialect)
+	}
+
+	log.Printf("Configuration OK")
+	return nil
+}
+
+// connect loads the configuration and the PII keyring, connects to the
+// database and brings its schema up to date.
+func connect(configFile string) (*config.Config, *sql.DB, *fieldcrypt.Keyring, error) {
+	cfg, err := config.Load(&config.Flags{File: configFile})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	keyring, err := config.LoadKeyring(cfg.PII)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db, err := config.NewDatabaseConnection(cfg.Database)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := config.RunMigrations(db, cfg.Database.Dialect); err != nil {
+		db.Close()
+		return nil, nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return cfg, db, keyring, nil
+}
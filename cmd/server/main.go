@@ -1,116 +1,388 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
 
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
 	"github.com/BioSystems-Indonesia/lis/internal/config"
-	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
 	"github.com/BioSystems-Indonesia/lis/internal/handler"
+	"github.com/BioSystems-Indonesia/lis/internal/health"
+	"github.com/BioSystems-Indonesia/lis/internal/lifecycle"
+	"github.com/BioSystems-Indonesia/lis/internal/metrics"
+	"github.com/BioSystems-Indonesia/lis/internal/openapi"
+	"github.com/BioSystems-Indonesia/lis/internal/report"
 	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/requestmeta"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
 	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func main() {
-	dbConfig := config.GetDatabaseConfig()
+	flags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load(flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	slog.SetDefault(config.NewLogger(cfg.Log))
+
+	dbConfig := cfg.Database
+	labConfig := cfg.Lab
+	authConfig := cfg.Auth
+	authConfig.EnsureSecret()
+
+	keyring, err := config.LoadKeyring(cfg.PII)
+	if err != nil {
+		fatal("Failed to load PII encryption keys", err)
+	}
+
+	// Installed before the database is opened, so that the statements
+	// run from the start are traced.
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Enabled() {
+		tracerProvider, err = config.NewTracerProvider(context.Background(), cfg.Tracing)
+		if err != nil {
+			fatal("Failed to set up tracing", err)
+		}
+		tracing.Install(tracerProvider)
+
+		slog.Info("Tracing enabled", "endpoint", cfg.Tracing.Endpoint, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
 
 	db, err := config.NewDatabaseConnection(dbConfig)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
-	defer db.Close()
 
-	log.Println("Database connection established")
+	// Registered first, so the pool is closed after every service that
+	// uses it has stopped.
+	lc := lifecycle.New(cfg.HTTP.ShutdownTimeout)
+	lc.OnClose("database", db.Close)
 
-	if err := config.RunMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	if tracerProvider != nil {
+		// Flushes the spans of the last requests.
+		lc.OnClose("tracing", func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+			defer cancel()
+			return tracerProvider.Shutdown(ctx)
+		})
 	}
 
-	patientRepo := repository.NewPatientRepository(db)
-	workOrderRepo := repository.NewWorkOrderRepository(db)
+	slog.Info("Database connection established", "driver", dbConfig.Dialect)
 
-	patientUC := usecase.NewPatientUsecase(db, patientRepo)
-	workOrderUC := usecase.NewWorkOrderUsecase(db, workOrderRepo, patientRepo)
+	metrics.RegisterDB(db, string(dbConfig.Dialect))
 
-	patientHandler := handler.NewPatientHandler(patientUC)
-	workOrderHandler := handler.NewWorkOrderHandler(workOrderUC)
+	if err := config.RunMigrations(db, dbConfig.Dialect); err != nil {
+		fatal("Failed to run migrations", err)
+	}
+
+	patientRepo := repository.NewPatientRepository(db, dbConfig.Dialect, keyring)
+	workOrderRepo := repository.NewWorkOrderRepository(db, dbConfig.Dialect)
+	testCatalogRepo := repository.NewTestCatalogRepository(db, dbConfig.Dialect)
+	issuedReportRepo := repository.NewIssuedReportRepository(db, dbConfig.Dialect)
+	resultVersionRepo := repository.NewResultVersionRepository(db, dbConfig.Dialect)
+	userRepo := repository.NewUserRepository(db, dbConfig.Dialect)
+	sessionRepo := repository.NewSessionRepository(db, dbConfig.Dialect)
+	auditLogRepo := repository.NewAuditLogRepository(db, dbConfig.Dialect)
+	apiKeyRepo := repository.NewAPIKeyRepository(db, dbConfig.Dialect)
+
+	patientUC := usecase.NewPatientUsecase(db, patientRepo, auditLogRepo)
+	workOrderUC := usecase.NewWorkOrderUsecase(db, workOrderRepo, patientRepo, testCatalogRepo, resultVersionRepo, userRepo, auditLogRepo)
+	testCatalogUC := usecase.NewTestCatalogUsecase(db, testCatalogRepo)
+	worklistUC := usecase.NewWorklistUsecase(db, workOrderRepo, auditLogRepo)
+	turnaroundUC := usecase.NewTurnaroundUsecase(db, workOrderRepo)
+	reportUC := usecase.NewReportUsecase(db, workOrderRepo, patientRepo, testCatalogRepo, issuedReportRepo, resultVersionRepo, auditLogRepo)
+	userUC := usecase.NewUserUsecase(db, userRepo)
+	authUC := usecase.NewAuthUsecase(db, userRepo, sessionRepo, authConfig)
+	auditLogUC := usecase.NewAuditLogUsecase(db, auditLogRepo)
+	apiKeyUC := usecase.NewAPIKeyUsecase(db, apiKeyRepo, userRepo, auditLogRepo)
+	workloadUC := usecase.NewWorkloadUsecase(db, workOrderRepo)
+
+	if err := userUC.EnsureAdmin(context.Background(), authConfig.AdminUsername, authConfig.AdminPassword); err != nil {
+		fatal("Failed to bootstrap admin user", err)
+	}
+
+	h := handlers{
+		patient:     handler.NewPatientHandler(patientUC),
+		workOrder:   handler.NewWorkOrderHandler(workOrderUC),
+		testCatalog: handler.NewTestCatalogHandler(testCatalogUC),
+		worklist:    handler.NewWorklistHandler(worklistUC),
+		turnaround:  handler.NewTurnaroundHandler(turnaroundUC),
+		report: handler.NewReportHandler(reportUC, report.Letterhead{
+			Name:     labConfig.Name,
+			Address:  labConfig.Address,
+			Phone:    labConfig.Phone,
+			Email:    labConfig.Email,
+			LogoPath: labConfig.LogoPath,
+		}, labConfig.VerifyURL),
+		auth:     handler.NewAuthHandler(authUC, userUC),
+		user:     handler.NewUserHandler(userUC),
+		auditLog: handler.NewAuditLogHandler(auditLogUC),
+		apiKey:   handler.NewAPIKeyHandler(apiKeyUC),
+		health:   handler.NewHealthHandler(healthChecks(cfg, db), lc.Ready),
+		metrics:  handler.NewMetricsHandler(workloadUC),
+	}
+
+	spec := openapi.New(openapi.Info{
+		Title:       "LIS API",
+		Version:     "1.0",
+		Description: "REST API of the laboratory information system.",
+	})
+	documentEnums(spec)
 
 	mux := http.NewServeMux()
+	register(mux, spec, routes(h, spec))
 
-	mux.HandleFunc("/patients", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			if r.URL.Query().Get("q") != "" {
-				patientHandler.Search(w, r)
-			} else if r.URL.Query().Get("id") != "" {
-				patientHandler.GetByID(w, r)
-			} else {
-				patientHandler.GetAll(w, r)
-			}
-		case http.MethodPost:
-			patientHandler.Create(w, r)
-		case http.MethodPut:
-			patientHandler.Update(w, r)
-		case http.MethodDelete:
-			patientHandler.Delete(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           serverHandler(mux, authUC, apiKeyUC),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	listener, err := net.Listen("tcp", cfg.HTTP.Addr)
+	if err != nil {
+		fatal("Server failed to start", err)
+	}
+
+	if cfg.TLS.Enabled() {
+		certificates, err := config.LoadCertificates(cfg.TLS)
+		if err != nil {
+			fatal("Failed to load TLS certificate", err)
 		}
-	})
+		listener = certificates.NewListener(listener, "h2", "http/1.1")
+
+		lc.Add(lifecycle.Worker("TLS certificate reloader", certificates.Run))
+	}
+
+	lc.Add(lifecycle.Worker("expired session cleanup", deleteExpiredSessions(authUC, time.Hour)))
+
+	lc.Add(lifecycle.HTTPServer("HTTP server", server, func() error { return server.Serve(listener) }))
+
+	// Instrument connections are not implemented: the ports are validated
+	// but not opened, so no listener or session worker is registered.
+	for _, instrument := range cfg.Instruments {
+		slog.Warn("Instrument port not opened, instrument connections are not supported yet", "instrument", instrument.Name, "listen", instrument.Listen)
+	}
 
-	mux.HandleFunc("/work-orders", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			if r.URL.Query().Get("no_order") != "" {
-				workOrderHandler.GetByNoOrder(w, r)
-			} else if r.URL.Query().Get("doctor") != "" {
-				workOrderHandler.GetByDoctor(w, r)
-			} else if r.URL.Query().Get("analyst") != "" {
-				workOrderHandler.GetByAnalyst(w, r)
-			} else {
-				workOrderHandler.GetAll(w, r)
+	slog.Info("Server starting", "addr", listener.Addr().String(), "tls", cfg.TLS.Enabled(), "client_auth", cfg.TLS.ClientAuth)
+
+	if err := lc.Run(context.Background()); err != nil {
+		fatal("Server stopped with an error", err)
+	}
+
+	slog.Info("Server stopped")
+}
+
+// deleteExpiredSessions returns a worker that deletes the expired sessions
+// at startup and then every interval until ctx is done. A failed run is
+// logged and retried at the next tick.
+func deleteExpiredSessions(authUC usecase.AuthUsecase, interval time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := authUC.DeleteExpiredSessions(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("Failed to delete expired sessions", "error", err)
+			} else if deleted > 0 {
+				slog.Info("Expired sessions deleted", "count", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
 			}
-		case http.MethodPost:
-			workOrderHandler.Create(w, r)
-		case http.MethodPut:
-			workOrderHandler.Update(w, r)
-		case http.MethodDelete:
-			workOrderHandler.Delete(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	}
+}
 
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// healthChecks returns the dependency checks of the readiness probe.
+func healthChecks(cfg *config.Config, db *sql.DB) *health.Checker {
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+
+	checker.Add(health.Database(db))
+	checker.Add(health.Migrations(func(ctx context.Context) ([]string, error) {
+		return config.PendingMigrations(ctx, db, cfg.Database.Dialect)
+	}))
+
+	// The working directory holds the migrations and anything the server
+	// writes; a SQLite database may live elsewhere.
+	diskPaths := []string{"."}
+	if cfg.Database.Dialect == dialect.SQLite {
+		diskPaths = append(diskPaths, filepath.Dir(cfg.Database.Path))
+	}
+	checker.Add(health.Disk(diskPaths, uint64(cfg.Health.MinFreeDiskMB)<<20))
+
+	return checker
+}
+
+// serverHandler wraps mux in the middleware every request passes through.
+// Tracing comes first, so that the request span covers the others.
+func serverHandler(mux *http.ServeMux, authUC usecase.AuthUsecase, apiKeyUC usecase.APIKeyUsecase) http.Handler {
+	return tracing.Middleware(mux, metrics.Middleware(mux, requestMetaMiddleware(recoverMiddleware(authMiddleware(authUC, apiKeyUC, mux)))))
+}
+
+// requestMetaMiddleware assigns every request an ID, echoed in the
+// X-Request-ID response header and set on the span of the request, and
+// records the client address.
+func requestMetaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := requestmeta.FromRequest(r)
+		w.Header().Set(requestmeta.Header, requestmeta.RequestID(ctx))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("lis.request_id", requestmeta.RequestID(ctx)))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
+}
+
+// register serves, authorizes and documents every route of table.
+func register(mux *http.ServeMux, spec *openapi.Spec, table []route) {
+	for _, rt := range table {
+		mux.HandleFunc(rt.pattern, rt.handler)
+
+		_, path, ok := strings.Cut(rt.pattern, " ")
+		if !ok {
+			path = rt.pattern
+		}
+		if rt.public {
+			publicPaths[path] = true
+		}
+		if rt.permission != "" {
+			routePermissions[rt.pattern] = rt.permission
+		}
 
-	log.Println("Server starting on :8080...")
-	if err := http.ListenAndServe(":8080", recoverMiddleware(mux)); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+		spec.Add(rt.pattern, rt.doc, string(rt.permission), rt.public)
 	}
 }
 
+// publicPaths are served without an access token, keyed by URL path.
+// They are filled from the route table.
+var publicPaths = map[string]bool{}
+
+// routePermissions is the permission each endpoint requires, keyed by
+// its ServeMux pattern and filled from the route table. Usecases check the
+// same permissions again; this table rejects requests before any work is
+// done.
+var routePermissions = map[string]auth.Permission{}
+
+// authMiddleware authenticates a request by its access token or API key.
+// An API key is sent in the X-API-Key header or, for clients that can
+// only set a bearer token, as the bearer token. The required permission is
+// looked up by the pattern mux routes the request to.
+func authMiddleware(authUC usecase.AuthUsecase, apiKeyUC usecase.APIKeyUsecase, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			token, ok = apiKey, true
+		}
+
+		if !ok || token == "" {
+			unauthorized(w, r, auth.ErrUnauthenticated)
+			return
+		}
+
+		var principal *auth.Principal
+		var err error
+		if auth.IsAPIKey(token) {
+			principal, err = apiKeyUC.Authenticate(r.Context(), token)
+		} else {
+			principal, err = authUC.Authenticate(r.Context(), token)
+		}
+		if err != nil {
+			unauthorized(w, r, err)
+			return
+		}
+
+		_, pattern := mux.Handler(r)
+		if permission, ok := routePermissions[pattern]; ok && !principal.Can(permission) {
+			writeError(w, r, fmt.Errorf("%w: %s required", auth.ErrForbidden, permission))
+			return
+		}
+
+		mux.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="lis"`)
+	writeError(w, r, err)
+}
+
+// writeError writes the error response for err, the same way the
+// handlers do.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, response := handler.ErrorResponse(r.Context(), err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// recoverMiddleware answers a request whose handler panicked with an
+// internal error, and logs the panic with its stack trace and request ID.
+// The panic value is not sent to the client.
 func recoverMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
-				log.Printf("PANIC recovered: %v", err)
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), "panic serving request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"panic", fmt.Sprint(err),
+				"stack", string(debug.Stack()),
+			)
+			tracing.RecordError(r.Context(), fmt.Errorf("panic: %v", err))
 
-				response := dto.ResponseError{
-					Code:    http.StatusInternalServerError,
-					Status:  "Inrernal Server Error",
-					Message: fmt.Sprintf("Internal Server Error: %v", err),
-				}
+			status, response := handler.InternalErrorResponse(r.Context())
 
-				json.NewEncoder(w).Encode(response)
-			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+
+			json.NewEncoder(w).Encode(response)
 		}()
 
 		next.ServeHTTP(w, r)
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/handler"
+	"github.com/BioSystems-Indonesia/lis/internal/health"
+	"github.com/BioSystems-Indonesia/lis/internal/openapi"
+)
+
+// route is one endpoint of the API. The server registers, authorizes and
+// documents every endpoint from the same table, so the OpenAPI document
+// lists exactly the routes that are served.
+type route struct {
+	pattern    string
+	handler    http.HandlerFunc
+	permission auth.Permission
+	// public routes are served without credentials.
+	public bool
+	doc    openapi.Route
+}
+
+type handlers struct {
+	patient     *handler.PatientHandler
+	workOrder   *handler.WorkOrderHandler
+	testCatalog *handler.TestCatalogHandler
+	worklist    *handler.WorklistHandler
+	turnaround  *handler.TurnaroundHandler
+	report      *handler.ReportHandler
+	auth        *handler.AuthHandler
+	user        *handler.UserHandler
+	auditLog    *handler.AuditLogHandler
+	apiKey      *handler.APIKeyHandler
+	health      *handler.HealthHandler
+	metrics     *handler.MetricsHandler
+}
+
+// Query parameters shared by several endpoints.
+var (
+	fromParam = openapi.Query("from", "Created on or after, YYYY-MM-DD or RFC 3339.")
+	toParam   = openapi.Query("to", "Created on or before, YYYY-MM-DD or RFC 3339.")
+)
+
+var workOrderFilters = []openapi.Parameter{
+	openapi.Query("doctor", "Doctor user ID or name."),
+	openapi.Query("analyst", "Analyst user ID or name."),
+	openapi.Query("priority", "routine, urgent or stat."),
+	openapi.Query("status", "Comma-separated test statuses; matches orders with at least one such test."),
+	openapi.Query("test_code", "Matches orders containing the test."),
+	fromParam,
+	toParam,
+}
+
+type message map[string]string
+
+func routes(h handlers, spec *openapi.Spec) []route {
+	return []route{
+		{
+			pattern: "GET /patients",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("q") != "" {
+					h.patient.Search(w, r)
+				} else {
+					h.patient.GetAll(w, r)
+				}
+			},
+			permission: auth.PermPatientsRead,
+			doc: openapi.Route{
+				Summary:     "List patients",
+				Description: "With q, returns a page of the patients matching the search text, best match first. A search also accepts sort=relevance and ignores sex, from and to.",
+				Query: []openapi.Parameter{
+					openapi.Query("q", "Search text: names (typos and old spellings tolerated), a birthdate, or a patient ID, phone, email or national ID."),
+					openapi.Query("sex", "male or female."),
+					fromParam,
+					toParam,
+				},
+				Response: []dto.PatientResponse{},
+				Sort:     dto.PatientSortFields,
+			},
+		},
+		{
+			pattern:    "POST /patients",
+			handler:    h.patient.Create,
+			permission: auth.PermPatientsWrite,
+			doc:        openapi.Route{Summary: "Create a patient", Body: dto.PatientRequest{}, Response: dto.PatientResponse{}, Status: http.StatusCreated},
+		},
+		{
+			pattern:    "GET /patients/{id}",
+			handler:    h.patient.GetByID,
+			permission: auth.PermPatientsRead,
+			doc:        openapi.Route{Summary: "Get a patient", Response: dto.PatientResponse{}},
+		},
+		{
+			pattern:    "PUT /patients/{id}",
+			handler:    h.patient.Update,
+			permission: auth.PermPatientsWrite,
+			doc:        openapi.Route{Summary: "Replace a patient", Body: dto.PatientRequest{}, Response: dto.PatientResponse{}},
+		},
+		{
+			pattern:    "PATCH /patients/{id}",
+			handler:    h.patient.Patch,
+			permission: auth.PermPatientsWrite,
+			doc:        openapi.Route{Summary: "Update patient fields", Body: dto.PatientPatchRequest{}, Response: dto.PatientResponse{}},
+		},
+		{
+			pattern:    "DELETE /patients/{id}",
+			handler:    h.patient.Delete,
+			permission: auth.PermPatientsDelete,
+			doc:        openapi.Route{Summary: "Delete a patient", Response: message{}},
+		},
+		{
+			pattern:    "GET /patients/{id}/work-orders",
+			handler:    h.workOrder.GetByPatient,
+			permission: auth.PermWorkOrdersRead,
+			doc:        openapi.Route{Summary: "List the work orders of a patient", Query: workOrderFilters, Response: []dto.WorkOrderResponse{}, Sort: dto.WorkOrderSortFields},
+		},
+
+		{
+			pattern:    "GET /work-orders",
+			handler:    h.workOrder.GetAll,
+			permission: auth.PermWorkOrdersRead,
+			doc: openapi.Route{
+				Summary:  "List work orders",
+				Query:    append([]openapi.Parameter{openapi.Query("patient_id", "Patient ID.")}, workOrderFilters...),
+				Response: []dto.WorkOrderResponse{},
+				Sort:     dto.WorkOrderSortFields,
+			},
+		},
+		{
+			pattern:    "POST /work-orders",
+			handler:    h.workOrder.Create,
+			permission: auth.PermWorkOrdersWrite,
+			doc:        openapi.Route{Summary: "Create a work order", Body: dto.WorkOrderRequest{}, Response: dto.WorkOrderResponse{}, Status: http.StatusCreated},
+		},
+		{
+			pattern:    "GET /work-orders/{no}",
+			handler:    h.workOrder.GetByNoOrder,
+			permission: auth.PermWorkOrdersRead,
+			doc:        openapi.Route{Summary: "Get a work order", Response: dto.WorkOrderResponse{}},
+		},
+		{
+			pattern:    "PUT /work-orders/{no}",
+			handler:    h.workOrder.Update,
+			permission: auth.PermWorkOrdersWrite,
+			doc:        openapi.Route{Summary: "Replace a work order", Body: dto.WorkOrderRequest{}, Response: dto.WorkOrderResponse{}},
+		},
+		{
+			pattern:    "PATCH /work-orders/{no}",
+			handler:    h.workOrder.Patch,
+			permission: auth.PermWorkOrdersWrite,
+			doc:        openapi.Route{Summary: "Update work order fields", Body: dto.WorkOrderPatchRequest{}, Response: dto.WorkOrderResponse{}},
+		},
+		{
+			pattern:    "DELETE /work-orders/{no}",
+			handler:    h.workOrder.Delete,
+			permission: auth.PermWorkOrdersDelete,
+			doc:        openapi.Route{Summary: "Delete a work order", Response: message{}},
+		},
+		{
+			pattern:    "GET /work-orders/{no}/tests",
+			handler:    h.workOrder.GetTests,
+			permission: auth.PermResultsRead,
+			doc:        openapi.Route{Summary: "List the tests of a work order", Response: []dto.WorkOrderTestResponse{}},
+		},
+		{
+			pattern:    "POST /work-orders/{no}/receive",
+			handler:    h.workOrder.Receive,
+			permission: auth.PermSpecimensReceive,
+			doc:        openapi.Route{Summary: "Receive specimens", Body: dto.TestStepRequest{}, BodyOptional: true, Response: []dto.WorkOrderTestResponse{}},
+		},
+		{
+			pattern:    "POST /work-orders/{no}/results",
+			handler:    h.workOrder.RecordResults,
+			permission: auth.PermResultsWrite,
+			doc:        openapi.Route{Summary: "Record results", Body: dto.ResultRequest{}, Response: []dto.WorkOrderTestResponse{}},
+		},
+		{
+			pattern:    "POST /work-orders/{no}/validate",
+			handler:    h.workOrder.Validate,
+			permission: auth.PermResultsValidate,
+			doc:        openapi.Route{Summary: "Validate results", Body: dto.TestStepRequest{}, BodyOptional: true, Response: []dto.WorkOrderTestResponse{}},
+		},
+		{
+			pattern:    "POST /work-orders/{no}/authorize",
+			handler:    h.workOrder.Authorize,
+			permission: auth.PermResultsAuthorize,
+			doc:        openapi.Route{Summary: "Authorize results", Body: dto.TestStepRequest{}, BodyOptional: true, Response: []dto.WorkOrderTestResponse{}},
+		},
+		{
+			pattern:    "POST /work-orders/{no}/amend",
+			handler:    h.workOrder.Amend,
+			permission: auth.PermResultsAmend,
+			doc:        openapi.Route{Summary: "Amend released results", Body: dto.AmendRequest{}, Response: []dto.WorkOrderTestResponse{}},
+		},
+		{
+			pattern:    "GET /work-orders/{no}/history",
+			handler:    h.workOrder.GetHistory,
+			permission: auth.PermResultsRead,
+			doc:        openapi.Route{Summary: "Get the result history of a work order", Response: dto.ResultHistoryResponse{}},
+		},
+		{
+			pattern:    "GET /work-orders/{no}/report",
+			handler:    h.report.GetLabReport,
+			permission: auth.PermReportsRead,
+			doc: openapi.Route{
+				Summary:     "Issue the lab report",
+				Description: "Issues the report and returns it as a PDF, which also requires reports:issue. Reading a report also requires results:read. With format=json the current report content is returned without issuing it.",
+				Query:       []openapi.Parameter{openapi.Query("format", "pdf (default) or json.")},
+				Response:    dto.LabReportResponse{},
+				Produces:    []string{"application/pdf"},
+			},
+		},
+
+		{
+			// The verification link is printed on issued reports, so it
+			// keeps its query parameter form.
+			pattern: "GET /verify",
+			handler: h.report.Verify,
+			public:  true,
+			doc: openapi.Route{
+				Summary:  "Verify a printed report",
+				Query:    []openapi.Parameter{openapi.Query("code", "Verification code printed on the report.")},
+				Response: dto.ReportVerificationResponse{},
+			},
+		},
+
+		{
+			pattern:    "GET /tat",
+			handler:    h.turnaround.GetMetrics,
+			permission: auth.PermMetricsRead,
+			doc: openapi.Route{
+				Summary: "Get turnaround time metrics",
+				Query: []openapi.Parameter{
+					openapi.Query("group_by", "Comma-separated: test, priority, department."),
+					openapi.Query("department", "Department."),
+					openapi.Query("priority", "routine, urgent or stat."),
+					fromParam,
+					toParam,
+				},
+				Response: []dto.TATGroupResponse{},
+			},
+		},
+		{
+			pattern:    "GET /worklist",
+			handler:    h.worklist.Get,
+			permission: auth.PermWorkOrdersRead,
+			doc: openapi.Route{
+				Summary: "Get the worklist",
+				Query: []openapi.Parameter{
+					openapi.Query("department", "Department."),
+					openapi.Query("instrument", "Instrument."),
+					openapi.Query("priority", "routine, urgent or stat."),
+					openapi.Query("status", "Comma-separated test statuses."),
+					fromParam,
+					toParam,
+					openapi.Query("format", "json (default), csv or pdf."),
+				},
+				Response: []dto.WorklistItemResponse{},
+				Produces: []string{"text/csv", "application/pdf"},
+			},
+		},
+
+		{
+			pattern:    "GET /test-catalog",
+			handler:    h.testCatalog.GetAll,
+			permission: auth.PermCatalogRead,
+			doc:        openapi.Route{Summary: "List catalog tests", Response: []dto.TestCatalogResponse{}},
+		},
+		{
+			pattern:    "POST /test-catalog",
+			handler:    h.testCatalog.Create,
+			permission: auth.PermCatalogWrite,
+			doc:        openapi.Route{Summary: "Create a catalog test", Body: dto.TestCatalogRequest{}, Response: dto.TestCatalogResponse{}, Status: http.StatusCreated},
+		},
+		{
+			pattern:    "GET /test-catalog/{code}",
+			handler:    h.testCatalog.GetByCode,
+			permission: auth.PermCatalogRead,
+			doc:        openapi.Route{Summary: "Get a catalog test", Response: dto.TestCatalogResponse{}},
+		},
+		{
+			pattern:    "PUT /test-catalog/{code}",
+			handler:    h.testCatalog.Update,
+			permission: auth.PermCatalogWrite,
+			doc:        openapi.Route{Summary: "Replace a catalog test", Body: dto.TestCatalogRequest{}, Response: dto.TestCatalogResponse{}},
+		},
+		{
+			pattern:    "DELETE /test-catalog/{code}",
+			handler:    h.testCatalog.Delete,
+			permission: auth.PermCatalogWrite,
+			doc:        openapi.Route{Summary: "Delete a catalog test", Response: message{}},
+		},
+
+		{
+			pattern: "POST /auth/login",
+			handler: h.auth.Login,
+			public:  true,
+			doc:     openapi.Route{Summary: "Log in", Body: dto.LoginRequest{}, Response: dto.TokenResponse{}},
+		},
+		{
+			pattern: "POST /auth/refresh",
+			handler: h.auth.Refresh,
+			public:  true,
+			doc:     openapi.Route{Summary: "Refresh an access token", Body: dto.RefreshRequest{}, Response: dto.TokenResponse{}},
+		},
+		{
+			pattern: "POST /auth/logout",
+			handler: h.auth.Logout,
+			doc:     openapi.Route{Summary: "Log out", Response: message{}},
+		},
+		{
+			pattern: "GET /auth/me",
+			handler: h.auth.Me,
+			doc:     openapi.Route{Summary: "Get the current user", Response: dto.UserResponse{}},
+		},
+
+		{
+			pattern:    "GET /users",
+			handler:    h.user.GetAll,
+			permission: auth.PermUsersManage,
+			doc:        openapi.Route{Summary: "List users", Response: []dto.UserResponse{}},
+		},
+		{
+			pattern:    "POST /users",
+			handler:    h.user.Create,
+			permission: auth.PermUsersManage,
+			doc:        openapi.Route{Summary: "Create a user", Body: dto.UserRequest{}, Response: dto.UserResponse{}, Status: http.StatusCreated},
+		},
+		{
+			pattern:    "GET /users/{id}",
+			handler:    h.user.GetByID,
+			permission: auth.PermUsersManage,
+			doc:        openapi.Route{Summary: "Get a user", Response: dto.UserResponse{}},
+		},
+		{
+			pattern:    "PUT /users/{id}",
+			handler:    h.user.Update,
+			permission: auth.PermUsersManage,
+			doc:        openapi.Route{Summary: "Update a user", Body: dto.UserRequest{}, Response: dto.UserResponse{}},
+		},
+		{
+			pattern:    "POST /users/{id}/unlock",
+			handler:    h.user.Unlock,
+			permission: auth.PermUsersManage,
+			doc:        openapi.Route{Summary: "Unlock a user", Response: dto.UserResponse{}},
+		},
+
+		{
+			pattern:    "GET /audit-logs",
+			handler:    h.auditLog.Find,
+			permission: auth.PermAuditRead,
+			doc: openapi.Route{
+				Summary: "Search the audit trail",
+				Query: []openapi.Parameter{
+					openapi.Query("patient_id", "Patient ID."),
+					openapi.Query("user_id", "User ID."),
+					openapi.Query("entity_type", "Entity type, e.g. patient or work_order."),
+					openapi.Query("entity_id", "Entity ID."),
+					openapi.Query("action", "view, create, update or delete."),
+					fromParam,
+					toParam,
+					{Name: "limit", In: "query", Description: "Maximum number of entries.", Schema: &openapi.Schema{Type: "integer"}},
+				},
+				Response: []dto.AuditLogResponse{},
+			},
+		},
+
+		{
+			pattern:    "GET /api-keys",
+			handler:    h.apiKey.GetAll,
+			permission: auth.PermAPIKeysManage,
+			doc:        openapi.Route{Summary: "List API keys", Response: []dto.APIKeyResponse{}},
+		},
+		{
+			pattern:    "POST /api-keys",
+			handler:    h.apiKey.Create,
+			permission: auth.PermAPIKeysManage,
+			doc:        openapi.Route{Summary: "Create an API key", Body: dto.APIKeyRequest{}, Response: dto.APIKeySecretResponse{}, Status: http.StatusCreated},
+		},
+		{
+			pattern:    "POST /api-keys/{id}/revoke",
+			handler:    h.apiKey.Revoke,
+			permission: auth.PermAPIKeysManage,
+			doc:        openapi.Route{Summary: "Revoke an API key", Response: dto.APIKeyResponse{}},
+		},
+		{
+			pattern:    "POST /api-keys/{id}/rotate",
+			handler:    h.apiKey.Rotate,
+			permission: auth.PermAPIKeysManage,
+			doc:        openapi.Route{Summary: "Rotate an API key", Response: dto.APIKeySecretResponse{}},
+		},
+
+		{
+			pattern: "/health",
+			handler: h.health.Health,
+			public:  true,
+			doc: openapi.Route{
+				Summary:     "Health check",
+				Description: "OK when the server is ready, as /health/ready; otherwise 503 with the failing status.",
+				Raw:         true,
+				Produces:    []string{"text/plain"},
+			},
+		},
+		{
+			pattern: "GET /health/live",
+			handler: h.health.Live,
+			public:  true,
+			doc: openapi.Route{
+				Summary:     "Liveness probe",
+				Description: "Answers 200 while the process is serving requests. It does not check dependencies, so that a database outage does not get the server restarted.",
+				Raw:         true,
+				Produces:    []string{"application/json"},
+				Response:    handler.LiveResponse{},
+			},
+		},
+		{
+			pattern: "GET /health/ready",
+			handler: h.health.Ready,
+			public:  true,
+			doc: openapi.Route{
+				Summary:     "Readiness probe",
+				Description: "Checks the database, pending migrations and free disk space, and answers the status of each. Answers 503 when a critical check fails, and while the server starts or shuts down; failures of the other checks only set status to warn.",
+				Raw:         true,
+				Produces:    []string{"application/json"},
+				Response:    health.Summary{},
+			},
+		},
+		{
+			pattern:    "GET /health/details",
+			handler:    h.health.Details,
+			permission: auth.PermMetricsRead,
+			doc: openapi.Route{
+				Summary:     "Readiness checks in detail",
+				Description: "The readiness report with the figures and errors of each check, such as the database latency and pool, pending migration versions and free disk space. Answers 503 like /health/ready.",
+				Raw:         true,
+				Produces:    []string{"application/json"},
+				Response:    health.Readiness{},
+			},
+		},
+		{
+			pattern:    "GET /metrics",
+			handler:    h.metrics.Serve,
+			permission: auth.PermMetricsRead,
+			doc: openapi.Route{
+				Summary:     "Prometheus metrics",
+				Description: "Request, database and lab throughput metrics in the Prometheus text format. Prometheus can authenticate with an API key holding metrics:read as its bearer token.",
+				Raw:         true,
+				Produces:    []string{"text/plain"},
+			},
+		},
+		{
+			pattern: "GET /openapi.json",
+			handler: spec.Handler(),
+			public:  true,
+			doc:     openapi.Route{Summary: "This OpenAPI document", Raw: true, Produces: []string{"application/json"}, Response: map[string]interface{}{}},
+		},
+		{
+			pattern: "GET /docs",
+			handler: openapi.UIHandler("/openapi.json", "/docs/"),
+			public:  true,
+			doc:     openapi.Route{Summary: "API documentation UI", Raw: true, Produces: []string{"text/html"}},
+		},
+		{
+			pattern: "GET /docs/swagger-ui.css",
+			handler: openapi.UIAssetHandler(),
+			public:  true,
+			doc:     openapi.Route{Summary: "Stylesheet of the documentation UI", Raw: true, Produces: []string{"text/css"}},
+		},
+		{
+			pattern: "GET /docs/swagger-ui-bundle.js",
+			handler: openapi.UIAssetHandler(),
+			public:  true,
+			doc:     openapi.Route{Summary: "Script of the documentation UI", Raw: true, Produces: []string{"text/javascript"}},
+		},
+	}
+}
+
+// documentEnums declares the values of the enumerated types used in the
+// request and response bodies.
+func documentEnums(spec *openapi.Spec) {
+	spec.Enum(entitiy.Male, entitiy.Female)
+	spec.Enum(entitiy.PriorityRoutine, entitiy.PriorityUrgent, entitiy.PrioritySTAT)
+	spec.Enum(entitiy.TestStatusPending, entitiy.TestStatusReceived, entitiy.TestStatusResulted, entitiy.TestStatusValidated, entitiy.TestStatusAuthorized)
+	spec.Enum(entitiy.AuditView, entitiy.AuditCreate, entitiy.AuditUpdate, entitiy.AuditDelete)
+
+	roles := make([]interface{}, len(entitiy.Roles))
+	for i, role := range entitiy.Roles {
+		roles[i] = role
+	}
+	spec.Enum(roles...)
+}
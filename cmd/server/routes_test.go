@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/openapi"
+)
+
+// TestRoutesAreDocumented registers the route table as the server does and
+// checks that the OpenAPI document has an operation for every route the
+// mux serves, and no operation for a route it does not serve.
+func TestRoutesAreDocumented(t *testing.T) {
+	spec := openapi.New(openapi.Info{Title: "LIS API", Version: "test"})
+	documentEnums(spec)
+
+	mux := http.NewServeMux()
+	table := routes(handlers{}, spec)
+	register(mux, spec, table)
+
+	document := spec.Document()
+	served := make(map[string]bool)
+
+	for _, rt := range table {
+		method, path, ok := strings.Cut(rt.pattern, " ")
+		if !ok {
+			method, path = http.MethodGet, rt.pattern
+		}
+
+		request := httptest.NewRequest(method, strings.NewReplacer("{", "", "}", "").Replace(path), nil)
+		if _, pattern := mux.Handler(request); pattern != rt.pattern {
+			t.Errorf("%s is routed to %q", rt.pattern, pattern)
+		}
+
+		if document.Paths[path][strings.ToLower(method)] == nil {
+			t.Errorf("%s is served but not documented", rt.pattern)
+		}
+		served[strings.ToLower(method)+" "+path] = true
+	}
+
+	for path, item := range document.Paths {
+		for method := range item {
+			if !served[method+" "+path] {
+				t.Errorf("%s %s is documented but not served", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/config"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+	"github.com/BioSystems-Indonesia/lis/internal/handler"
+	"github.com/BioSystems-Indonesia/lis/internal/openapi"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the migration log out of the test output.
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// TestRequestTrace serves one request through the middleware of the server
+// and checks that its HTTP span, the span of the usecase method it calls
+// and the spans of the SQL statements that method runs form one trace.
+func TestRequestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	tracing.Install(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// The migrations are read from the repository root.
+	t.Chdir(filepath.Join("..", ".."))
+
+	dbConfig := config.Default().Database
+	dbConfig.Dialect = dialect.SQLite
+	dbConfig.Path = filepath.Join(t.TempDir(), "lis.db")
+	db, err := config.NewDatabaseConnection(dbConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := config.RunMigrations(db, dbConfig.Dialect); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexKey, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := fieldcrypt.ParseKeyring("test:"+key, "", indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authConfig := config.Default().Auth
+	authConfig.JWTSecret = "0123456789abcdef0123456789abcdef"
+
+	userRepo := repository.NewUserRepository(db, dbConfig.Dialect)
+	auditLogRepo := repository.NewAuditLogRepository(db, dbConfig.Dialect)
+	patientUC := usecase.NewPatientUsecase(db, repository.NewPatientRepository(db, dbConfig.Dialect, keyring), auditLogRepo)
+	userUC := usecase.NewUserUsecase(db, userRepo)
+	authUC := usecase.NewAuthUsecase(db, userRepo, repository.NewSessionRepository(db, dbConfig.Dialect), authConfig)
+	apiKeyUC := usecase.NewAPIKeyUsecase(db, repository.NewAPIKeyRepository(db, dbConfig.Dialect), userRepo, auditLogRepo)
+
+	ctx := context.Background()
+	if err := userUC.EnsureAdmin(ctx, "admin", "s3cret-pass-for-tests"); err != nil {
+		t.Fatal(err)
+	}
+	token, err := authUC.Login(ctx, &dto.LoginRequest{Username: "admin", Password: "s3cret-pass-for-tests"}, dto.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := openapi.New(openapi.Info{Title: "LIS API", Version: "test"})
+	mux := http.NewServeMux()
+	register(mux, spec, routes(handlers{patient: handler.NewPatientHandler(patientUC)}, spec))
+
+	request := httptest.NewRequest(http.MethodGet, "/patients", nil)
+	request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	response := httptest.NewRecorder()
+	serverHandler(mux, authUC, apiKeyUC).ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("GET /patients answered %d: %s", response.Code, response.Body)
+	}
+
+	var server sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "GET /patients" && span.SpanKind() == trace.SpanKindServer {
+			server = span
+		}
+	}
+	if server == nil {
+		t.Fatal("no server span named GET /patients")
+	}
+
+	// Every span of the trace is a descendant of the server span.
+	spans := make(map[trace.SpanID]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == server.SpanContext().TraceID() {
+			spans[span.SpanContext().SpanID()] = span
+		}
+	}
+
+	var usecaseSpans, sqlSpans int
+	for _, span := range spans {
+		if span == server {
+			continue
+		}
+
+		ancestor := span
+		for ancestor != server {
+			parent, ok := spans[ancestor.Parent().SpanID()]
+			if !ok {
+				t.Fatalf("span %s is not a descendant of the server span", span.Name())
+			}
+			ancestor = parent
+		}
+
+		switch {
+		case span.Name() == "patientUsecase.GetAll":
+			usecaseSpans++
+		case span.InstrumentationScope().Name == "github.com/XSAM/otelsql":
+			sqlSpans++
+		}
+	}
+
+	if usecaseSpans != 1 {
+		t.Errorf("found %d patientUsecase.GetAll spans in the trace, want 1", usecaseSpans)
+	}
+	if sqlSpans == 0 {
+		t.Error("found no SQL spans in the trace")
+	}
+}
@@ -0,0 +1,117 @@
+// Package apperror classifies errors by what went wrong, independent of
+// the layer they come from. Repositories and usecases return these errors,
+// wrapped as usual with fmt.Errorf and %w, and the handlers map the kind to
+// an HTTP status code and a machine-readable error code.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error kinds. Use errors.Is to test an error for a kind.
+var (
+	// ErrNotFound is the kind of errors for a resource that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is the kind of errors for a request that conflicts with
+	// the current state: a duplicate key, a record still referenced by
+	// others, or a lifecycle step from the wrong status.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation is the kind of errors for invalid input.
+	ErrValidation = errors.New("validation failed")
+	// ErrForbidden is the kind of errors for operations that are not
+	// allowed regardless of the caller's permissions.
+	ErrForbidden = errors.New("forbidden")
+	// ErrPreconditionFailed is returned when an If-Match entity tag no
+	// longer matches the current representation: someone else changed the
+	// resource since the client read it.
+	ErrPreconditionFailed = errors.New("resource was modified since it was read; fetch it again and retry")
+)
+
+// FieldError describes why the value of one request field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error of a given kind with a message meant for the API
+// client and, for validation errors, the offending fields.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound returns an ErrNotFound error.
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict returns an ErrConflict error.
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden returns an ErrForbidden error.
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid returns an ErrValidation error that is not about a single field.
+func Invalid(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation returns an ErrValidation error for the given fields. It
+// returns nil when fields is empty, so a caller can collect field errors
+// and return the result unconditionally.
+func Validation(fields ...FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+
+	return &Error{
+		Kind:    ErrValidation,
+		Message: strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
+
+// Field returns an ErrValidation error for a single field.
+func Field(field, format string, args ...interface{}) error {
+	return Validation(FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Message returns the client-facing message of err: the message of the
+// first *Error in its chain, without the context added while it was
+// wrapped, or err.Error() when there is none.
+func Message(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
+
+// Fields returns the field errors in the chain of err, if any.
+func Fields(err error) []FieldError {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Fields
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, which tells API keys apart from
+// access tokens in the Authorization header.
+const APIKeyPrefix = "lis_"
+
+// apiKeyDisplayLength is how much of a key is stored in clear so that an
+// administrator can recognise it.
+const apiKeyDisplayLength = 12
+
+// NewAPIKey returns a new random API key, the prefix shown in listings and
+// the hash that is stored for it.
+func NewAPIKey() (string, string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	return key, key[:apiKeyDisplayLength], HashToken(key), nil
+}
+
+// IsAPIKey reports whether a credential has the API key format.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// Principal is the authenticated caller of a request: a user with a
+// session, or an API key. An API key has no user ID or roles; it is
+// limited to its scopes.
+type Principal struct {
+	UserID    string
+	Username  string
+	FullName  string
+	SessionID string
+	Roles     []entitiy.Role
+	APIKeyID  string
+	Scopes    []Permission
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal stored by the auth middleware, or
+// nil for an unauthenticated request.
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// ActorID identifies the principal in the audit log: the user ID, or the
+// API key ID for an API key.
+func (p *Principal) ActorID() string {
+	if p.APIKeyID != "" {
+		return p.APIKeyID
+	}
+	return p.UserID
+}
+
+// DisplayName is the name recorded when the principal signs off a result.
+func (p *Principal) DisplayName() string {
+	if p.FullName != "" {
+		return p.FullName
+	}
+	return p.Username
+}
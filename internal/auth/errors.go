@@ -0,0 +1,23 @@
+package auth
+
+import "errors"
+
+var (
+	// ErrInvalidCredentials is returned for an unknown username or a wrong
+	// password. The two cases are deliberately indistinguishable.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrAccountLocked is returned while an account is locked out after
+	// too many failed login attempts.
+	ErrAccountLocked = errors.New("account is temporarily locked")
+	// ErrAccountDisabled is returned when an inactive user tries to log in.
+	ErrAccountDisabled = errors.New("account is disabled")
+	// ErrInvalidToken is returned for a missing, malformed, expired or
+	// revoked token.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrUnauthenticated is returned when an operation is called without
+	// a logged in user.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is returned when the user lacks the permission for an
+	// operation.
+	ErrForbidden = errors.New("permission denied")
+)
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// Permission is a single operation a role may perform.
+type Permission string
+
+const (
+	PermPatientsRead     Permission = "patients:read"
+	PermPatientsWrite    Permission = "patients:write"
+	PermPatientsDelete   Permission = "patients:delete"
+	PermWorkOrdersRead   Permission = "work-orders:read"
+	PermWorkOrdersWrite  Permission = "work-orders:write"
+	PermWorkOrdersDelete Permission = "work-orders:delete"
+	PermSpecimensReceive Permission = "specimens:receive"
+	PermResultsRead      Permission = "results:read"
+	PermResultsWrite     Permission = "results:write"
+	PermResultsValidate  Permission = "results:validate"
+	PermResultsAuthorize Permission = "results:authorize"
+	PermResultsAmend     Permission = "results:amend"
+	PermReportsRead      Permission = "reports:read"
+	PermReportsIssue     Permission = "reports:issue"
+	PermMetricsRead      Permission = "metrics:read"
+	PermCatalogRead      Permission = "catalog:read"
+	PermCatalogWrite     Permission = "catalog:write"
+	PermUsersManage      Permission = "users:manage"
+	PermAuditRead        Permission = "audit:read"
+	PermAPIKeysManage    Permission = "api-keys:manage"
+)
+
+// Permissions lists every permission. API key scopes are drawn from it.
+var Permissions = []Permission{
+	PermPatientsRead, PermPatientsWrite, PermPatientsDelete,
+	PermWorkOrdersRead, PermWorkOrdersWrite, PermWorkOrdersDelete,
+	PermSpecimensReceive,
+	PermResultsRead, PermResultsWrite, PermResultsValidate, PermResultsAuthorize, PermResultsAmend,
+	PermReportsRead, PermReportsIssue, PermMetricsRead,
+	PermCatalogRead, PermCatalogWrite,
+	PermUsersManage, PermAuditRead, PermAPIKeysManage,
+}
+
+func (p Permission) Valid() bool {
+	for _, permission := range Permissions {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// rolePermissions is the permission table of each role. Clinical sign-off
+// (validation, authorization, amendment) is deliberately not part of
+// lab_admin; an administrator who also signs results needs the
+// validator role as well.
+var rolePermissions = map[entitiy.Role][]Permission{
+	entitiy.RoleReceptionist: {
+		PermPatientsRead, PermPatientsWrite,
+		PermWorkOrdersRead, PermWorkOrdersWrite, PermResultsRead,
+		PermCatalogRead, PermReportsRead, PermReportsIssue,
+	},
+	entitiy.RolePhlebotomist: {
+		PermPatientsRead, PermWorkOrdersRead, PermResultsRead,
+		PermSpecimensReceive, PermCatalogRead,
+	},
+	entitiy.RoleAnalyst: {
+		PermPatientsRead, PermWorkOrdersRead, PermResultsRead,
+		PermSpecimensReceive, PermResultsWrite,
+		PermCatalogRead, PermReportsRead,
+	},
+	entitiy.RoleValidator: {
+		PermPatientsRead, PermWorkOrdersRead, PermResultsRead,
+		PermResultsValidate, PermResultsAuthorize, PermResultsAmend,
+		PermCatalogRead, PermReportsRead, PermReportsIssue, PermMetricsRead,
+	},
+	entitiy.RolePathologist: {
+		PermPatientsRead, PermWorkOrdersRead, PermResultsRead,
+		PermResultsValidate, PermResultsAmend,
+		PermCatalogRead, PermReportsRead, PermReportsIssue, PermMetricsRead,
+	},
+	entitiy.RoleLabAdmin: {
+		PermPatientsRead, PermPatientsWrite, PermPatientsDelete,
+		PermWorkOrdersRead, PermWorkOrdersWrite, PermWorkOrdersDelete,
+		PermResultsRead, PermCatalogRead, PermCatalogWrite,
+		PermReportsRead, PermMetricsRead, PermUsersManage,
+		PermAuditRead, PermAPIKeysManage,
+	},
+	entitiy.RoleDoctor: {
+		PermPatientsRead, PermWorkOrdersRead, PermResultsRead, PermReportsRead,
+	},
+}
+
+// PermissionsOf returns the union of the permissions of roles.
+func PermissionsOf(roles []entitiy.Role) []Permission {
+	seen := make(map[Permission]bool)
+	var permissions []Permission
+
+	for _, role := range roles {
+		for _, permission := range rolePermissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	return permissions
+}
+
+// Can reports whether the principal holds permission through any of its
+// roles or, for an API key, its scopes.
+func (p *Principal) Can(permission Permission) bool {
+	if p == nil {
+		return false
+	}
+
+	if p.APIKeyID != "" {
+		for _, scope := range p.Scopes {
+			if scope == permission {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, role := range p.Roles {
+		for _, granted := range rolePermissions[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Require checks that the caller stored in ctx holds permission. Usecases
+// call it at the start of every operation so that the rule holds no matter
+// how the operation is reached.
+func Require(ctx context.Context, permission Permission) error {
+	principal := PrincipalFrom(ctx)
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+
+	if !principal.Can(permission) {
+		return fmt.Errorf("%w: %s required", ErrForbidden, permission)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log/slog"
+	"time"
+)
+
+// AuthConfig holds token lifetimes, lockout policy and the bootstrap admin
+// account created on first start.
+type AuthConfig struct {
+	JWTSecret        string        `yaml:"jwt_secret"`
+	AccessTokenTTL   time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL  time.Duration `yaml:"refresh_token_ttl"`
+	MaxLoginAttempts int           `yaml:"max_login_attempts"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration"`
+	AdminUsername    string        `yaml:"admin_username"`
+	AdminPassword    string        `yaml:"admin_password"`
+}
+
+// minJWTSecretLength is the shortest accepted signing secret, in bytes.
+const minJWTSecretLength = 32
+
+func defaultAuthConfig() AuthConfig {
+	return AuthConfig{
+		AccessTokenTTL:   15 * time.Minute,
+		RefreshTokenTTL:  7 * 24 * time.Hour,
+		MaxLoginAttempts: 5,
+		LockoutDuration:  15 * time.Minute,
+		AdminUsername:    "admin",
+	}
+}
+
+func (c *AuthConfig) applyEnv(env *envReader) {
+	env.string(&c.JWTSecret, "JWT_SECRET")
+	env.duration(&c.AccessTokenTTL, "ACCESS_TOKEN_TTL")
+	env.duration(&c.RefreshTokenTTL, "REFRESH_TOKEN_TTL")
+	env.int(&c.MaxLoginAttempts, "MAX_LOGIN_ATTEMPTS")
+	env.duration(&c.LockoutDuration, "LOCKOUT_DURATION")
+	env.string(&c.AdminUsername, "ADMIN_USERNAME")
+	env.string(&c.AdminPassword, "ADMIN_PASSWORD")
+}
+
+func (c AuthConfig) validate(p *problems) {
+	if c.JWTSecret != "" && len(c.JWTSecret) < minJWTSecretLength {
+		p.add("auth.jwt_secret", "must be at least %d characters", minJWTSecretLength)
+	}
+	if c.AccessTokenTTL <= 0 {
+		p.add("auth.access_token_ttl", "must be positive")
+	}
+	if c.RefreshTokenTTL <= 0 {
+		p.add("auth.refresh_token_ttl", "must be positive")
+	}
+	if c.MaxLoginAttempts < 0 {
+		p.add("auth.max_login_attempts", "must not be negative (0 disables lockout)")
+	}
+	if c.LockoutDuration < 0 {
+		p.add("auth.lockout_duration", "must not be negative")
+	}
+	if c.AdminUsername == "" {
+		p.add("auth.admin_username", "is required")
+	}
+}
+
+// EnsureSecret sets a random signing secret when none is configured.
+func (c *AuthConfig) EnsureSecret() {
+	if c.JWTSecret != "" {
+		return
+	}
+
+	slog.Warn("JWT_SECRET is not set, using a random secret; tokens will not survive a restart")
+
+	secret := make([]byte, minJWTSecretLength)
+	rand.Read(secret)
+	c.JWTSecret = base64.StdEncoding.EncodeToString(secret)
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the server and the lis command. It is
+// built from the defaults, then the YAML configuration file, then the
+// environment variables, then the command line flags, each overriding the
+// one before.
+type Config struct {
+	Database    DatabaseConfig     `yaml:"database"`
+	HTTP        HTTPConfig         `yaml:"http"`
+	TLS         TLSConfig          `yaml:"tls"`
+	Instruments []InstrumentConfig `yaml:"instruments"`
+	Log         LogConfig          `yaml:"log"`
+	Health      HealthConfig       `yaml:"health"`
+	Tracing     TracingConfig      `yaml:"tracing"`
+	Auth        AuthConfig         `yaml:"auth"`
+	PII         PIIConfig          `yaml:"pii"`
+	Lab         LabConfig          `yaml:"lab"`
+}
+
+// Flags are the settings that can be given on the command line.
+type Flags struct {
+	File      string
+	HTTPAddr  string
+	DBDriver  string
+	LogLevel  string
+	LogFormat string
+}
+
+// RegisterFlags defines the configuration flags on fs. Flags left empty
+// do not override the file or the environment.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{}
+	fs.StringVar(&flags.File, "config", "", "configuration file (default $LIS_CONFIG)")
+	fs.StringVar(&flags.HTTPAddr, "http-addr", "", "HTTP listen address, e.g. :8080")
+	fs.StringVar(&flags.DBDriver, "db-driver", "", "database driver: mysql, postgres or sqlite")
+	fs.StringVar(&flags.LogLevel, "log-level", "", "log level: debug, info, warn or error")
+	fs.StringVar(&flags.LogFormat, "log-format", "", "log format: text or json")
+	return flags
+}
+
+// Load builds and validates the configuration. The configuration file is
+// the -config flag, or LIS_CONFIG; without either only the defaults and
+// the environment are used. flags may be nil.
+func Load(flags *Flags) (*Config, error) {
+	if flags == nil {
+		flags = &Flags{}
+	}
+
+	config := Default()
+
+	path := flags.File
+	if path == "" {
+		path = os.Getenv("LIS_CONFIG")
+	}
+
+	if path != "" {
+		if err := config.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	env := &envReader{}
+	config.applyEnv(env)
+	if err := env.err(); err != nil {
+		return nil, err
+	}
+
+	config.applyFlags(flags)
+	config.resolve()
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Default returns the configuration used when nothing is configured.
+func Default() *Config {
+	return &Config{
+		Database: defaultDatabaseConfig(),
+		HTTP:     defaultHTTPConfig(),
+		TLS:      defaultTLSConfig(),
+		Log:      defaultLogConfig(),
+		Health:   defaultHealthConfig(),
+		Tracing:  defaultTracingConfig(),
+		Auth:     defaultAuthConfig(),
+		Lab:      defaultLabConfig(),
+	}
+}
+
+// readFile overlays the YAML file at path. Unknown keys are errors, so
+// that a misspelt setting is not silently ignored.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) applyEnv(env *envReader) {
+	c.Database.applyEnv(env)
+	c.HTTP.applyEnv(env)
+	c.TLS.applyEnv(env)
+	c.Log.applyEnv(env)
+	c.Health.applyEnv(env)
+	c.Tracing.applyEnv(env)
+	c.Auth.applyEnv(env)
+	c.PII.applyEnv(env)
+	c.Lab.applyEnv(env)
+}
+
+func (c *Config) applyFlags(flags *Flags) {
+	setString(&c.HTTP.Addr, flags.HTTPAddr)
+	setString((*string)(&c.Database.Dialect), strings.ToLower(flags.DBDriver))
+	setString(&c.Log.Level, flags.LogLevel)
+	setString(&c.Log.Format, flags.LogFormat)
+}
+
+// resolve fills in the defaults that depend on other settings.
+func (c *Config) resolve() {
+	c.Database.resolve()
+}
+
+// Validate checks the whole configuration and reports every problem at
+// once.
+func (c *Config) Validate() error {
+	var p problems
+
+	c.Database.validate(&p)
+	c.HTTP.validate(&p)
+	c.TLS.validate(&p)
+	validateInstruments(c.Instruments, c.HTTP.Addr, c.TLS.Enabled(), &p)
+	c.Log.validate(&p)
+	c.Health.validate(&p)
+	c.Tracing.validate(&p)
+	c.Auth.validate(&p)
+	c.PII.validate(&p)
+	c.Lab.validate(&p)
+
+	return p.err()
+}
+
+// Redacted returns the configuration as YAML with passwords, secrets and
+// keys masked, for display.
+func (c *Config) Redacted() ([]byte, error) {
+	redacted := *c
+	mask(&redacted.Database.Password)
+	mask(&redacted.Auth.JWTSecret)
+	mask(&redacted.Auth.AdminPassword)
+	mask(&redacted.PII.Keys)
+	mask(&redacted.PII.IndexKey)
+
+	return yaml.Marshal(&redacted)
+}
+
+func mask(value *string) {
+	if *value != "" {
+		*value = "********"
+	}
+}
+
+func setString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+// ValidationError lists every problem found in a configuration, each as
+// the setting followed by what is wrong with it.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// problems collects validation problems.
+type problems []string
+
+func (p *problems) add(setting, format string, args ...interface{}) {
+	*p = append(*p, setting+": "+fmt.Sprintf(format, args...))
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
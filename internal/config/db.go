@@ -3,46 +3,148 @@ package config
 import (
 	"database/sql"
 	"fmt"
-	"os"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/XSAM/otelsql"
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
+// DatabaseConfig selects the database by its dialect (driver: mysql,
+// postgres or sqlite). Host, Port, User, Password and Name apply to MySQL
+// and PostgreSQL; Path is the database file of SQLite. Port 0 is the
+// default port of the driver.
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Database string
+	Dialect         dialect.Dialect `yaml:"driver"`
+	Host            string          `yaml:"host"`
+	Port            int             `yaml:"port"`
+	User            string          `yaml:"user"`
+	Password        string          `yaml:"password"`
+	Database        string          `yaml:"name"`
+	SSLMode         string          `yaml:"sslmode"`
+	Path            string          `yaml:"path"`
+	MaxOpenConns    int             `yaml:"max_open_conns"`
+	MaxIdleConns    int             `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration   `yaml:"conn_max_lifetime"`
 }
 
-func GetDatabaseConfig() DatabaseConfig {
+// postgresSSLModes are the sslmode values PostgreSQL accepts.
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+func defaultDatabaseConfig() DatabaseConfig {
 	return DatabaseConfig{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "3306"),
-		User:     getEnv("DB_USER", "root"),
-		Password: getEnv("DB_PASSWORD", "password"),
-		Database: getEnv("DB_NAME", "lis_db"),
+		Dialect:         dialect.MySQL,
+		Host:            "localhost",
+		Database:        "lis_db",
+		SSLMode:         "prefer",
+		Path:            "lis.db",
+		MaxOpenConns:    25,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 5 * time.Minute,
+	}
+}
+
+func (c *DatabaseConfig) applyEnv(env *envReader) {
+	env.string((*string)(&c.Dialect), "DB_DRIVER")
+	env.string(&c.Host, "DB_HOST")
+	env.int(&c.Port, "DB_PORT")
+	env.string(&c.User, "DB_USER")
+	env.string(&c.Password, "DB_PASSWORD")
+	env.string(&c.Database, "DB_NAME")
+	env.string(&c.SSLMode, "DB_SSLMODE")
+	env.string(&c.Path, "DB_PATH")
+	env.int(&c.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	env.int(&c.MaxIdleConns, "DB_MAX_IDLE_CONNS")
+	env.duration(&c.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME")
+}
+
+func (c *DatabaseConfig) resolve() {
+	c.Dialect = dialect.Dialect(strings.ToLower(string(c.Dialect)))
+
+	if c.Port == 0 {
+		switch c.Dialect {
+		case dialect.Postgres:
+			c.Port = 5432
+		case dialect.MySQL:
+			c.Port = 3306
+		}
 	}
 }
 
+func (c DatabaseConfig) validate(p *problems) {
+	if _, err := dialect.Parse(string(c.Dialect)); err != nil {
+		p.add("database.driver", "%q is not supported; use mysql, postgres or sqlite", c.Dialect)
+		return
+	}
+
+	if c.Dialect == dialect.SQLite {
+		if c.Path == "" {
+			p.add("database.path", "is required for sqlite")
+		}
+	} else {
+		if c.Host == "" {
+			p.add("database.host", "is required")
+		}
+		if c.Port < 1 || c.Port > 65535 {
+			p.add("database.port", "must be between 1 and 65535")
+		}
+		if c.User == "" {
+			p.add("database.user", "is required")
+		}
+		if c.Password == "" {
+			p.add("database.password", "is required")
+		}
+		if c.Database == "" {
+			p.add("database.name", "is required")
+		}
+	}
+
+	if c.Dialect == dialect.Postgres && !containsString(postgresSSLModes, c.SSLMode) {
+		p.add("database.sslmode", "must be one of %s", strings.Join(postgresSSLModes, ", "))
+	}
+
+	if c.MaxOpenConns < 1 {
+		p.add("database.max_open_conns", "must be 1 or greater")
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConns > c.MaxOpenConns {
+		p.add("database.max_idle_conns", "must be between 0 and max_open_conns")
+	}
+	if c.ConnMaxLifetime < 0 {
+		p.add("database.conn_max_lifetime", "must not be negative")
+	}
+}
+
+// NewDatabaseConnection opens and pings the configured database. Each
+// statement is traced as a span of the trace of its context; row reads and
+// session resets, which would add a span per row or per query, are not.
 func NewDatabaseConnection(config DatabaseConfig) (*sql.DB, error) {
-	dsn := fmt.Sprintf("%s:@tcp(%s:%s)/%s?parseTime=true",
-		config.User,
-		config.Host,
-		config.Port,
-		config.Database,
-	)
+	d, err := dialect.Parse(string(config.Dialect))
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := otelsql.Open(d.Driver(), dataSourceName(d, config),
+		otelsql.WithAttributes(dbSystem(d)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
@@ -51,9 +153,54 @@ func NewDatabaseConnection(config DatabaseConfig) (*sql.DB, error) {
 	return db, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func dataSourceName(d dialect.Dialect, config DatabaseConfig) string {
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+
+	switch d {
+	case dialect.Postgres:
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(config.User, config.Password),
+			Host:     addr,
+			Path:     "/" + config.Database,
+			RawQuery: url.Values{"sslmode": {config.SSLMode}}.Encode(),
+		}
+		return dsn.String()
+	case dialect.SQLite:
+		// Foreign keys are off by default in SQLite. Transactions take the
+		// write lock when they begin, which stands in for SELECT ... FOR
+		// UPDATE, and wait for each other instead of failing with
+		// SQLITE_BUSY.
+		return "file:" + config.Path + "?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+	default:
+		dsn := mysql.NewConfig()
+		dsn.User = config.User
+		dsn.Passwd = config.Password
+		dsn.Net = "tcp"
+		dsn.Addr = addr
+		dsn.DBName = config.Database
+		dsn.ParseTime = true
+		return dsn.FormatDSN()
+	}
+}
+
+// dbSystem is the OpenTelemetry name of the database of d.
+func dbSystem(d dialect.Dialect) attribute.KeyValue {
+	switch d {
+	case dialect.Postgres:
+		return semconv.DBSystemNamePostgreSQL
+	case dialect.SQLite:
+		return semconv.DBSystemNameSQLite
+	default:
+		return semconv.DBSystemNameMySQL
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
-	return defaultValue
+	return false
 }
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envReader overrides settings with the environment variables that are
+// set. Malformed numbers and durations are collected as errors instead of
+// being ignored.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) string(target *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
+}
+
+func (e *envReader) int(target *int, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a whole number", key, value))
+		return
+	}
+	*target = n
+}
+
+func (e *envReader) float(target *float64, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a number", key, value))
+		return
+	}
+	*target = f
+}
+
+func (e *envReader) duration(target *time.Duration, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a duration such as 30s or 15m", key, value))
+		return
+	}
+	*target = d
+}
+
+func (e *envReader) err() error {
+	return errors.Join(e.errs...)
+}
@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// HealthConfig tunes the readiness checks. CheckTimeout bounds each check,
+// so that a hung database answers as failed instead of blocking the probe;
+// disks with less than MinFreeDiskMB megabytes free are reported as a
+// warning.
+type HealthConfig struct {
+	CheckTimeout  time.Duration `yaml:"check_timeout"`
+	MinFreeDiskMB int           `yaml:"min_free_disk_mb"`
+}
+
+func defaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		CheckTimeout:  2 * time.Second,
+		MinFreeDiskMB: 1024,
+	}
+}
+
+func (c *HealthConfig) applyEnv(env *envReader) {
+	env.duration(&c.CheckTimeout, "HEALTH_CHECK_TIMEOUT")
+	env.int(&c.MinFreeDiskMB, "HEALTH_MIN_FREE_DISK_MB")
+}
+
+func (c HealthConfig) validate(p *problems) {
+	if c.CheckTimeout <= 0 {
+		p.add("health.check_timeout", "must be positive")
+	}
+	if c.MinFreeDiskMB < 0 {
+		p.add("health.min_free_disk_mb", "must not be negative")
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"crypto/tls"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/tlscert"
+)
+
+// HTTPConfig is the listen address and timeouts of the HTTP server.
+// ShutdownTimeout bounds how long the server waits on SIGINT or SIGTERM for
+// requests in progress to finish.
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+}
+
+func defaultHTTPConfig() HTTPConfig {
+	return HTTPConfig{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+	}
+}
+
+func (c *HTTPConfig) applyEnv(env *envReader) {
+	env.string(&c.Addr, "HTTP_ADDR")
+	env.duration(&c.ReadHeaderTimeout, "HTTP_READ_HEADER_TIMEOUT")
+	env.duration(&c.ReadTimeout, "HTTP_READ_TIMEOUT")
+	env.duration(&c.WriteTimeout, "HTTP_WRITE_TIMEOUT")
+	env.duration(&c.IdleTimeout, "HTTP_IDLE_TIMEOUT")
+	env.duration(&c.ShutdownTimeout, "HTTP_SHUTDOWN_TIMEOUT")
+}
+
+func (c HTTPConfig) validate(p *problems) {
+	checkListenAddr(p, "http.addr", c.Addr)
+
+	timeouts := []struct {
+		setting string
+		value   time.Duration
+	}{
+		{"http.read_header_timeout", c.ReadHeaderTimeout},
+		{"http.read_timeout", c.ReadTimeout},
+		{"http.write_timeout", c.WriteTimeout},
+		{"http.idle_timeout", c.IdleTimeout},
+	}
+
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			p.add(timeout.setting, "must not be negative (0 means no timeout)")
+		}
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		p.add("http.shutdown_timeout", "must be positive")
+	}
+}
+
+// TLSConfig is the certificate the HTTP server presents. Without one the
+// server speaks plain HTTP. Instruments with tls set are to present it too,
+// but instrument ports are not opened yet, so only HTTP is served over
+// TLS. ClientAuth selects whether clients must present a certificate issued
+// by the CA in ClientCAFile: none, optional (verified when presented) or
+// require. It only filters connections: a verified certificate does not
+// identify a user or API key, so requests still need a token or key.
+type TLSConfig struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"`
+	ClientAuth   string `yaml:"client_auth"`
+	MinVersion   string `yaml:"min_version"`
+}
+
+var (
+	tlsClientAuths = map[string]tls.ClientAuthType{
+		"none":     tls.NoClientCert,
+		"optional": tls.VerifyClientCertIfGiven,
+		"require":  tls.RequireAndVerifyClientCert,
+	}
+	tlsVersions = map[string]uint16{
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+func defaultTLSConfig() TLSConfig {
+	return TLSConfig{
+		ClientAuth: "none",
+		MinVersion: "1.2",
+	}
+}
+
+// Enabled reports whether a certificate is configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+func (c *TLSConfig) applyEnv(env *envReader) {
+	env.string(&c.CertFile, "TLS_CERT_FILE")
+	env.string(&c.KeyFile, "TLS_KEY_FILE")
+	env.string(&c.ClientCAFile, "TLS_CLIENT_CA_FILE")
+	env.string(&c.ClientAuth, "TLS_CLIENT_AUTH")
+	env.string(&c.MinVersion, "TLS_MIN_VERSION")
+}
+
+func (c TLSConfig) validate(p *problems) {
+	clientAuth, ok := tlsClientAuths[c.ClientAuth]
+	if !ok {
+		p.add("tls.client_auth", "must be one of none, optional, require")
+	}
+	if _, ok := tlsVersions[c.MinVersion]; !ok {
+		p.add("tls.min_version", "must be 1.2 or 1.3")
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		p.add("tls", "cert_file and key_file must be set together")
+		return
+	}
+
+	if !c.Enabled() {
+		if clientAuth != tls.NoClientCert || c.ClientCAFile != "" {
+			p.add("tls", "client certificates require cert_file and key_file")
+		}
+		return
+	}
+
+	checkFile(p, "tls.cert_file", c.CertFile)
+	checkFile(p, "tls.key_file", c.KeyFile)
+
+	if c.ClientCAFile != "" {
+		checkFile(p, "tls.client_ca_file", c.ClientCAFile)
+	} else if clientAuth != tls.NoClientCert {
+		p.add("tls.client_ca_file", "is required when client_auth is %s", c.ClientAuth)
+	}
+}
+
+// LoadCertificates loads the certificate, its key and the client CA.
+// They are read again when the reloader receives SIGHUP.
+func LoadCertificates(config TLSConfig) (*tlscert.Reloader, error) {
+	return tlscert.New(tlscert.Files{
+		CertFile:     config.CertFile,
+		KeyFile:      config.KeyFile,
+		ClientCAFile: config.ClientCAFile,
+	}, &tls.Config{
+		ClientAuth: tlsClientAuths[config.ClientAuth],
+		MinVersion: tlsVersions[config.MinVersion],
+	})
+}
+
+// checkListenAddr checks that addr is a host:port address with a valid
+// port. The host may be empty to listen on every interface.
+func checkListenAddr(p *problems, setting, addr string) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		p.add(setting, "%q is not a host:port address such as :8080", addr)
+		return
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		p.add(setting, "%q does not have a port between 0 and 65535", addr)
+	}
+}
+
+// checkFile checks that path is a readable regular file.
+func checkFile(p *problems, setting, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		p.add(setting, "%v", err)
+		return
+	}
+
+	if info.IsDir() {
+		p.add(setting, "%s is a directory", path)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstrumentConfig is a port on which an analyzer connects to send results
+// and receive orders. The server does not open instrument ports yet: they
+// are validated so that they can be planned alongside the HTTP address,
+// and a warning is logged at startup for each one.
+// TLS is meant to wrap the connections in TLS with the certificate and
+// client authentication of the tls section; it is validated, but has no
+// effect until instrument ports are opened.
+type InstrumentConfig struct {
+	Name     string `yaml:"name"`
+	Protocol string `yaml:"protocol"`
+	Listen   string `yaml:"listen"`
+	TLS      bool   `yaml:"tls"`
+}
+
+// instrumentProtocols are the supported analyzer protocols.
+var instrumentProtocols = []string{"astm", "hl7"}
+
+// validateInstruments checks every instrument, and that no two listeners,
+// including the HTTP server at httpAddr, share a port. tlsEnabled reports
+// whether a certificate is configured for instruments with tls set.
+func validateInstruments(instruments []InstrumentConfig, httpAddr string, tlsEnabled bool, p *problems) {
+	names := make(map[string]bool)
+	listeners := map[string]string{httpAddr: "http.addr"}
+
+	for i, instrument := range instruments {
+		setting := fmt.Sprintf("instruments[%d]", i)
+
+		if instrument.Name == "" {
+			p.add(setting+".name", "is required")
+		} else if names[instrument.Name] {
+			p.add(setting+".name", "%q is used by another instrument", instrument.Name)
+		}
+		names[instrument.Name] = true
+
+		if !containsString(instrumentProtocols, instrument.Protocol) {
+			p.add(setting+".protocol", "must be one of %s", strings.Join(instrumentProtocols, ", "))
+		}
+
+		checkListenAddr(p, setting+".listen", instrument.Listen)
+
+		if other, ok := listeners[instrument.Listen]; ok {
+			p.add(setting+".listen", "%q is also the address of %s", instrument.Listen, other)
+		}
+		listeners[instrument.Listen] = setting
+
+		if instrument.TLS && !tlsEnabled {
+			p.add(setting+".tls", "requires tls.cert_file and tls.key_file")
+		}
+	}
+}
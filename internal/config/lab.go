@@ -0,0 +1,41 @@
+package config
+
+import "net/url"
+
+// LabConfig holds the laboratory identity printed on patient reports.
+type LabConfig struct {
+	Name      string `yaml:"name"`
+	Address   string `yaml:"address"`
+	Phone     string `yaml:"phone"`
+	Email     string `yaml:"email"`
+	LogoPath  string `yaml:"logo_path"`
+	VerifyURL string `yaml:"verify_url"`
+}
+
+func defaultLabConfig() LabConfig {
+	return LabConfig{
+		Name:      "Clinical Laboratory",
+		VerifyURL: "http://localhost:8080/verify",
+	}
+}
+
+func (c *LabConfig) applyEnv(env *envReader) {
+	env.string(&c.Name, "LAB_NAME")
+	env.string(&c.Address, "LAB_ADDRESS")
+	env.string(&c.Phone, "LAB_PHONE")
+	env.string(&c.Email, "LAB_EMAIL")
+	env.string(&c.LogoPath, "LAB_LOGO_PATH")
+	env.string(&c.VerifyURL, "LAB_VERIFY_URL")
+}
+
+func (c LabConfig) validate(p *problems) {
+	if c.Name == "" {
+		p.add("lab.name", "is required")
+	}
+	if u, err := url.Parse(c.VerifyURL); err != nil || !u.IsAbs() {
+		p.add("lab.verify_url", "must be an absolute URL")
+	}
+	if c.LogoPath != "" {
+		checkFile(p, "lab.logo_path", c.LogoPath)
+	}
+}
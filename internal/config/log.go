@@ -0,0 +1,58 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/logging"
+)
+
+// LogConfig selects the level and format of the server log.
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"text", "json"}
+)
+
+func defaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:  "info",
+		Format: "text",
+	}
+}
+
+func (c *LogConfig) applyEnv(env *envReader) {
+	env.string(&c.Level, "LOG_LEVEL")
+	env.string(&c.Format, "LOG_FORMAT")
+}
+
+func (c LogConfig) validate(p *problems) {
+	if !containsString(logLevels, strings.ToLower(c.Level)) {
+		p.add("log.level", "must be one of %s", strings.Join(logLevels, ", "))
+	}
+	if !containsString(logFormats, strings.ToLower(c.Format)) {
+		p.add("log.format", "must be one of %s", strings.Join(logFormats, ", "))
+	}
+}
+
+// NewLogger returns a logger writing to standard error at the configured
+// level and format. Records carry the request ID of their context, and
+// patient data is redacted from them.
+func NewLogger(config LogConfig) *slog.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(config.Level))
+
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, options)
+	if strings.ToLower(config.Format) == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	}
+
+	return slog.New(logging.NewHandler(handler))
+}
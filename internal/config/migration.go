@@ -1,19 +1,130 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
 )
 
-func RunMigrations(db *sql.DB) error {
-	migrationFile := "migrations/001_create_tables.sql"
+const migrationRoot = "migrations"
+
+// RunMigrations applies every migrations/<dialect>/*.sql file that has not
+// been recorded in schema_migrations yet, in file name order. Each dialect
+// has its own scripts; a schema change adds a file of the same name to
+// every directory.
+func RunMigrations(db *sql.DB, d dialect.Dialect) error {
+	files, err := migrationFiles(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	applied, err := appliedMigrations(context.Background(), db)
+	if err != nil {
+		return err
+	}
+
+	executedCount := 0
+	for _, file := range files {
+		version := filepath.Base(file)
+		if applied[version] {
+			continue
+		}
+
+		if err := runMigrationFile(db, file); err != nil {
+			return err
+		}
+
+		if _, err := db.Exec(d.Rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), version); err != nil {
+			return fmt.Errorf("failed to record migration %s: %v", version, err)
+		}
+
+		slog.Info("Applied migration", "version", version)
+		executedCount++
+	}
+
+	slog.Info("Database migrations completed", "applied", executedCount)
+	return nil
+}
+
+// PendingMigrations returns the versions of the migrations/<dialect>/*.sql
+// files that have not been applied, in the order they would be applied.
+// A migration is pending when the server runs against a database another
+// instance has not migrated, or after the files were updated underneath a
+// running server.
+func PendingMigrations(ctx context.Context, db *sql.DB, d dialect.Dialect) ([]string, error) {
+	files, err := migrationFiles(d)
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := os.Stat(migrationFile); os.IsNotExist(err) {
-		return fmt.Errorf("migration file not found: %s", migrationFile)
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, file := range files {
+		if version := filepath.Base(file); !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+
+	return pending, nil
+}
+
+// migrationFiles lists the migration scripts of d in file name order.
+func migrationFiles(d dialect.Dialect) ([]string, error) {
+	migrationDir := filepath.Join(migrationRoot, string(d))
+	if _, err := os.Stat(migrationDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("migration directory not found: %s", migrationDir)
+	}
+
+	files, err := filepath.Glob(filepath.Join(migrationDir, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %v", err)
 	}
+	sort.Strings(files)
 
+	return files, nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %v", err)
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func runMigrationFile(db *sql.DB, migrationFile string) error {
 	content, err := os.ReadFile(migrationFile)
 	if err != nil {
 		return fmt.Errorf("failed to read migration file: %v", err)
@@ -33,21 +144,18 @@ func RunMigrations(db *sql.DB) error {
 
 	statements := strings.Split(cleanSQL.String(), ";")
 
-	executedCount := 0
 	for i, stmt := range statements {
 		stmt = strings.TrimSpace(stmt)
 		if stmt == "" {
 			continue
 		}
 
-		fmt.Printf("Executing migration statement %d...\n", i+1)
+		slog.Debug("Executing migration statement", "file", filepath.Base(migrationFile), "statement", i+1)
 		_, err := db.Exec(stmt)
 		if err != nil {
-			return fmt.Errorf("failed to execute migration statement %d: %v\nStatement: %s", i+1, err, stmt)
+			return fmt.Errorf("failed to execute migration statement %d of %s: %v\nStatement: %s", i+1, migrationFile, err, stmt)
 		}
-		executedCount++
 	}
 
-	fmt.Printf("✓ Database migrations completed successfully (%d statements executed)\n", executedCount)
 	return nil
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+)
+
+// PIIConfig points at the keys used to encrypt patient contact details.
+// Either KeyFile or Keys and IndexKey must be set.
+type PIIConfig struct {
+	KeyFile   string `yaml:"key_file"`
+	Keys      string `yaml:"keys"`
+	ActiveKey string `yaml:"active_key"`
+	IndexKey  string `yaml:"index_key"`
+}
+
+func (c *PIIConfig) applyEnv(env *envReader) {
+	env.string(&c.KeyFile, "PII_KEY_FILE")
+	env.string(&c.Keys, "PII_KEYS")
+	env.string(&c.ActiveKey, "PII_ACTIVE_KEY")
+	env.string(&c.IndexKey, "PII_INDEX_KEY")
+}
+
+func (c PIIConfig) validate(p *problems) {
+	switch {
+	case c.KeyFile != "":
+		checkFile(p, "pii.key_file", c.KeyFile)
+	case c.Keys == "":
+		p.add("pii", "set key_file, or keys and index_key (generate keys with `lis pii genkey`)")
+	case c.IndexKey == "":
+		p.add("pii.index_key", "is required with keys")
+	}
+}
+
+// LoadKeyring loads the PII keyring from the key file, or from the
+// environment when no key file is configured.
+func LoadKeyring(config PIIConfig) (*fieldcrypt.Keyring, error) {
+	if config.KeyFile != "" {
+		return fieldcrypt.LoadKeyFile(config.KeyFile)
+	}
+
+	if config.Keys == "" {
+		return nil, fmt.Errorf("PII encryption keys are not configured: set pii.key_file or PII_KEY_FILE, or PII_KEYS and PII_INDEX_KEY (generate keys with `lis pii genkey`)")
+	}
+
+	return fieldcrypt.ParseKeyring(config.Keys, config.ActiveKey, config.IndexKey)
+}
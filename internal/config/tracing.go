@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracingConfig exports OpenTelemetry traces over OTLP/HTTP to Endpoint,
+// the base URL of a collector such as http://localhost:4318. Tracing is
+// off while Endpoint is empty. SampleRatio is the share of requests
+// traced, between 0 and 1.
+type TracingConfig struct {
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+func defaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		ServiceName: "lis",
+		SampleRatio: 1,
+	}
+}
+
+// applyEnv reads the standard OpenTelemetry environment variables.
+func (c *TracingConfig) applyEnv(env *envReader) {
+	env.string(&c.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+	env.string(&c.ServiceName, "OTEL_SERVICE_NAME")
+	env.float(&c.SampleRatio, "OTEL_TRACES_SAMPLER_ARG")
+}
+
+// Enabled reports whether traces are exported.
+func (c TracingConfig) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+func (c TracingConfig) validate(p *problems) {
+	if c.Enabled() {
+		if u, err := url.Parse(c.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			p.add("tracing.endpoint", "must be an http or https URL such as http://localhost:4318")
+		}
+		if c.ServiceName == "" {
+			p.add("tracing.service_name", "is required")
+		}
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		p.add("tracing.sample_ratio", "must be between 0 and 1")
+	}
+}
+
+// NewTracerProvider returns a provider exporting to the configured
+// collector. Spans are sent to the traces path of the endpoint,
+// /v1/traces, unless the endpoint has a path of its own.
+func NewTracerProvider(ctx context.Context, config TracingConfig) (*sdktrace.TracerProvider, error) {
+	endpoint := config.Endpoint
+	if u, err := url.Parse(endpoint); err == nil && strings.Trim(u.Path, "/") == "" {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	return tracing.NewProvider(exporter, config.ServiceName, config.SampleRatio), nil
+}
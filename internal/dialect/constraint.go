@@ -0,0 +1,88 @@
+package dialect
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Violation is the kind of constraint a write violated.
+type Violation int
+
+const (
+	// NoViolation is reported for errors that are not constraint
+	// violations.
+	NoViolation Violation = iota
+	// Duplicate is a unique or primary key violation.
+	Duplicate
+	// Referenced is a delete or key change of a row other rows still
+	// reference.
+	Referenced
+	// MissingReference is a foreign key pointing at a row that does not
+	// exist.
+	MissingReference
+)
+
+// MySQL error numbers of constraint violations.
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+)
+
+// PostgreSQL SQLSTATE codes of constraint violations.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// ViolationOf classifies err, as returned by any of the supported drivers.
+// SQLite reports every foreign key violation alike, at times without the
+// extended code; it is classified as MissingReference, and deletes treat
+// it as Referenced.
+func ViolationOf(err error) Violation {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return Duplicate
+		case mysqlRowIsReferenced:
+			return Referenced
+		case mysqlNoReferencedRow:
+			return MissingReference
+		}
+		return NoViolation
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return Duplicate
+		case pgForeignKeyViolation:
+			if strings.HasPrefix(pgErr.Message, "update or delete") {
+				return Referenced
+			}
+			return MissingReference
+		}
+		return NoViolation
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return Duplicate
+		case sqlite3.ErrConstraintForeignKey:
+			return MissingReference
+		}
+		if sqliteErr.Code == sqlite3.ErrConstraint && strings.Contains(sqliteErr.Error(), "FOREIGN KEY") {
+			return MissingReference
+		}
+	}
+
+	return NoViolation
+}
@@ -0,0 +1,114 @@
+// Package dialect holds what differs between the SQL databases the LIS
+// runs on: the driver, the placeholder syntax, row locking, generated keys
+// and the constraint violation errors. Repositories write their queries
+// with ? placeholders and portable SQL, and pass them through the dialect
+// of the database they are connected to.
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is a supported database, named as in the DB_DRIVER setting.
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// Parse returns the dialect named name.
+func Parse(name string) (Dialect, error) {
+	switch d := Dialect(name); d {
+	case MySQL, Postgres, SQLite:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q: use mysql, postgres or sqlite", name)
+	}
+}
+
+// Driver returns the database/sql driver name of the dialect.
+func (d Dialect) Driver() string {
+	switch d {
+	case Postgres:
+		return "pgx"
+	case SQLite:
+		return "sqlite3"
+	default:
+		return "mysql"
+	}
+}
+
+// Rebind rewrites the ? placeholders of query into the placeholder syntax
+// of the dialect. Question marks inside string literals are left alone.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	n := 0
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// ForUpdate returns the clause that locks the selected rows until the end
+// of the transaction. SQLite has no row locks; its connections begin every
+// transaction with an immediate write lock on the database instead (see
+// config.NewDatabaseConnection), which serializes writers the same way.
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// Like returns a case-insensitive LIKE condition on column with one
+// placeholder for the pattern. MySQL and SQLite compare case-insensitively
+// already; PostgreSQL needs ILIKE.
+func (d Dialect) Like(column string) string {
+	if d == Postgres {
+		return column + " ILIKE ?"
+	}
+	return column + " LIKE ?"
+}
+
+// InsertID runs query, an INSERT into a table with an auto-increment id
+// column, and returns the id of the new row. PostgreSQL does not report
+// generated keys, so the id is returned by the statement itself.
+func (d Dialect) InsertID(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	query = d.Rebind(query)
+
+	if d == Postgres {
+		var id int64
+		err := tx.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,60 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{Postgres, "SELECT * FROM t WHERE a = ? AND b = ?", "SELECT * FROM t WHERE a = $1 AND b = $2"},
+		{Postgres, "SELECT '?' FROM t WHERE a = ?", "SELECT '?' FROM t WHERE a = $1"},
+		{Postgres, "SELECT 'it''s?' FROM t WHERE a IN (?, ?, ?)", "SELECT 'it''s?' FROM t WHERE a IN ($1, $2, $3)"},
+		{Postgres, "SELECT 1", "SELECT 1"},
+		{MySQL, "SELECT * FROM t WHERE a = ?", "SELECT * FROM t WHERE a = ?"},
+		{SQLite, "SELECT * FROM t WHERE a = ?", "SELECT * FROM t WHERE a = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect)+" "+tt.query, func(t *testing.T) {
+			if got := tt.dialect.Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViolationOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Violation
+	}{
+		{"mysql duplicate", &mysql.MySQLError{Number: 1062}, Duplicate},
+		{"mysql referenced", &mysql.MySQLError{Number: 1451}, Referenced},
+		{"mysql missing reference", &mysql.MySQLError{Number: 1452}, MissingReference},
+		{"mysql other", &mysql.MySQLError{Number: 1064}, NoViolation},
+		{"postgres duplicate", &pgconn.PgError{Code: "23505"}, Duplicate},
+		{"postgres referenced", &pgconn.PgError{Code: "23503", Message: `update or delete on table "work_orders" violates foreign key constraint "result_versions_no_order_fkey" on table "result_versions"`}, Referenced},
+		{"postgres missing reference", &pgconn.PgError{Code: "23503", Message: `insert or update on table "work_orders" violates foreign key constraint "work_orders_patient_id_fkey"`}, MissingReference},
+		{"postgres other", &pgconn.PgError{Code: "42601"}, NoViolation},
+		{"wrapped", fmt.Errorf("failed to create patient: %w", &pgconn.PgError{Code: "23505"}), Duplicate},
+		{"not a driver error", errors.New("connection refused"), NoViolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ViolationOf(tt.err); got != tt.want {
+				t.Errorf("ViolationOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
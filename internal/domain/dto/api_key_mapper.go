@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// ToEntity converts APIKeyRequest to APIKey entity. The key itself is set
+// by the usecase.
+func (req *APIKeyRequest) ToEntity(id, createdBy string) *entitiy.APIKey {
+	return &entitiy.APIKey{
+		ID:        id,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+	}
+}
+
+// ToAPIKeyResponse converts APIKey entity to APIKeyResponse
+func ToAPIKeyResponse(key *entitiy.APIKey) *APIKeyResponse {
+	if key == nil {
+		return nil
+	}
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		Active:     key.Valid(time.Now()),
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		UpdatedAt:  key.UpdatedAt,
+	}
+}
+
+// ToAPIKeyResponseList converts slice of APIKey entities to slice of APIKeyResponse
+func ToAPIKeyResponseList(keys []*entitiy.APIKey) []*APIKeyResponse {
+	if keys == nil {
+		return nil
+	}
+
+	responses := make([]*APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = ToAPIKeyResponse(key)
+	}
+
+	return responses
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+type AuditLogRequest struct {
+	PatientID  string
+	UserID     string
+	EntityType string
+	EntityID   string
+	Action     entitiy.AuditAction
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+type AuditLogResponse struct {
+	ID         int64               `json:"id"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	UserID     string              `json:"user_id,omitempty"`
+	Username   string              `json:"username,omitempty"`
+	Action     entitiy.AuditAction `json:"action"`
+	EntityType string              `json:"entity_type"`
+	EntityID   string              `json:"entity_id"`
+	PatientID  string              `json:"patient_id,omitempty"`
+	Changes    json.RawMessage     `json:"changes,omitempty"`
+	ClientIP   string              `json:"client_ip,omitempty"`
+	RequestID  string              `json:"request_id,omitempty"`
+}
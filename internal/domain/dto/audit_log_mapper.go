@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// ToFilter converts AuditLogRequest to an AuditFilter, applying the
+// default and maximum number of entries.
+func (req *AuditLogRequest) ToFilter() entitiy.AuditFilter {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	if limit > MaxAuditLimit {
+		limit = MaxAuditLimit
+	}
+
+	return entitiy.AuditFilter{
+		PatientID:  req.PatientID,
+		UserID:     req.UserID,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Action:     req.Action,
+		From:       req.From,
+		To:         req.To,
+		Limit:      limit,
+	}
+}
+
+// ToAuditLogResponse converts AuditLog entity to AuditLogResponse
+func ToAuditLogResponse(entry *entitiy.AuditLog) *AuditLogResponse {
+	if entry == nil {
+		return nil
+	}
+
+	response := &AuditLogResponse{
+		ID:         entry.ID,
+		OccurredAt: entry.OccurredAt,
+		UserID:     entry.UserID,
+		Username:   entry.Username,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		PatientID:  entry.PatientID,
+		ClientIP:   entry.ClientIP,
+		RequestID:  entry.RequestID,
+	}
+
+	if entry.Changes != "" {
+		response.Changes = json.RawMessage(entry.Changes)
+	}
+
+	return response
+}
+
+// ToAuditLogResponseList converts slice of AuditLog entities to slice of AuditLogResponse
+func ToAuditLogResponseList(entries []*entitiy.AuditLog) []*AuditLogResponse {
+	if entries == nil {
+		return nil
+	}
+
+	responses := make([]*AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = ToAuditLogResponse(entry)
+	}
+
+	return responses
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse is returned by login and refresh. The refresh token is
+// rotated on every refresh and the previous one stops working.
+type TokenResponse struct {
+	AccessToken      string        `json:"access_token"`
+	TokenType        string        `json:"token_type"`
+	ExpiresAt        time.Time     `json:"expires_at"`
+	RefreshToken     string        `json:"refresh_token"`
+	RefreshExpiresAt time.Time     `json:"refresh_expires_at"`
+	User             *UserResponse `json:"user"`
+}
+
+// ClientInfo identifies where a login or refresh came from; it is stored
+// on the session.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// ETag returns a strong entity tag for a response representation. Any
+// change to the representation, including updated_at, changes the tag.
+func ETag(representation interface{}) string {
+	data, err := json.Marshal(representation)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// MatchesETag reports whether an If-Match header value accepts etag. An
+// empty header accepts anything, so conditional requests stay optional.
+// Weak tags never match, as required for If-Match.
+func MatchesETag(ifMatch, etag string) bool {
+	if ifMatch == "" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// Lab report statuses. A report is final once every test line is
+// authorized, and amended when a released result was corrected afterwards.
+const (
+	LabReportPreliminary = "preliminary"
+	LabReportFinal       = "final"
+	LabReportAmended     = "amended"
+)
+
+type LabReportResponse struct {
+	NoOrder   string              `json:"no_order"`
+	Status    string              `json:"status"`
+	Patient   *PatientResponse    `json:"patient"`
+	Doctor    string              `json:"doctor"`
+	Analyst   string              `json:"analyst"`
+	Priority  entitiy.Priority    `json:"priority"`
+	OrderedAt time.Time           `json:"ordered_at"`
+	Sections  []*LabReportSection `json:"sections"`
+}
+
+// LabReportSection groups the results of one department.
+type LabReportSection struct {
+	Department string             `json:"department"`
+	Results    []*LabReportResult `json:"results"`
+}
+
+type LabReportResult struct {
+	TestCode       string             `json:"test_code"`
+	TestName       string             `json:"test_name"`
+	Status         entitiy.TestStatus `json:"status"`
+	Value          string             `json:"value"`
+	Unit           string             `json:"unit"`
+	ReferenceRange string             `json:"reference_range"`
+	Flag           string             `json:"flag"`
+	Comment        string             `json:"comment"`
+	ValidatedBy    string             `json:"validated_by"`
+	ValidatedAt    *time.Time         `json:"validated_at"`
+	AuthorizedBy   string             `json:"authorized_by"`
+	AuthorizedAt   *time.Time         `json:"authorized_at"`
+	Amended        bool               `json:"amended"`
+	AmendedAt      *time.Time         `json:"amended_at,omitempty"`
+	AmendedBy      string             `json:"amended_by,omitempty"`
+	AmendReason    string             `json:"amend_reason,omitempty"`
+	PreviousValue  string             `json:"previous_value,omitempty"`
+	PreviousFlag   string             `json:"previous_flag,omitempty"`
+}
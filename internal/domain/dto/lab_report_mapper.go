@@ -0,0 +1,90 @@
+package dto
+
+import "github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+
+// ToLabReportResponse builds the patient report of a work order. Only test
+// lines that have a result are reported; they are grouped by department
+// in the order the departments first appear on the work order. Amended
+// results carry the value they replaced, taken from versions.
+func ToLabReportResponse(workOrder *entitiy.WorkOrder, patient *entitiy.Patient, tests []*entitiy.WorkOrderTest, catalog map[string]*entitiy.TestCatalog, versions []*entitiy.ResultVersion) *LabReportResponse {
+	if workOrder == nil {
+		return nil
+	}
+
+	report := &LabReportResponse{
+		NoOrder:   workOrder.NoOrder,
+		Status:    LabReportFinal,
+		Patient:   ToPatientResponse(patient),
+		Doctor:    workOrder.Doctor,
+		Analyst:   workOrder.Analyst,
+		Priority:  workOrder.Priority,
+		OrderedAt: workOrder.CreatedAt,
+	}
+
+	history := make(map[string][]*entitiy.ResultVersion)
+	for _, version := range versions {
+		history[version.TestCode] = append(history[version.TestCode], version)
+	}
+
+	sections := make(map[string]*LabReportSection)
+	amended := false
+
+	for _, test := range tests {
+		if test.Status != entitiy.TestStatusAuthorized {
+			report.Status = LabReportPreliminary
+		}
+
+		if test.ResultedAt == nil {
+			continue
+		}
+
+		result := &LabReportResult{
+			TestCode:     test.TestCode,
+			TestName:     test.TestCode,
+			Status:       test.Status,
+			Value:        test.ResultValue,
+			Flag:         test.ResultFlag,
+			Comment:      test.ResultComment,
+			ValidatedBy:  test.ValidatedBy,
+			ValidatedAt:  test.ValidatedAt,
+			AuthorizedBy: test.AuthorizedBy,
+			AuthorizedAt: test.AuthorizedAt,
+			Amended:      test.AmendedAt != nil,
+			AmendedAt:    test.AmendedAt,
+			AmendedBy:    test.AmendedBy,
+		}
+
+		if result.Amended {
+			amended = true
+			if h := history[test.TestCode]; len(h) >= 2 {
+				latest, previous := h[len(h)-1], h[len(h)-2]
+				result.AmendReason = latest.Reason
+				result.PreviousValue = previous.Value
+				result.PreviousFlag = previous.Flag
+			}
+		}
+
+		department := ""
+		if entry, ok := catalog[test.TestCode]; ok {
+			result.TestName = entry.Name
+			result.Unit = entry.Unit
+			result.ReferenceRange = entry.ReferenceRange
+			department = entry.Department
+		}
+
+		section, ok := sections[department]
+		if !ok {
+			section = &LabReportSection{Department: department}
+			sections[department] = section
+			report.Sections = append(report.Sections, section)
+		}
+
+		section.Results = append(section.Results, result)
+	}
+
+	if amended && report.Status == LabReportFinal {
+		report.Status = LabReportAmended
+	}
+
+	return report
+}
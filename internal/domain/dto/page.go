@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"math"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+	// MaxPage keeps the offset of any page within 32 bits, the smallest
+	// int and OFFSET the server runs with.
+	MaxPage = math.MaxInt32 / MaxPageSize
+)
+
+// Sort fields accepted by the list endpoints. The first entry is the
+// default.
+var (
+	PatientSortFields       = []string{"first_name", "last_name", "birth_date", "created_at"}
+	PatientSearchSortFields = []string{"relevance", "first_name", "last_name", "birth_date", "created_at"}
+	WorkOrderSortFields     = []string{"no_order", "created_at", "priority", "doctor", "analyst"}
+)
+
+// PageRequest is the page, page_size and sort query parameters of a list
+// endpoint. Sort is a field name, prefixed with "-" for descending order.
+// A zero Page or PageSize is a parameter that was not sent and takes the
+// default; the handlers reject an explicit 0.
+type PageRequest struct {
+	Page     int
+	PageSize int
+	Sort     string
+}
+
+// PageMeta describes the page returned by a list endpoint.
+type PageMeta struct {
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	Sort       string `json:"sort"`
+}
+
+// ToPage validates the request against the allowed sort fields and fills
+// in defaults. Zero is not a value here but a parameter left out, so the
+// ranges in the messages start at 1.
+func (req PageRequest) ToPage(sortFields []string) (entitiy.Page, error) {
+	page := entitiy.Page{
+		Limit: DefaultPageSize,
+		Sort:  sortFields[0],
+	}
+
+	if req.PageSize < 0 || req.PageSize > MaxPageSize {
+		return page, apperror.Field("page_size", "must be between 1 and %d", MaxPageSize)
+	}
+	if req.PageSize > 0 {
+		page.Limit = req.PageSize
+	}
+
+	if req.Page < 0 || req.Page > MaxPage {
+		return page, apperror.Field("page", "must be between 1 and %d", MaxPage)
+	}
+	if req.Page > 1 {
+		page.Offset = (req.Page - 1) * page.Limit
+	}
+
+	if req.Sort != "" {
+		field, desc := strings.CutPrefix(req.Sort, "-")
+		if !containsString(sortFields, field) {
+			return page, apperror.Field("sort", "cannot sort by %q; use one of %s", field, strings.Join(sortFields, ", "))
+		}
+		page.Sort = field
+		page.Desc = desc
+	}
+
+	return page, nil
+}
+
+// NewPageMeta returns the metadata of page out of total matching rows.
+func NewPageMeta(page entitiy.Page, total int) *PageMeta {
+	meta := &PageMeta{
+		Page:     page.Offset/page.Limit + 1,
+		PageSize: page.Limit,
+		Total:    total,
+		Sort:     page.Sort,
+	}
+
+	meta.TotalPages = (total + page.Limit - 1) / page.Limit
+
+	if page.Desc {
+		meta.Sort = "-" + page.Sort
+	}
+
+	return meta
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
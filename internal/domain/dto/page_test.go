@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+)
+
+func TestPageRequestToPage(t *testing.T) {
+	sortFields := []string{"created_at", "last_name"}
+
+	tests := []struct {
+		name       string
+		req        PageRequest
+		wantOffset int
+		wantLimit  int
+		err        error
+	}{
+		{"defaults", PageRequest{}, 0, DefaultPageSize, nil},
+		{"page and page size left out", PageRequest{Page: 0, PageSize: 0}, 0, DefaultPageSize, nil},
+		{"first page", PageRequest{Page: 1, PageSize: 20}, 0, 20, nil},
+		{"second page", PageRequest{Page: 2, PageSize: 20}, 20, 20, nil},
+		{"last page", PageRequest{Page: MaxPage, PageSize: MaxPageSize}, (MaxPage - 1) * MaxPageSize, MaxPageSize, nil},
+		{"page past the last", PageRequest{Page: MaxPage + 1}, 0, 0, apperror.ErrValidation},
+		{"negative page", PageRequest{Page: -1}, 0, 0, apperror.ErrValidation},
+		{"negative page size", PageRequest{PageSize: -1}, 0, 0, apperror.ErrValidation},
+		{"page that overflows the offset", PageRequest{Page: 1 << 62, PageSize: MaxPageSize}, 0, 0, apperror.ErrValidation},
+		{"page size too large", PageRequest{PageSize: MaxPageSize + 1}, 0, 0, apperror.ErrValidation},
+		{"unknown sort field", PageRequest{Sort: "-email"}, 0, 0, apperror.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tt.req.ToPage(sortFields)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if page.Offset != tt.wantOffset || page.Limit != tt.wantLimit {
+				t.Errorf("offset, limit = %d, %d, want %d, %d", page.Offset, page.Limit, tt.wantOffset, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -1,30 +1,67 @@
 package dto
 
 import (
+	"log/slog"
 	"time"
 
 	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
 )
 
 type PatientResponse struct {
-	ID        string         `json:"id"`
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	Birthdate time.Time      `json:"birth_date"`
-	Sex       entitiy.Gender `json:"sex"`
-	Address   string         `json:"address"`
-	Phone     string         `json:"phone"`
-	Email     string         `json:"email"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID         string         `json:"id"`
+	FirstName  string         `json:"first_name"`
+	LastName   string         `json:"last_name"`
+	Birthdate  time.Time      `json:"birth_date"`
+	Sex        entitiy.Gender `json:"sex"`
+	Address    string         `json:"address"`
+	Phone      string         `json:"phone"`
+	Email      string         `json:"email"`
+	NationalID string         `json:"national_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// LogValue logs a patient by its ID only, keeping its identity out of the
+// logs.
+func (p *PatientResponse) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", p.ID))
 }
 
 type PatientRequest struct {
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	Birthdate time.Time      `json:"birth_date"`
-	Sex       entitiy.Gender `json:"sex"`
-	Address   string         `json:"address"`
-	Phone     string         `json:"phone"`
-	Email     string         `json:"email"`
+	FirstName  string         `json:"first_name"`
+	LastName   string         `json:"last_name"`
+	Birthdate  time.Time      `json:"birth_date"`
+	Sex        entitiy.Gender `json:"sex"`
+	Address    string         `json:"address"`
+	Phone      string         `json:"phone"`
+	Email      string         `json:"email"`
+	NationalID string         `json:"national_id"`
+}
+
+// PatientListRequest holds the filters and page of GET /patients.
+type PatientListRequest struct {
+	Sex  entitiy.Gender
+	From *time.Time
+	To   *time.Time
+	PageRequest
+}
+
+// PatientSearchRequest holds the search text and page of GET /patients?q=.
+type PatientSearchRequest struct {
+	Query string
+	PageRequest
+}
+
+// PatientPatchRequest is a partial update of a patient. Only the fields
+// present in the request body are changed; an empty string clears an
+// optional field.
+type PatientPatchRequest struct {
+	FirstName  *string         `json:"first_name"`
+	LastName   *string         `json:"last_name"`
+	Birthdate  *time.Time      `json:"birth_date"`
+	Sex        *entitiy.Gender `json:"sex"`
+	Address    *string         `json:"address"`
+	Phone      *string         `json:"phone"`
+	Email      *string         `json:"email"`
+	NationalID *string         `json:"national_id"`
 }
@@ -7,14 +7,15 @@ import (
 // ToEntity converts PatientRequest to Patient entity
 func (req *PatientRequest) ToEntity(id string) *entitiy.Patient {
 	return &entitiy.Patient{
-		ID:        id,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Birthdate: req.Birthdate,
-		Sex:       req.Sex,
-		Address:   req.Address,
-		Phone:     req.Phone,
-		Email:     req.Email,
+		ID:         id,
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		Birthdate:  req.Birthdate,
+		Sex:        req.Sex,
+		Address:    req.Address,
+		Phone:      req.Phone,
+		Email:      req.Email,
+		NationalID: req.NationalID,
 	}
 }
 
@@ -25,16 +26,17 @@ func ToPatientResponse(patient *entitiy.Patient) *PatientResponse {
 	}
 
 	return &PatientResponse{
-		ID:        patient.ID,
-		FirstName: patient.FirstName,
-		LastName:  patient.LastName,
-		Birthdate: patient.Birthdate,
-		Sex:       patient.Sex,
-		Address:   patient.Address,
-		Phone:     patient.Phone,
-		Email:     patient.Email,
-		CreatedAt: patient.CreatedAt,
-		UpdatedAt: patient.UpdatedAt,
+		ID:         patient.ID,
+		FirstName:  patient.FirstName,
+		LastName:   patient.LastName,
+		Birthdate:  patient.Birthdate,
+		Sex:        patient.Sex,
+		Address:    patient.Address,
+		Phone:      patient.Phone,
+		Email:      patient.Email,
+		NationalID: patient.NationalID,
+		CreatedAt:  patient.CreatedAt,
+		UpdatedAt:  patient.UpdatedAt,
 	}
 }
 
@@ -67,4 +69,51 @@ func (req *PatientRequest) UpdateEntity(patient *entitiy.Patient) {
 	if req.Email != "" {
 		patient.Email = req.Email
 	}
+	if req.NationalID != "" {
+		patient.NationalID = req.NationalID
+	}
+}
+
+// ToFilter converts PatientListRequest to PatientFilter
+func (req *PatientListRequest) ToFilter() (entitiy.PatientFilter, error) {
+	page, err := req.ToPage(PatientSortFields)
+	if err != nil {
+		return entitiy.PatientFilter{}, err
+	}
+
+	return entitiy.PatientFilter{
+		Sex:  req.Sex,
+		From: req.From,
+		To:   req.To,
+		Page: page,
+	}, nil
+}
+
+// ApplyTo applies the fields present in PatientPatchRequest to a Patient
+// entity
+func (req *PatientPatchRequest) ApplyTo(patient *entitiy.Patient) {
+	if req.FirstName != nil {
+		patient.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		patient.LastName = *req.LastName
+	}
+	if req.Birthdate != nil {
+		patient.Birthdate = *req.Birthdate
+	}
+	if req.Sex != nil {
+		patient.Sex = *req.Sex
+	}
+	if req.Address != nil {
+		patient.Address = *req.Address
+	}
+	if req.Phone != nil {
+		patient.Phone = *req.Phone
+	}
+	if req.Email != nil {
+		patient.Email = *req.Email
+	}
+	if req.NationalID != nil {
+		patient.NationalID = *req.NationalID
+	}
 }
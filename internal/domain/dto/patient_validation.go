@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/validation"
+)
+
+// earliestBirthdate is the earliest accepted date of birth; anything
+// older is a data entry error.
+var earliestBirthdate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Many Indonesians have a single name, so only the first name is required.
+var (
+	nameRules       = []validation.Rule[string]{validation.Required, validation.MaxLength(100)}
+	lastNameRules   = []validation.Rule[string]{validation.MaxLength(100)}
+	birthdateRules  = []validation.Rule[time.Time]{validation.RequiredTime, validation.NotInFuture, validation.NotBefore(earliestBirthdate)}
+	sexRules        = []validation.Rule[entitiy.Gender]{validation.OneOf(entitiy.Male, entitiy.Female)}
+	phoneRules      = []validation.Rule[string]{validation.Phone}
+	emailRules      = []validation.Rule[string]{validation.Email, validation.MaxLength(100)}
+	nationalIDRules = []validation.Rule[string]{validation.MaxLength(32)}
+)
+
+// Validate checks a new or replacement patient.
+func (req *PatientRequest) Validate() error {
+	return validation.Validate(
+		validation.Field("first_name", req.FirstName, nameRules...),
+		validation.Field("last_name", req.LastName, lastNameRules...),
+		validation.Field("birth_date", req.Birthdate, birthdateRules...),
+		validation.Field("sex", req.Sex, sexRules...),
+		validation.Field("phone", req.Phone, phoneRules...),
+		validation.Field("email", req.Email, emailRules...),
+		validation.Field("national_id", req.NationalID, nationalIDRules...),
+	)
+}
+
+// Validate checks the fields present in a partial update with the same
+// rules as PatientRequest.
+func (req *PatientPatchRequest) Validate() error {
+	return validation.Validate(
+		validation.Optional("first_name", req.FirstName, nameRules...),
+		validation.Optional("last_name", req.LastName, lastNameRules...),
+		validation.Optional("birth_date", req.Birthdate, birthdateRules...),
+		validation.Optional("sex", req.Sex, sexRules...),
+		validation.Optional("phone", req.Phone, phoneRules...),
+		validation.Optional("email", req.Email, emailRules...),
+		validation.Optional("national_id", req.NationalID, nationalIDRules...),
+	)
+}
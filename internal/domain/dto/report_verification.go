@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type IssuedReportResponse struct {
+	VerificationCode string             `json:"verification_code"`
+	ContentHash      string             `json:"content_hash"`
+	IssuedAt         time.Time          `json:"issued_at"`
+	Report           *LabReportResponse `json:"report"`
+}
+
+// ReportVerificationResponse is returned by the public verification
+// endpoint. Patient names are masked since anyone holding the code can
+// call it. Patient and Results are what the report showed when it was
+// issued; reports issued before that content was kept have neither.
+type ReportVerificationResponse struct {
+	VerificationCode string                      `json:"verification_code"`
+	Valid            bool                        `json:"valid"`
+	Amended          bool                        `json:"amended"`
+	NoOrder          string                      `json:"no_order"`
+	Status           string                      `json:"status"`
+	IssuedAt         time.Time                   `json:"issued_at"`
+	LatestIssuedAt   *time.Time                  `json:"latest_issued_at,omitempty"`
+	Patient          *ReportVerificationPatient  `json:"patient,omitempty"`
+	Results          []*ReportVerificationResult `json:"results"`
+}
+
+type ReportVerificationPatient struct {
+	Name      string         `json:"name"`
+	BirthYear int            `json:"birth_year"`
+	Sex       entitiy.Gender `json:"sex"`
+}
+
+type ReportVerificationResult struct {
+	TestName string `json:"test_name"`
+	Value    string `json:"value"`
+	Unit     string `json:"unit"`
+	Flag     string `json:"flag"`
+}
+
+// IssuedReportContent is the part of an issued report shown on
+// verification. It is stored with the issued report.
+type IssuedReportContent struct {
+	Patient *ReportVerificationPatient  `json:"patient,omitempty"`
+	Results []*ReportVerificationResult `json:"results"`
+}
+
+// ToIssuedReportContent converts a lab report to IssuedReportContent
+func ToIssuedReportContent(report *LabReportResponse) *IssuedReportContent {
+	content := &IssuedReportContent{
+		Results: []*ReportVerificationResult{},
+	}
+
+	if p := report.Patient; p != nil {
+		content.Patient = &ReportVerificationPatient{
+			Name:      maskName(p.FirstName) + " " + maskName(p.LastName),
+			BirthYear: p.Birthdate.Year(),
+			Sex:       p.Sex,
+		}
+	}
+
+	for _, section := range report.Sections {
+		for _, result := range section.Results {
+			content.Results = append(content.Results, &ReportVerificationResult{
+				TestName: result.TestName,
+				Value:    result.Value,
+				Unit:     result.Unit,
+				Flag:     result.Flag,
+			})
+		}
+	}
+
+	return content
+}
+
+// ToReportVerificationResponse converts an issued report and its stored
+// content, nil if none was kept, to ReportVerificationResponse
+func ToReportVerificationResponse(issued *entitiy.IssuedReport, content *IssuedReportContent) *ReportVerificationResponse {
+	response := &ReportVerificationResponse{
+		VerificationCode: issued.VerificationCode,
+		Valid:            true,
+		NoOrder:          issued.NoOrder,
+		Status:           issued.Status,
+		IssuedAt:         issued.IssuedAt,
+		Results:          []*ReportVerificationResult{},
+	}
+
+	if content != nil {
+		response.Patient = content.Patient
+		response.Results = content.Results
+	}
+
+	return response
+}
+
+// maskName keeps the first letter of every word, e.g. "Budi" -> "B***".
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
@@ -1,13 +1,23 @@
 package dto
 
+import "github.com/BioSystems-Indonesia/lis/internal/apperror"
+
 type Response struct {
 	Code   int         `json:"code"`
 	Status string      `json:"status"`
 	Data   interface{} `json:"data"`
+	Meta   *PageMeta   `json:"meta,omitempty"`
 }
 
+// ResponseError is the body of every error response. Error is a stable,
+// machine-readable code; Message is meant for people and may change.
 type ResponseError struct {
-	Code    int    `json:"code"`
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	Code    int                   `json:"code"`
+	Status  string                `json:"status"`
+	Error   string                `json:"error"`
+	Message string                `json:"message"`
+	Details []apperror.FieldError `json:"details,omitempty"`
+	// RequestID is sent with internal errors, whose details are only
+	// logged, so that a report can be matched to the server log.
+	RequestID string `json:"request_id,omitempty"`
 }
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type AmendRequest struct {
+	TestCode string `json:"test_code"`
+	Value    string `json:"value"`
+	Flag     string `json:"flag"`
+	Comment  string `json:"comment"`
+	Reason   string `json:"reason"`
+}
+
+type ResultHistoryResponse struct {
+	NoOrder string               `json:"no_order"`
+	Tests   []*TestHistoryResult `json:"tests"`
+}
+
+type TestHistoryResult struct {
+	TestCode  string                   `json:"test_code"`
+	Status    entitiy.TestStatus       `json:"status"`
+	Amended   bool                     `json:"amended"`
+	AmendedAt *time.Time               `json:"amended_at,omitempty"`
+	AmendedBy string                   `json:"amended_by,omitempty"`
+	Versions  []*ResultVersionResponse `json:"versions"`
+}
+
+type ResultVersionResponse struct {
+	Version   int       `json:"version"`
+	Value     string    `json:"value"`
+	Flag      string    `json:"flag"`
+	Comment   string    `json:"comment"`
+	Reason    string    `json:"reason,omitempty"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+import "github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+
+// ToResultVersionResponse converts ResultVersion entity to ResultVersionResponse
+func ToResultVersionResponse(version *entitiy.ResultVersion) *ResultVersionResponse {
+	if version == nil {
+		return nil
+	}
+
+	return &ResultVersionResponse{
+		Version:   version.Version,
+		Value:     version.Value,
+		Flag:      version.Flag,
+		Comment:   version.Comment,
+		Reason:    version.Reason,
+		ChangedBy: version.ChangedBy,
+		ChangedAt: version.ChangedAt,
+	}
+}
+
+// ToResultHistoryResponse groups the result versions of a work order by
+// test line, oldest version first.
+func ToResultHistoryResponse(noOrder string, tests []*entitiy.WorkOrderTest, versions []*entitiy.ResultVersion) *ResultHistoryResponse {
+	byTest := make(map[string][]*ResultVersionResponse)
+	for _, version := range versions {
+		byTest[version.TestCode] = append(byTest[version.TestCode], ToResultVersionResponse(version))
+	}
+
+	response := &ResultHistoryResponse{
+		NoOrder: noOrder,
+		Tests:   make([]*TestHistoryResult, len(tests)),
+	}
+
+	for i, test := range tests {
+		history := byTest[test.TestCode]
+		if history == nil {
+			history = []*ResultVersionResponse{}
+		}
+
+		response.Tests[i] = &TestHistoryResult{
+			TestCode:  test.TestCode,
+			Status:    test.Status,
+			Amended:   test.AmendedAt != nil,
+			AmendedAt: test.AmendedAt,
+			AmendedBy: test.AmendedBy,
+			Versions:  history,
+		}
+	}
+
+	return response
+}
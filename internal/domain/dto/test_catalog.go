@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type TestCatalogRequest struct {
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	Department     string `json:"department"`
+	Instrument     string `json:"instrument"`
+	Unit           string `json:"unit"`
+	ReferenceRange string `json:"reference_range"`
+}
+
+type TestCatalogResponse struct {
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	Department     string    `json:"department"`
+	Instrument     string    `json:"instrument"`
+	Unit           string    `json:"unit"`
+	ReferenceRange string    `json:"reference_range"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+
+// ToEntity converts TestCatalogRequest to TestCatalog entity
+func (req *TestCatalogRequest) ToEntity() *entitiy.TestCatalog {
+	return &entitiy.TestCatalog{
+		Code:           req.Code,
+		Name:           req.Name,
+		Department:     req.Department,
+		Instrument:     req.Instrument,
+		Unit:           req.Unit,
+		ReferenceRange: req.ReferenceRange,
+	}
+}
+
+// ToTestCatalogResponse converts TestCatalog entity to TestCatalogResponse
+func ToTestCatalogResponse(test *entitiy.TestCatalog) *TestCatalogResponse {
+	if test == nil {
+		return nil
+	}
+
+	return &TestCatalogResponse{
+		Code:           test.Code,
+		Name:           test.Name,
+		Department:     test.Department,
+		Instrument:     test.Instrument,
+		Unit:           test.Unit,
+		ReferenceRange: test.ReferenceRange,
+		CreatedAt:      test.CreatedAt,
+		UpdatedAt:      test.UpdatedAt,
+	}
+}
+
+// ToTestCatalogResponseList converts slice of TestCatalog entities to slice of TestCatalogResponse
+func ToTestCatalogResponseList(tests []*entitiy.TestCatalog) []*TestCatalogResponse {
+	if tests == nil {
+		return nil
+	}
+
+	responses := make([]*TestCatalogResponse, len(tests))
+	for i, test := range tests {
+		responses[i] = ToTestCatalogResponse(test)
+	}
+
+	return responses
+}
+
+// UpdateEntity updates existing TestCatalog entity with TestCatalogRequest data
+func (req *TestCatalogRequest) UpdateEntity(test *entitiy.TestCatalog) {
+	test.Name = req.Name
+	test.Department = req.Department
+	test.Instrument = req.Instrument
+	test.Unit = req.Unit
+	test.ReferenceRange = req.ReferenceRange
+}
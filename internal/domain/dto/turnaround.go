@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// TAT grouping dimensions accepted in TATRequest.GroupBy.
+const (
+	TATGroupTest       = "test"
+	TATGroupPriority   = "priority"
+	TATGroupDepartment = "department"
+)
+
+type TATRequest struct {
+	Department string
+	Priority   entitiy.Priority
+	From       *time.Time
+	To         *time.Time
+	GroupBy    []string
+}
+
+type TATGroupResponse struct {
+	Group           map[string]string `json:"group"`
+	Tests           int               `json:"tests"`
+	OrderToReceipt  TATStats          `json:"order_to_receipt"`
+	ReceiptToResult TATStats          `json:"receipt_to_result"`
+	ResultToReport  TATStats          `json:"result_to_report"`
+	OrderToReport   TATStats          `json:"order_to_report"`
+}
+
+// TATStats summarises the durations of one lifecycle interval in minutes.
+// Count is the number of test lines that completed the interval.
+type TATStats struct {
+	Count      int     `json:"count"`
+	AvgMinutes float64 `json:"avg_minutes"`
+	P50Minutes float64 `json:"p50_minutes"`
+	P90Minutes float64 `json:"p90_minutes"`
+	P95Minutes float64 `json:"p95_minutes"`
+	MaxMinutes float64 `json:"max_minutes"`
+}
+
+// ToFilter converts TATRequest to TATFilter
+func (req *TATRequest) ToFilter() entitiy.TATFilter {
+	return entitiy.TATFilter{
+		Department: req.Department,
+		Priority:   req.Priority,
+		From:       req.From,
+		To:         req.To,
+	}
+}
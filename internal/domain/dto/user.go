@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type UserRequest struct {
+	Username string         `json:"username"`
+	FullName string         `json:"full_name"`
+	Password string         `json:"password"`
+	Active   *bool          `json:"active"`
+	Roles    []entitiy.Role `json:"roles"`
+}
+
+type UserResponse struct {
+	ID          string         `json:"id"`
+	Username    string         `json:"username"`
+	FullName    string         `json:"full_name"`
+	Active      bool           `json:"active"`
+	Roles       []entitiy.Role `json:"roles"`
+	Permissions []string       `json:"permissions"`
+	LockedUntil *time.Time     `json:"locked_until,omitempty"`
+	LastLoginAt *time.Time     `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
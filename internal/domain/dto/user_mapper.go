@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// ToEntity converts UserRequest to User entity. The password hash is set
+// by the usecase.
+func (req *UserRequest) ToEntity() *entitiy.User {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return &entitiy.User{
+		Username: req.Username,
+		FullName: req.FullName,
+		Active:   active,
+		Roles:    req.Roles,
+	}
+}
+
+// UpdateEntity updates existing User entity with UserRequest data. The
+// username cannot be changed and roles are only replaced when given.
+func (req *UserRequest) UpdateEntity(user *entitiy.User) {
+	if req.FullName != "" {
+		user.FullName = req.FullName
+	}
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+	if req.Roles != nil {
+		user.Roles = req.Roles
+	}
+}
+
+// ToUserResponse converts User entity to UserResponse
+func ToUserResponse(user *entitiy.User) *UserResponse {
+	if user == nil {
+		return nil
+	}
+
+	response := &UserResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		FullName:    user.FullName,
+		Active:      user.Active,
+		Roles:       user.Roles,
+		Permissions: []string{},
+		LastLoginAt: user.LastLoginAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+
+	for _, permission := range auth.PermissionsOf(user.Roles) {
+		response.Permissions = append(response.Permissions, string(permission))
+	}
+
+	if user.Locked(time.Now()) {
+		response.LockedUntil = user.LockedUntil
+	}
+
+	return response
+}
+
+// ToUserResponseList converts slice of User entities to slice of UserResponse
+func ToUserResponseList(users []*entitiy.User) []*UserResponse {
+	if users == nil {
+		return nil
+	}
+
+	responses := make([]*UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = ToUserResponse(user)
+	}
+
+	return responses
+}
@@ -1,9 +1,39 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
 type WorkOrderRequest struct {
-	NoOrder  string         `json:"no_order"`
-	TestCode []string       `json:"test_code"`
-	Patient  PatientRequest `json:"patient"`
-	Analyst  string         `json:"analyst"`
-	Doctor   string         `json:"doctor"`
+	NoOrder   string           `json:"no_order"`
+	TestCode  []string         `json:"test_code"`
+	Patient   PatientRequest   `json:"patient"`
+	AnalystID string           `json:"analyst_id"`
+	DoctorID  string           `json:"doctor_id"`
+	Priority  entitiy.Priority `json:"priority"`
+}
+
+// WorkOrderPatchRequest is a partial update of a work order and its
+// patient. Only the fields present in the request body are changed.
+type WorkOrderPatchRequest struct {
+	TestCode  *[]string            `json:"test_code"`
+	Patient   *PatientPatchRequest `json:"patient"`
+	AnalystID *string              `json:"analyst_id"`
+	DoctorID  *string              `json:"doctor_id"`
+	Priority  *entitiy.Priority    `json:"priority"`
+}
+
+// WorkOrderListRequest holds the filters and page of GET /work-orders.
+type WorkOrderListRequest struct {
+	PatientID string
+	Doctor    string
+	Analyst   string
+	Priority  entitiy.Priority
+	Status    []entitiy.TestStatus
+	TestCode  string
+	From      *time.Time
+	To        *time.Time
+	PageRequest
 }
@@ -1,23 +1,36 @@
 package dto
 
-import "github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
 
 type WorkOrderResponse struct {
-	NoOrder  string           `json:"no_order"`
-	Patient  *PatientResponse `json:"patient,omitempty"`
-	TestCode []string         `json:"test_code"`
-	Analyst  string           `json:"analyst"`
-	Doctor   string           `json:"doctor"`
+	NoOrder   string           `json:"no_order"`
+	Patient   *PatientResponse `json:"patient,omitempty"`
+	TestCode  []string         `json:"test_code"`
+	Analyst   string           `json:"analyst"`
+	AnalystID string           `json:"analyst_id,omitempty"`
+	Doctor    string           `json:"doctor"`
+	DoctorID  string           `json:"doctor_id,omitempty"`
+	Priority  entitiy.Priority `json:"priority"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
 }
 
 // ToEntity converts WorkOrderRequest to WorkOrder entity
 func (req *WorkOrderRequest) ToEntity(patientID string) *entitiy.WorkOrder {
+	priority := req.Priority
+	if priority == "" {
+		priority = entitiy.PriorityRoutine
+	}
+
 	return &entitiy.WorkOrder{
 		NoOrder:   req.NoOrder,
 		PatientID: patientID,
 		TestCode:  req.TestCode,
-		Analyst:   req.Analyst,
-		Doctor:    req.Doctor,
+		Priority:  priority,
 	}
 }
 
@@ -28,11 +41,16 @@ func ToWorkOrderResponse(workOrder *entitiy.WorkOrder, patient *entitiy.Patient)
 	}
 
 	return &WorkOrderResponse{
-		NoOrder:  workOrder.NoOrder,
-		Patient:  ToPatientResponse(patient),
-		TestCode: workOrder.TestCode,
-		Analyst:  workOrder.Analyst,
-		Doctor:   workOrder.Doctor,
+		NoOrder:   workOrder.NoOrder,
+		Patient:   ToPatientResponse(patient),
+		TestCode:  workOrder.TestCode,
+		Analyst:   workOrder.Analyst,
+		AnalystID: workOrder.AnalystID,
+		Doctor:    workOrder.Doctor,
+		DoctorID:  workOrder.DoctorID,
+		Priority:  workOrder.Priority,
+		CreatedAt: workOrder.CreatedAt,
+		UpdatedAt: workOrder.UpdatedAt,
 	}
 }
 
@@ -54,9 +72,61 @@ func ToWorkOrderResponseList(workOrders []*entitiy.WorkOrder, patients map[strin
 	return responses
 }
 
-// UpdateEntity updates existing WorkOrder entity with WorkOrderRequest data
+// UpdateEntity updates existing WorkOrder entity with WorkOrderRequest data.
+// Analyst and doctor are only reassigned when given.
 func (req *WorkOrderRequest) UpdateEntity(workOrder *entitiy.WorkOrder) {
 	workOrder.TestCode = req.TestCode
-	workOrder.Analyst = req.Analyst
-	workOrder.Doctor = req.Doctor
+	if req.AnalystID != "" {
+		workOrder.AnalystID = req.AnalystID
+	}
+	if req.DoctorID != "" {
+		workOrder.DoctorID = req.DoctorID
+	}
+	if req.Priority != "" {
+		workOrder.Priority = req.Priority
+	}
+}
+
+// ApplyTo applies the work order fields present in WorkOrderPatchRequest
+// to a WorkOrder entity. An empty analyst_id or doctor_id unassigns.
+// Patient fields are applied separately.
+func (req *WorkOrderPatchRequest) ApplyTo(workOrder *entitiy.WorkOrder) {
+	if req.TestCode != nil {
+		workOrder.TestCode = *req.TestCode
+	}
+	if req.AnalystID != nil {
+		workOrder.AnalystID = *req.AnalystID
+		if workOrder.AnalystID == "" {
+			workOrder.Analyst = ""
+		}
+	}
+	if req.DoctorID != nil {
+		workOrder.DoctorID = *req.DoctorID
+		if workOrder.DoctorID == "" {
+			workOrder.Doctor = ""
+		}
+	}
+	if req.Priority != nil {
+		workOrder.Priority = *req.Priority
+	}
+}
+
+// ToFilter converts WorkOrderListRequest to WorkOrderFilter
+func (req *WorkOrderListRequest) ToFilter() (entitiy.WorkOrderFilter, error) {
+	page, err := req.ToPage(WorkOrderSortFields)
+	if err != nil {
+		return entitiy.WorkOrderFilter{}, err
+	}
+
+	return entitiy.WorkOrderFilter{
+		PatientID: req.PatientID,
+		Doctor:    req.Doctor,
+		Analyst:   req.Analyst,
+		Priority:  req.Priority,
+		Status:    req.Status,
+		TestCode:  req.TestCode,
+		From:      req.From,
+		To:        req.To,
+		Page:      page,
+	}, nil
 }
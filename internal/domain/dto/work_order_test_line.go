@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type WorkOrderTestResponse struct {
+	TestCode      string             `json:"test_code"`
+	Status        entitiy.TestStatus `json:"status"`
+	ResultValue   string             `json:"result_value,omitempty"`
+	ResultFlag    string             `json:"result_flag,omitempty"`
+	ResultComment string             `json:"result_comment,omitempty"`
+	OrderedAt     time.Time          `json:"ordered_at"`
+	ReceivedAt    *time.Time         `json:"received_at"`
+	ResultedAt    *time.Time         `json:"resulted_at"`
+	ValidatedAt   *time.Time         `json:"validated_at"`
+	ValidatedBy   string             `json:"validated_by,omitempty"`
+	AuthorizedAt  *time.Time         `json:"authorized_at"`
+	AuthorizedBy  string             `json:"authorized_by,omitempty"`
+	AmendedAt     *time.Time         `json:"amended_at,omitempty"`
+	AmendedBy     string             `json:"amended_by,omitempty"`
+}
+
+// TestStepRequest moves test lines of a work order to the next lifecycle
+// step. An empty TestCode applies the step to every eligible test line.
+// The step is signed by the logged in user.
+type TestStepRequest struct {
+	TestCode []string `json:"test_code"`
+}
+
+type ResultRequest struct {
+	Results []ResultItem `json:"results"`
+}
+
+type ResultItem struct {
+	TestCode string `json:"test_code"`
+	Value    string `json:"value"`
+	Flag     string `json:"flag"`
+	Comment  string `json:"comment"`
+}
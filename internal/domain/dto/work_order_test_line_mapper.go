@@ -0,0 +1,41 @@
+package dto
+
+import "github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+
+// ToWorkOrderTestResponse converts WorkOrderTest entity to WorkOrderTestResponse
+func ToWorkOrderTestResponse(test *entitiy.WorkOrderTest) *WorkOrderTestResponse {
+	if test == nil {
+		return nil
+	}
+
+	return &WorkOrderTestResponse{
+		TestCode:      test.TestCode,
+		Status:        test.Status,
+		ResultValue:   test.ResultValue,
+		ResultFlag:    test.ResultFlag,
+		ResultComment: test.ResultComment,
+		OrderedAt:     test.CreatedAt,
+		ReceivedAt:    test.ReceivedAt,
+		ResultedAt:    test.ResultedAt,
+		ValidatedAt:   test.ValidatedAt,
+		ValidatedBy:   test.ValidatedBy,
+		AuthorizedAt:  test.AuthorizedAt,
+		AuthorizedBy:  test.AuthorizedBy,
+		AmendedAt:     test.AmendedAt,
+		AmendedBy:     test.AmendedBy,
+	}
+}
+
+// ToWorkOrderTestResponseList converts slice of WorkOrderTest entities to slice of WorkOrderTestResponse
+func ToWorkOrderTestResponseList(tests []*entitiy.WorkOrderTest) []*WorkOrderTestResponse {
+	if tests == nil {
+		return nil
+	}
+
+	responses := make([]*WorkOrderTestResponse, len(tests))
+	for i, test := range tests {
+		responses[i] = ToWorkOrderTestResponse(test)
+	}
+
+	return responses
+}
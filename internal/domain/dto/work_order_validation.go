@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/validation"
+)
+
+var (
+	testCodeRules = []validation.Rule[[]string]{
+		validation.NotEmpty[string],
+		validation.Each(validation.Required, validation.MaxLength(50)),
+		validation.Unique[string],
+	}
+	priorityRules = []validation.Rule[entitiy.Priority]{
+		validation.OneOf(entitiy.PriorityRoutine, entitiy.PriorityUrgent, entitiy.PrioritySTAT),
+	}
+)
+
+// Validate checks a new work order.
+func (req *WorkOrderRequest) Validate() error {
+	return validation.Validate(
+		validation.Field("no_order", req.NoOrder, validation.Required, validation.MaxLength(50)),
+		validation.Field("doctor_id", req.DoctorID, validation.Required),
+		req.validateDetails(),
+	)
+}
+
+// ValidateUpdate checks a replacement of an existing work order. The work
+// order number is taken from the path and the staff are only reassigned
+// when given, so neither is required.
+func (req *WorkOrderRequest) ValidateUpdate() error {
+	return validation.Validate(req.validateDetails())
+}
+
+func (req *WorkOrderRequest) validateDetails() validation.Result {
+	var result validation.Result
+	result = append(result, validation.Field("test_code", req.TestCode, testCodeRules...)...)
+	result = append(result, validation.Field("priority", req.Priority, validation.IfSet(priorityRules...))...)
+	result = append(result, validation.Nested("patient", req.Patient.Validate())...)
+	return result
+}
+
+// Validate checks the fields present in a partial update.
+func (req *WorkOrderPatchRequest) Validate() error {
+	var patient validation.Result
+	if req.Patient != nil {
+		patient = validation.Nested("patient", req.Patient.Validate())
+	}
+
+	return validation.Validate(
+		validation.Optional("test_code", req.TestCode, testCodeRules...),
+		validation.Optional("priority", req.Priority, priorityRules...),
+		patient,
+	)
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// MaxWorklistItems caps the test lines of one worklist. A worklist that
+// would have more is refused rather than cut short, since a bench must not
+// miss tests; it is narrowed with the date, department, instrument or
+// priority filters.
+const MaxWorklistItems = 2000
+
+type WorklistRequest struct {
+	Department string
+	Instrument string
+	Status     []entitiy.TestStatus
+	Priority   entitiy.Priority
+	From       *time.Time
+	To         *time.Time
+}
+
+type WorklistItemResponse struct {
+	NoOrder    string              `json:"no_order"`
+	TestCode   string              `json:"test_code"`
+	TestName   string              `json:"test_name"`
+	Department string              `json:"department"`
+	Instrument string              `json:"instrument"`
+	Status     entitiy.TestStatus  `json:"status"`
+	Priority   entitiy.Priority    `json:"priority"`
+	Patient    WorklistPatientInfo `json:"patient"`
+	Doctor     string              `json:"doctor"`
+	OrderedAt  time.Time           `json:"ordered_at"`
+}
+
+type WorklistPatientInfo struct {
+	ID        string         `json:"id"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	Birthdate time.Time      `json:"birth_date"`
+	Sex       entitiy.Gender `json:"sex"`
+}
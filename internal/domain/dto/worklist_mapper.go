@@ -0,0 +1,63 @@
+package dto
+
+import "github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+
+// ToFilter converts WorklistRequest to WorklistFilter. Pending tests are
+// returned when no status is requested. One line more than
+// MaxWorklistItems is read, to tell a full worklist from a longer one.
+func (req *WorklistRequest) ToFilter() entitiy.WorklistFilter {
+	status := req.Status
+	if len(status) == 0 {
+		status = []entitiy.TestStatus{entitiy.TestStatusPending, entitiy.TestStatusReceived}
+	}
+
+	return entitiy.WorklistFilter{
+		Department: req.Department,
+		Instrument: req.Instrument,
+		Status:     status,
+		Priority:   req.Priority,
+		From:       req.From,
+		To:         req.To,
+		Limit:      MaxWorklistItems + 1,
+	}
+}
+
+// ToWorklistItemResponse converts WorklistItem entity to WorklistItemResponse
+func ToWorklistItemResponse(item *entitiy.WorklistItem) *WorklistItemResponse {
+	if item == nil {
+		return nil
+	}
+
+	return &WorklistItemResponse{
+		NoOrder:    item.NoOrder,
+		TestCode:   item.TestCode,
+		TestName:   item.TestName,
+		Department: item.Department,
+		Instrument: item.Instrument,
+		Status:     item.Status,
+		Priority:   item.Priority,
+		Patient: WorklistPatientInfo{
+			ID:        item.PatientID,
+			FirstName: item.PatientFirstName,
+			LastName:  item.PatientLastName,
+			Birthdate: item.PatientBirthdate,
+			Sex:       item.PatientSex,
+		},
+		Doctor:    item.Doctor,
+		OrderedAt: item.OrderedAt,
+	}
+}
+
+// ToWorklistResponseList converts slice of WorklistItem entities to slice of WorklistItemResponse
+func ToWorklistResponseList(items []*entitiy.WorklistItem) []*WorklistItemResponse {
+	if items == nil {
+		return nil
+	}
+
+	responses := make([]*WorklistItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = ToWorklistItemResponse(item)
+	}
+
+	return responses
+}
@@ -0,0 +1,25 @@
+package entitiy
+
+import "time"
+
+// APIKey is a long-lived credential for another system, such as the HIS.
+// It grants only its scopes. Only the SHA-256 hash of the key is stored;
+// Prefix is its first characters, kept to recognise it.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	CreatedBy  string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Valid reports whether the key can be used at the given time.
+func (k *APIKey) Valid(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,49 @@
+package entitiy
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditView   AuditAction = "view"
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// Audited entity types.
+const (
+	AuditEntityPatient       = "patient"
+	AuditEntityWorkOrder     = "work_order"
+	AuditEntityWorkOrderTest = "work_order_test"
+	AuditEntityReport        = "report"
+	AuditEntityAPIKey        = "api_key"
+)
+
+// AuditLog is one entry of the append-only audit trail. Changes holds a
+// JSON object of the changed fields with their before and after values.
+type AuditLog struct {
+	ID         int64
+	OccurredAt time.Time
+	UserID     string
+	Username   string
+	Action     AuditAction
+	EntityType string
+	EntityID   string
+	PatientID  string
+	Changes    string
+	ClientIP   string
+	RequestID  string
+}
+
+// AuditFilter selects audit log entries. Empty fields are not filtered on.
+type AuditFilter struct {
+	PatientID  string
+	UserID     string
+	EntityType string
+	EntityID   string
+	Action     AuditAction
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
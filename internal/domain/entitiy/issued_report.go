@@ -0,0 +1,17 @@
+package entitiy
+
+import "time"
+
+// IssuedReport records a patient report handed out to the patient together
+// with the hash of its canonical content. Content is the issued report as
+// shown on verification, encoded as JSON; reports issued before it was
+// kept have none.
+type IssuedReport struct {
+	ID               int64
+	NoOrder          string
+	VerificationCode string
+	ContentHash      string
+	Status           string
+	IssuedAt         time.Time
+	Content          string
+}
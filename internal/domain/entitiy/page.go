@@ -0,0 +1,10 @@
+package entitiy
+
+// Page selects one page of a sorted list. Sort names a sort field the
+// repository knows; Desc reverses it.
+type Page struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+}
@@ -1,6 +1,9 @@
 package entitiy
 
-import "time"
+import (
+	"log/slog"
+	"time"
+)
 
 type Gender string
 
@@ -10,14 +13,30 @@ const (
 )
 
 type Patient struct {
-	ID        string
-	FirstName string
-	LastName  string
-	Birthdate time.Time
-	Sex       Gender
-	Address   string
-	Phone     string
-	Email     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         string
+	FirstName  string
+	LastName   string
+	Birthdate  time.Time
+	Sex        Gender
+	Address    string
+	Phone      string
+	Email      string
+	NationalID string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// LogValue logs a patient by its ID only, keeping its identity out of the
+// logs.
+func (p *Patient) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", p.ID))
+}
+
+// PatientFilter selects patients for a list. Empty fields are not filtered
+// on; From and To bound the registration time.
+type PatientFilter struct {
+	Sex  Gender
+	From *time.Time
+	To   *time.Time
+	Page Page
 }
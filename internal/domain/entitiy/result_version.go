@@ -0,0 +1,18 @@
+package entitiy
+
+import "time"
+
+// ResultVersion is one entry in the history of a test result. Versions are
+// never updated or deleted; every change adds a new one.
+type ResultVersion struct {
+	ID        int64
+	NoOrder   string
+	TestCode  string
+	Version   int
+	Value     string
+	Flag      string
+	Comment   string
+	Reason    string
+	ChangedBy string
+	ChangedAt time.Time
+}
@@ -0,0 +1,35 @@
+package entitiy
+
+// Role is a lab role assigned to a user account. A user can hold several
+// roles; their permissions add up.
+type Role string
+
+const (
+	RoleReceptionist Role = "receptionist"
+	RolePhlebotomist Role = "phlebotomist"
+	RoleAnalyst      Role = "analyst"
+	RoleValidator    Role = "validator"
+	RolePathologist  Role = "pathologist"
+	RoleLabAdmin     Role = "lab_admin"
+	RoleDoctor       Role = "doctor"
+)
+
+// Roles lists every role in display order.
+var Roles = []Role{
+	RoleReceptionist,
+	RolePhlebotomist,
+	RoleAnalyst,
+	RoleValidator,
+	RolePathologist,
+	RoleLabAdmin,
+	RoleDoctor,
+}
+
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
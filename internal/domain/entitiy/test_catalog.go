@@ -0,0 +1,14 @@
+package entitiy
+
+import "time"
+
+type TestCatalog struct {
+	Code           string
+	Name           string
+	Department     string
+	Instrument     string
+	Unit           string
+	ReferenceRange string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
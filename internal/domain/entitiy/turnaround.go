@@ -0,0 +1,25 @@
+package entitiy
+
+import "time"
+
+// TATFilter selects test lines for turnaround-time metrics. From and To
+// bound the work order creation time.
+type TATFilter struct {
+	Department string
+	Priority   Priority
+	From       *time.Time
+	To         *time.Time
+}
+
+// TATSample holds the lifecycle timestamps of one test line. Steps that
+// have not happened yet are nil.
+type TATSample struct {
+	NoOrder      string
+	TestCode     string
+	Department   string
+	Priority     Priority
+	OrderedAt    time.Time
+	ReceivedAt   *time.Time
+	ResultedAt   *time.Time
+	AuthorizedAt *time.Time
+}
@@ -0,0 +1,50 @@
+package entitiy
+
+import "time"
+
+type User struct {
+	ID             string
+	Username       string
+	FullName       string
+	PasswordHash   string
+	Active         bool
+	FailedAttempts int
+	LockedUntil    *time.Time
+	LastLoginAt    *time.Time
+	Roles          []Role
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Locked reports whether the account is locked out at the given time.
+func (u *User) Locked(at time.Time) bool {
+	return u.LockedUntil != nil && at.Before(*u.LockedUntil)
+}
+
+// HasRole reports whether the user holds role.
+func (u *User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Session is a login session identified by its refresh token. Only the
+// SHA-256 hash of the token is stored.
+type Session struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash string
+	ClientIP         string
+	UserAgent        string
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	CreatedAt        time.Time
+}
+
+// Valid reports whether the session can still be used at the given time.
+func (s *Session) Valid(at time.Time) bool {
+	return s.RevokedAt == nil && at.Before(s.ExpiresAt)
+}
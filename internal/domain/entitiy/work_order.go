@@ -1,9 +1,71 @@
 package entitiy
 
+import "time"
+
+type Priority string
+
+const (
+	PriorityRoutine Priority = "routine"
+	PriorityUrgent  Priority = "urgent"
+	PrioritySTAT    Priority = "stat"
+)
+
+type TestStatus string
+
+const (
+	TestStatusPending    TestStatus = "pending"
+	TestStatusReceived   TestStatus = "received"
+	TestStatusResulted   TestStatus = "resulted"
+	TestStatusValidated  TestStatus = "validated"
+	TestStatusAuthorized TestStatus = "authorized"
+)
+
 type WorkOrder struct {
 	NoOrder   string
 	PatientID string
 	TestCode  []string
 	Analyst   string
+	AnalystID string
+	Doctor    string
+	DoctorID  string
+	Priority  Priority
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WorkOrderFilter selects work orders for a list. Empty fields are not
+// filtered on. Doctor and Analyst match the user ID, or the name on orders
+// created before staff were user accounts. From and To bound the creation
+// time. Status and TestCode match work orders with at least one test line
+// having both.
+type WorkOrderFilter struct {
+	PatientID string
 	Doctor    string
+	Analyst   string
+	Priority  Priority
+	Status    []TestStatus
+	TestCode  string
+	From      *time.Time
+	To        *time.Time
+	Page      Page
+}
+
+// WorkOrderTest is a single test line of a work order together with its
+// result and the timestamps of each lifecycle step.
+type WorkOrderTest struct {
+	NoOrder       string
+	TestCode      string
+	Status        TestStatus
+	ResultValue   string
+	ResultFlag    string
+	ResultComment string
+	ReceivedAt    *time.Time
+	ResultedAt    *time.Time
+	ValidatedAt   *time.Time
+	ValidatedBy   string
+	AuthorizedAt  *time.Time
+	AuthorizedBy  string
+	AmendedAt     *time.Time
+	AmendedBy     string
+	CreatedAt     time.Time
 }
@@ -0,0 +1,35 @@
+package entitiy
+
+import "time"
+
+// WorklistFilter selects test lines for a bench worklist. Empty fields are
+// not filtered on; From and To bound the work order creation time. Limit
+// caps the lines returned, when positive.
+type WorklistFilter struct {
+	Department string
+	Instrument string
+	Status     []TestStatus
+	Priority   Priority
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+// WorklistItem is a single test line of a work order joined with its
+// patient and catalog entry.
+type WorklistItem struct {
+	NoOrder          string
+	TestCode         string
+	TestName         string
+	Department       string
+	Instrument       string
+	Status           TestStatus
+	Priority         Priority
+	PatientID        string
+	PatientFirstName string
+	PatientLastName  string
+	PatientBirthdate time.Time
+	PatientSex       Gender
+	Doctor           string
+	OrderedAt        time.Time
+}
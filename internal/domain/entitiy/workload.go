@@ -0,0 +1,16 @@
+package entitiy
+
+// CriticalResultFlags are the result flags of critical values, after HL7
+// table 0078: below the lower panic limit, above the upper panic limit,
+// and critically abnormal. Flags are compared case-insensitively.
+var CriticalResultFlags = []string{"LL", "HH", "AA"}
+
+// WorkloadCount is the number of test lines not yet authorized in one
+// department and status. Critical counts those among them whose result
+// has a critical flag.
+type WorkloadCount struct {
+	Department string
+	Status     TestStatus
+	Count      int
+	Critical   int
+}
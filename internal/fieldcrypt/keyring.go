@@ -0,0 +1,274 @@
+// Package fieldcrypt encrypts individual database columns with envelope
+// encryption and computes blind indexes for exact-match lookups.
+//
+// Every value gets its own random data key. The value is sealed with the
+// data key (AES-256-GCM) and the data key is sealed with a key encryption
+// key from the keyring. Rotating the key encryption key therefore only
+// rewraps the small data key. Stored values look like
+//
+//	enc:v1:<key id>:<wrapped data key>:<ciphertext>
+//
+// Values without the prefix are treated as legacy plaintext, so a table
+// keeps working while its rows are being migrated.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	prefix  = "enc:v1:"
+	keySize = 32
+)
+
+var (
+	// ErrUnknownKey is returned when a value was sealed with a key that is
+	// not in the keyring.
+	ErrUnknownKey = errors.New("encryption key not in keyring")
+	// ErrMalformed is returned for a value with the encryption prefix that
+	// cannot be parsed or authenticated.
+	ErrMalformed = errors.New("malformed encrypted value")
+)
+
+var validKeyID = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+
+// Keyring holds the key encryption keys by ID, the ID of the key used for
+// new values and the key of the blind index.
+type Keyring struct {
+	keys     map[string][]byte
+	active   string
+	indexKey []byte
+}
+
+func NewKeyring(keys map[string][]byte, active string, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption keys configured")
+	}
+
+	for id, key := range keys {
+		if !validKeyID.MatchString(id) {
+			return nil, fmt.Errorf("invalid key id %q: use up to 32 letters, digits or dashes", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %s must be %d bytes, got %d", id, keySize, len(key))
+		}
+	}
+
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", active)
+	}
+
+	if len(indexKey) != keySize {
+		return nil, fmt.Errorf("blind index key must be %d bytes, got %d", keySize, len(indexKey))
+	}
+
+	return &Keyring{
+		keys:     keys,
+		active:   active,
+		indexKey: indexKey,
+	}, nil
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt seals plaintext under the active key. The associated data binds
+// the value to its place (for example table, column and row ID) so that a
+// ciphertext copied to another row does not decrypt. Empty values stay
+// empty.
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrapped, err := seal(k.keys[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return "", err
+	}
+
+	data, err := seal(dataKey, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + k.active + ":" + encode(wrapped) + ":" + encode(data), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Values without the
+// encryption prefix are returned unchanged.
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, wrapped, data, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, data, []byte(aad))
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	return string(plaintext), nil
+}
+
+// Reseal brings a stored value up to date: legacy plaintext is encrypted
+// and a value sealed under an older key has its data key rewrapped under
+// the active key. It reports whether the value changed.
+func (k *Keyring) Reseal(value, aad string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+
+	if !IsEncrypted(value) {
+		sealed, err := k.Encrypt(value, aad)
+		return sealed, err == nil, err
+	}
+
+	keyID, wrapped, data, err := parse(value)
+	if err != nil {
+		return "", false, err
+	}
+
+	if keyID == k.active {
+		return value, false, nil
+	}
+
+	dataKey, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Make sure the value itself is intact before rewrapping it.
+	if _, err := open(dataKey, data, []byte(aad)); err != nil {
+		return "", false, ErrMalformed
+	}
+
+	rewrapped, err := seal(k.keys[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return "", false, err
+	}
+
+	return prefix + k.active + ":" + encode(rewrapped) + ":" + encode(data), true, nil
+}
+
+// BlindIndex returns a keyed hash of a normalized value for exact-match
+// lookups without storing the plaintext. kind separates the index spaces
+// of different fields. Empty values have no index.
+func (k *Keyring) BlindIndex(kind, normalized string) string {
+	if normalized == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalized))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted reports whether value carries the encryption prefix.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	dataKey, err := open(kek, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return dataKey, nil
+}
+
+func parse(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, ErrMalformed
+	}
+
+	wrapped, err := decode(parts[1])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+
+	data, err := decode(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+
+	return parts[0], wrapped, data, nil
+}
+
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func encode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,232 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKeys are the key encryption keys the test keyrings are built from,
+// so that keyrings sharing an ID share the key.
+var testKeys = map[string][]byte{
+	"k1": randomKey(),
+	"k2": randomKey(),
+}
+
+var testIndexKey = randomKey()
+
+func randomKey() []byte {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// newTestKeyring returns a keyring of the test keys ids, with active as
+// the key for new values.
+func newTestKeyring(t *testing.T, active string, ids ...string) *Keyring {
+	t.Helper()
+
+	keys := make(map[string][]byte)
+	for _, id := range ids {
+		keys[id] = testKeys[id]
+	}
+
+	keyring, err := NewKeyring(keys, active, testIndexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	for _, plaintext := range []string{"081234567890", "budi@example.com", "Jl. Merdeka No. 1\nBandung"} {
+		sealed, err := keyring.Encrypt(plaintext, "patients.phone:p-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(sealed) || strings.Contains(sealed, plaintext) {
+			t.Fatalf("Encrypt(%q) = %q, want a sealed value", plaintext, sealed)
+		}
+
+		opened, err := keyring.Decrypt(sealed, "patients.phone:p-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opened != plaintext {
+			t.Errorf("Decrypt = %q, want %q", opened, plaintext)
+		}
+	}
+
+	sealed, err := keyring.Encrypt("", "patients.phone:p-1")
+	if err != nil || sealed != "" {
+		t.Errorf("Encrypt of an empty value = %q, %v, want it left empty", sealed, err)
+	}
+}
+
+func TestDecryptChecksAssociatedData(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	sealed, err := keyring.Encrypt("081234567890", "patients.phone:p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		aad  string
+	}{
+		{"other row", "patients.phone:p-2"},
+		{"other column", "patients.email:p-1"},
+		{"no associated data", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.Decrypt(sealed, tt.aad); !errors.Is(err, ErrMalformed) {
+				t.Errorf("got %v, want %v", err, ErrMalformed)
+			}
+		})
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	sealed, err := keyring.Encrypt("081234567890", "patients.phone:p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip the first character of the sealed data, which is part of the
+	// nonce.
+	split := strings.LastIndex(sealed, ":") + 1
+	flipped := "A"
+	if sealed[split] == 'A' {
+		flipped = "B"
+	}
+	tampered := sealed[:split] + flipped + sealed[split+1:]
+
+	tests := []struct {
+		name  string
+		value string
+		err   error
+	}{
+		{"data", tampered, ErrMalformed},
+		{"truncated", sealed[:len(sealed)-4], ErrMalformed},
+		{"missing part", prefix + "k1:" + sealed[split:], ErrMalformed},
+		{"unknown key", strings.Replace(sealed, prefix+"k1:", prefix+"k9:", 1), ErrUnknownKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.Decrypt(tt.value, "patients.phone:p-1"); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReseal(t *testing.T) {
+	old := newTestKeyring(t, "k1", "k1")
+	rotated := newTestKeyring(t, "k2", "k1", "k2")
+	retired := newTestKeyring(t, "k2", "k2")
+
+	sealed, err := old.Encrypt("081234567890", "patients.phone:p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The old key stays readable until the value is resealed.
+	if opened, err := rotated.Decrypt(sealed, "patients.phone:p-1"); err != nil || opened != "081234567890" {
+		t.Fatalf("Decrypt under the rotated keyring = %q, %v", opened, err)
+	}
+	if _, err := retired.Decrypt(sealed, "patients.phone:p-1"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Decrypt without the old key: got %v, want %v", err, ErrUnknownKey)
+	}
+
+	resealed, changed, err := rotated.Reseal(sealed, "patients.phone:p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || !strings.HasPrefix(resealed, prefix+"k2:") {
+		t.Fatalf("Reseal = %q, %v, want the value sealed under k2", resealed, changed)
+	}
+	if opened, err := retired.Decrypt(resealed, "patients.phone:p-1"); err != nil || opened != "081234567890" {
+		t.Errorf("Decrypt of the resealed value = %q, %v", opened, err)
+	}
+
+	if again, changed, err := rotated.Reseal(resealed, "patients.phone:p-1"); err != nil || changed || again != resealed {
+		t.Errorf("Reseal of an up to date value = %q, %v, %v, want it unchanged", again, changed, err)
+	}
+
+	if _, _, err := rotated.Reseal(sealed, "patients.phone:p-2"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Reseal under other associated data: got %v, want %v", err, ErrMalformed)
+	}
+}
+
+func TestLegacyPlaintext(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	opened, err := keyring.Decrypt("081234567890", "patients.phone:p-1")
+	if err != nil || opened != "081234567890" {
+		t.Fatalf("Decrypt of plaintext = %q, %v, want it unchanged", opened, err)
+	}
+
+	sealed, changed, err := keyring.Reseal("081234567890", "patients.phone:p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || !IsEncrypted(sealed) {
+		t.Fatalf("Reseal of plaintext = %q, %v, want it encrypted", sealed, changed)
+	}
+	if opened, err := keyring.Decrypt(sealed, "patients.phone:p-1"); err != nil || opened != "081234567890" {
+		t.Errorf("Decrypt of the resealed plaintext = %q, %v", opened, err)
+	}
+
+	if _, changed, err := keyring.Reseal("", "patients.phone:p-1"); err != nil || changed {
+		t.Errorf("Reseal of an empty value = %v, %v, want it unchanged", changed, err)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	tests := []struct {
+		name      string
+		kind      string
+		normalize func(string) string
+		same      []string
+		different string
+	}{
+		{"phone", "phone", NormalizePhone, []string{"081234567890", "+62 812-3456-7890", "62 812 3456 7890", "(0812) 3456 7890"}, "081234567891"},
+		{"email", "email", NormalizeEmail, []string{"budi@example.com", " Budi@Example.COM "}, "budi@example.org"},
+		{"national id", "national_id", NormalizeNationalID, []string{"3201010101010001", "3201-0101-0101-0001", "3201 0101 0101 0001"}, "3201010101010002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := keyring.BlindIndex(tt.kind, tt.normalize(tt.same[0]))
+			for _, value := range tt.same[1:] {
+				if got := keyring.BlindIndex(tt.kind, tt.normalize(value)); got != want {
+					t.Errorf("index of %q differs from the index of %q", value, tt.same[0])
+				}
+			}
+
+			if keyring.BlindIndex(tt.kind, tt.normalize(tt.different)) == want {
+				t.Errorf("index of %q equals the index of %q", tt.different, tt.same[0])
+			}
+			if keyring.BlindIndex("other", tt.normalize(tt.same[0])) == want {
+				t.Errorf("index of %q is the same under another kind", tt.same[0])
+			}
+		})
+	}
+
+	if got := keyring.BlindIndex("phone", NormalizePhone("-")); got != "" {
+		t.Errorf("index of an empty value = %q, want none", got)
+	}
+}
@@ -0,0 +1,98 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// keyFile is the JSON layout of a key file:
+//
+//	{"active_key": "2024-01", "keys": {"2024-01": "<base64>"}, "index_key": "<base64>"}
+type keyFile struct {
+	ActiveKey string            `json:"active_key"`
+	Keys      map[string]string `json:"keys"`
+	IndexKey  string            `json:"index_key"`
+}
+
+// LoadKeyFile reads a keyring from a JSON key file.
+func LoadKeyFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(file.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key is not valid base64: %w", err)
+	}
+
+	return NewKeyring(keys, defaultActive(file.ActiveKey, keys), indexKey)
+}
+
+// ParseKeyring builds a keyring from the environment style
+// "id:base64,id:base64" key list, the active key ID and the base64 index
+// key. With a single key the active key ID may be empty.
+func ParseKeyring(keyList, active, indexKey string) (*Keyring, error) {
+	keys := make(map[string][]byte)
+
+	for _, entry := range strings.Split(keyList, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("key entry %q must be id:base64", entry)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	index, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key is not valid base64: %w", err)
+	}
+
+	return NewKeyring(keys, defaultActive(active, keys), index)
+}
+
+// GenerateKey returns a new random key, base64 encoded.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func defaultActive(active string, keys map[string][]byte) string {
+	if active == "" && len(keys) == 1 {
+		for id := range keys {
+			return id
+		}
+	}
+	return active
+}
@@ -0,0 +1,41 @@
+package fieldcrypt
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeEmail lower-cases and trims an email address.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone keeps the digits of a phone number and writes Indonesian
+// numbers in the national form, so "+62 812-3456" and "08123456" match.
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+
+	normalized := digits.String()
+	if strings.HasPrefix(normalized, "62") {
+		normalized = "0" + strings.TrimPrefix(normalized, "62")
+	}
+
+	return normalized
+}
+
+// NormalizeNationalID removes separators and upper-cases an identity
+// number.
+func NormalizeNationalID(id string) string {
+	var b strings.Builder
+	for _, r := range id {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
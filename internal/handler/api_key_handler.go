@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+	"github.com/BioSystems-Indonesia/lis/internal/validation"
+)
+
+type APIKeyHandler struct {
+	apiKeyUC usecase.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(apiKeyUC usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUC: apiKeyUC,
+	}
+}
+
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.APIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	if err := validation.Validate(
+		validation.Field("name", req.Name, validation.Required),
+	); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	key, err := h.apiKeyUC.Create(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyUC.GetAll(r.Context())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	key, err := h.apiKeyUC.Revoke(r.Context(), id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	key, err := h.apiKeyUC.Rotate(r.Context(), id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
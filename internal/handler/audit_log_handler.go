@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+)
+
+type AuditLogHandler struct {
+	auditLogUC usecase.AuditLogUsecase
+}
+
+func NewAuditLogHandler(auditLogUC usecase.AuditLogUsecase) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogUC: auditLogUC,
+	}
+}
+
+func (h *AuditLogHandler) Find(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	req := dto.AuditLogRequest{
+		PatientID:  query.Get("patient_id"),
+		UserID:     query.Get("user_id"),
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+		Action:     entitiy.AuditAction(query.Get("action")),
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			respondError(w, r, apperror.Field("from", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.From = &t
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			respondError(w, r, apperror.Field("to", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.To = &t
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			respondError(w, r, apperror.Field("limit", "must be a positive number"))
+			return
+		}
+		req.Limit = n
+	}
+
+	entries, err := h.auditLogUC.Find(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, entries)
+}
+
+func (h *AuditLogHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
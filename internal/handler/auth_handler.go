@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/requestmeta"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+	"github.com/BioSystems-Indonesia/lis/internal/validation"
+)
+
+type AuthHandler struct {
+	authUC usecase.AuthUsecase
+	userUC usecase.UserUsecase
+}
+
+func NewAuthHandler(authUC usecase.AuthUsecase, userUC usecase.UserUsecase) *AuthHandler {
+	return &AuthHandler{
+		authUC: authUC,
+		userUC: userUC,
+	}
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	if err := validation.Validate(
+		validation.Field("username", req.Username, validation.Required),
+		validation.Field("password", req.Password, validation.Required),
+	); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	tokens, err := h.authUC.Login(r.Context(), &req, clientInfo(r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	if req.RefreshToken == "" {
+		respondError(w, r, apperror.Field("refresh_token", "is required"))
+		return
+	}
+
+	tokens, err := h.authUC.Refresh(r.Context(), req.RefreshToken, clientInfo(r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal := auth.PrincipalFrom(r.Context())
+	if principal == nil {
+		respondError(w, r, auth.ErrInvalidToken)
+		return
+	}
+
+	if principal.APIKeyID != "" {
+		respondError(w, r, apperror.Invalid("API keys have no session; revoke the key instead"))
+		return
+	}
+
+	if err := h.authUC.Logout(r.Context(), principal); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, map[string]string{
+		"message": "Logged out successfully",
+	})
+}
+
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	principal := auth.PrincipalFrom(r.Context())
+	if principal == nil {
+		respondError(w, r, auth.ErrInvalidToken)
+		return
+	}
+
+	if principal.APIKeyID != "" {
+		respondError(w, r, apperror.Invalid("not available for API keys"))
+		return
+	}
+
+	user, err := h.userUC.GetByID(r.Context(), principal.UserID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, user)
+}
+
+func (h *AuthHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// clientInfo returns the client address recorded by the request metadata
+// middleware and the user agent of the request.
+func clientInfo(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{
+		IP:        requestmeta.ClientIP(r.Context()),
+		UserAgent: r.UserAgent(),
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/requestmeta"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+// errorKinds maps error kinds to a status code and the machine-readable
+// code sent in the error body. The first kind in the error chain wins.
+var errorKinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{auth.ErrUnauthenticated, http.StatusUnauthorized, "unauthenticated"},
+	{auth.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{auth.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{auth.ErrAccountLocked, http.StatusLocked, "account_locked"},
+	{auth.ErrAccountDisabled, http.StatusForbidden, "account_disabled"},
+	{auth.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{apperror.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{apperror.ErrNotFound, http.StatusNotFound, "not_found"},
+	{apperror.ErrConflict, http.StatusConflict, "conflict"},
+	{apperror.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{apperror.ErrValidation, http.StatusBadRequest, "validation_failed"},
+}
+
+// ErrorResponse returns the status code and body for err. Errors of no
+// known kind are internal errors; their details are logged with the
+// request ID of ctx, which is returned instead, and recorded on the span
+// of the request.
+func ErrorResponse(ctx context.Context, err error) (int, dto.ResponseError) {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.kind) {
+			return k.status, dto.ResponseError{
+				Code:    k.status,
+				Status:  "error",
+				Error:   k.code,
+				Message: apperror.Message(err),
+				Details: apperror.Fields(err),
+			}
+		}
+	}
+
+	slog.ErrorContext(ctx, "internal error", "error", err)
+	tracing.RecordError(ctx, err)
+
+	return InternalErrorResponse(ctx)
+}
+
+// InternalErrorResponse returns the status code and body of an internal
+// error, for callers that have logged its details themselves.
+func InternalErrorResponse(ctx context.Context) (int, dto.ResponseError) {
+	return http.StatusInternalServerError, dto.ResponseError{
+		Code:      http.StatusInternalServerError,
+		Status:    "error",
+		Error:     "internal_error",
+		Message:   "Internal server error",
+		RequestID: requestmeta.RequestID(ctx),
+	}
+}
+
+// respondError writes the error response for err to the request r.
+func respondError(w http.ResponseWriter, r *http.Request, err error) {
+	status, response := ErrorResponse(r.Context(), err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(response)
+}
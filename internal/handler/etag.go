@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+// setETag sets the ETag header for a representation. On a GET whose
+// If-None-Match already names that tag it writes 304 Not Modified and
+// returns true; the caller then writes nothing else.
+func setETag(w http.ResponseWriter, r *http.Request, representation interface{}) bool {
+	etag := dto.ETag(representation)
+	w.Header().Set("ETag", etag)
+
+	if r.Method == http.MethodGet {
+		if inm := r.Header.Get("If-None-Match"); inm != "" && dto.MatchesETag(inm, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
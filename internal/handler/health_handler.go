@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/health"
+)
+
+// HealthHandler serves the probes of orchestrators and monitoring.
+// Liveness only says the process is serving; readiness runs the
+// dependency checks, and also fails while ready reports false, before
+// startup completes and once shutdown has begun. The probes are public and
+// answer only statuses; the details of the checks, such as pool figures
+// and file system paths, are served to authenticated monitors by Details.
+type HealthHandler struct {
+	checker *health.Checker
+	ready   func() bool
+}
+
+func NewHealthHandler(checker *health.Checker, ready func() bool) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+		ready:   ready,
+	}
+}
+
+// LiveResponse is the body of the liveness probe.
+type LiveResponse struct {
+	Status health.Status `json:"status"`
+}
+
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, LiveResponse{Status: health.StatusOK})
+}
+
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.report(r)
+	writeHealth(w, readinessStatus(report), report.Summary())
+}
+
+// Details is the readiness report with the details and errors of every
+// check.
+func (h *HealthHandler) Details(w http.ResponseWriter, r *http.Request) {
+	report := h.report(r)
+	writeHealth(w, readinessStatus(report), report)
+}
+
+// Health is the plain-text probe kept for existing monitors: OK when the
+// server is ready, the failing status otherwise.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	report := h.report(r)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.Ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(report.Status))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// report runs the checks, adding the state of the server itself.
+func (h *HealthHandler) report(r *http.Request) health.Readiness {
+	report := h.checker.Run(r.Context())
+
+	if h.ready() {
+		report.Checks["server"] = health.OK(nil)
+	} else {
+		report.Checks["server"] = health.CheckResult{Status: health.StatusFail, Error: "not accepting traffic: starting or shutting down"}
+		report.Status = health.StatusFail
+	}
+
+	return report
+}
+
+func readinessStatus(report health.Readiness) int {
+	if !report.Ready() {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+func writeHealth(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/BioSystems-Indonesia/lis/internal/metrics"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+)
+
+// MetricsHandler serves the Prometheus metrics. The lab workload gauges
+// are read from the database on each scrape; the other metrics are kept
+// up to date as requests are served.
+type MetricsHandler struct {
+	workloadUC usecase.WorkloadUsecase
+	metrics    http.Handler
+	// mu keeps concurrent scrapes from seeing the workload gauges while
+	// another scrape replaces them.
+	mu sync.Mutex
+}
+
+func NewMetricsHandler(workloadUC usecase.WorkloadUsecase) *MetricsHandler {
+	return &MetricsHandler{
+		workloadUC: workloadUC,
+		metrics:    metrics.Handler(),
+	}
+}
+
+// Serve writes the metrics. When the workload cannot be read, the
+// workload gauges are left out and the other metrics are still served,
+// so that an outage of the database does not blind the dashboards.
+func (h *MetricsHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	workload, err := h.workloadUC.GetWorkload(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read workload for metrics", "error", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	metrics.SetWorkload(workload)
+	h.metrics.ServeHTTP(w, r)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+// parsePageRequest reads the page, page_size and sort query parameters and
+// checks them against the sort fields of the endpoint.
+func parsePageRequest(query url.Values, sortFields []string) (dto.PageRequest, error) {
+	req := dto.PageRequest{
+		Sort: query.Get("sort"),
+	}
+
+	if page := query.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return req, apperror.Field("page", "must be a number of 1 or greater")
+		}
+		req.Page = n
+	}
+
+	if pageSize := query.Get("page_size"); pageSize != "" {
+		n, err := strconv.Atoi(pageSize)
+		if err != nil || n < 1 {
+			return req, apperror.Field("page_size", "must be a number of 1 or greater")
+		}
+		req.PageSize = n
+	}
+
+	if _, err := req.ToPage(sortFields); err != nil {
+		return req, err
+	}
+
+	return req, nil
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	sortFields := []string{"created_at", "last_name"}
+
+	tests := []struct {
+		name  string
+		query string
+		want  dto.PageRequest
+		err   error
+	}{
+		{"left out", "", dto.PageRequest{}, nil},
+		{"first page", "page=1&page_size=20", dto.PageRequest{Page: 1, PageSize: 20}, nil},
+		{"last page", "page=" + strconv.Itoa(dto.MaxPage), dto.PageRequest{Page: dto.MaxPage}, nil},
+		{"page zero", "page=0", dto.PageRequest{}, apperror.ErrValidation},
+		{"page size zero", "page_size=0", dto.PageRequest{}, apperror.ErrValidation},
+		{"page past the last", "page=" + strconv.Itoa(dto.MaxPage+1), dto.PageRequest{}, apperror.ErrValidation},
+		{"page not a number", "page=two", dto.PageRequest{}, apperror.ErrValidation},
+		{"page size too large", "page_size=" + strconv.Itoa(dto.MaxPageSize+1), dto.PageRequest{}, apperror.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := parsePageRequest(query, sortFields)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if req != tt.want {
+				t.Errorf("request = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
 	"github.com/BioSystems-Indonesia/lis/internal/usecase"
 )
 
@@ -22,13 +24,13 @@ func (h *PatientHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req dto.PatientRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
 	patient, err := h.patientUC.Create(r.Context(), &req)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
@@ -36,15 +38,13 @@ func (h *PatientHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PatientHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.respondError(w, http.StatusBadRequest, "id parameter is required")
+	patient, err := h.patientUC.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	patient, err := h.patientUC.GetByID(r.Context(), id)
-	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+	if setETag(w, r, patient) {
 		return
 	}
 
@@ -52,38 +52,46 @@ func (h *PatientHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PatientHandler) Update(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.respondError(w, http.StatusBadRequest, "id parameter is required")
-		return
-	}
-
 	var req dto.PatientRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
-	patient, err := h.patientUC.Update(r.Context(), id, &req)
+	patient, err := h.patientUC.Update(r.Context(), r.PathValue("id"), &req, r.Header.Get("If-Match"))
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
+	setETag(w, r, patient)
 	h.respondSuccess(w, http.StatusOK, patient)
 }
 
-func (h *PatientHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.respondError(w, http.StatusBadRequest, "id parameter is required")
+// Patch applies a partial update (JSON merge patch of the patient fields).
+func (h *PatientHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	var req dto.PatientPatchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
-	err := h.patientUC.Delete(r.Context(), id)
+	patient, err := h.patientUC.Patch(r.Context(), r.PathValue("id"), &req, r.Header.Get("If-Match"))
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
+		return
+	}
+
+	setETag(w, r, patient)
+	h.respondSuccess(w, http.StatusOK, patient)
+}
+
+func (h *PatientHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	err := h.patientUC.Delete(r.Context(), r.PathValue("id"), r.Header.Get("If-Match"))
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -92,30 +100,75 @@ func (h *PatientHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetAll returns one page of patients, optionally filtered by sex and
+// registration date.
 func (h *PatientHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	patients, err := h.patientUC.GetAll(r.Context())
+	query := r.URL.Query()
+
+	page, err := parsePageRequest(query, dto.PatientSortFields)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, patients)
+	req := dto.PatientListRequest{
+		Sex:         entitiy.Gender(query.Get("sex")),
+		PageRequest: page,
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			respondError(w, r, apperror.Field("from", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.From = &t
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			respondError(w, r, apperror.Field("to", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.To = &t
+	}
+
+	patients, meta, err := h.patientUC.GetAll(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondPage(w, patients, meta)
 }
 
+// Search returns one page of the patients matching the search text q.
 func (h *PatientHandler) Search(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		h.respondError(w, http.StatusBadRequest, "q parameter is required")
+	query := r.URL.Query()
+
+	text := query.Get("q")
+	if text == "" {
+		respondError(w, r, apperror.Field("q", "is required"))
 		return
 	}
 
-	patients, err := h.patientUC.Search(r.Context(), query)
+	page, err := parsePageRequest(query, dto.PatientSearchSortFields)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, patients)
+	patients, meta, err := h.patientUC.Search(r.Context(), &dto.PatientSearchRequest{
+		Query:       text,
+		PageRequest: page,
+	})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondPage(w, patients, meta)
 }
 
 func (h *PatientHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
@@ -131,14 +184,15 @@ func (h *PatientHandler) respondSuccess(w http.ResponseWriter, code int, data in
 	json.NewEncoder(w).Encode(response)
 }
 
-func (h *PatientHandler) respondError(w http.ResponseWriter, code int, message string) {
+func (h *PatientHandler) respondPage(w http.ResponseWriter, data interface{}, meta *dto.PageMeta) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
+	w.WriteHeader(http.StatusOK)
 
-	response := dto.ResponseError{
-		Code:    code,
-		Status:  "error",
-		Message: message,
+	response := dto.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   data,
+		Meta:   meta,
 	}
 
 	json.NewEncoder(w).Encode(response)
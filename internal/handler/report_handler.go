@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/report"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+)
+
+type ReportHandler struct {
+	reportUC   usecase.ReportUsecase
+	letterhead report.Letterhead
+	verifyURL  string
+}
+
+func NewReportHandler(reportUC usecase.ReportUsecase, letterhead report.Letterhead, verifyURL string) *ReportHandler {
+	return &ReportHandler{
+		reportUC:   reportUC,
+		letterhead: letterhead,
+		verifyURL:  verifyURL,
+	}
+}
+
+// GetLabReport issues the patient report of a work order and returns it as
+// a PDF. With format=json the current report content is returned without
+// issuing it.
+func (h *ReportHandler) GetLabReport(w http.ResponseWriter, r *http.Request) {
+	noOrder := r.PathValue("no")
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "pdf":
+		issued, err := h.reportUC.Issue(r.Context(), noOrder)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		verification := report.Verification{
+			Code: issued.VerificationCode,
+			URL:  h.verificationLink(issued.VerificationCode),
+		}
+
+		var buf bytes.Buffer
+		if err := report.WriteLabReportPDF(&buf, h.letterhead, issued.Report, verification); err != nil {
+			respondError(w, r, err)
+			return
+		}
+		h.respondFile(w, "application/pdf", fmt.Sprintf("report-%s.pdf", noOrder), buf.Bytes())
+	case "json":
+		labReport, err := h.reportUC.GetLabReport(r.Context(), noOrder)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+		h.respondSuccess(w, http.StatusOK, labReport)
+	default:
+		respondError(w, r, apperror.Invalid("unsupported format %q", format))
+	}
+}
+
+// Verify is the public endpoint used by other clinics to check a printed
+// report by its verification code.
+func (h *ReportHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		respondError(w, r, apperror.Field("code", "is required"))
+		return
+	}
+
+	verification, err := h.reportUC.Verify(r.Context(), code)
+	if errors.Is(err, apperror.ErrNotFound) {
+		respondError(w, r, apperror.NotFound("No report was issued with this verification code"))
+		return
+	}
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, verification)
+}
+
+func (h *ReportHandler) verificationLink(code string) string {
+	if h.verifyURL == "" {
+		return ""
+	}
+	return h.verifyURL + "?code=" + url.QueryEscape(code)
+}
+
+func (h *ReportHandler) respondFile(w http.ResponseWriter, contentType, filename string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (h *ReportHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
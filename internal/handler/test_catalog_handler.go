@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+	"github.com/BioSystems-Indonesia/lis/internal/validation"
+)
+
+type TestCatalogHandler struct {
+	testCatalogUC usecase.TestCatalogUsecase
+}
+
+func NewTestCatalogHandler(testCatalogUC usecase.TestCatalogUsecase) *TestCatalogHandler {
+	return &TestCatalogHandler{
+		testCatalogUC: testCatalogUC,
+	}
+}
+
+func (h *TestCatalogHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.TestCatalogRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	if err := validation.Validate(
+		validation.Field("code", req.Code, validation.Required),
+		validation.Field("name", req.Name, validation.Required),
+		validation.Field("department", req.Department, validation.Required),
+	); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	test, err := h.testCatalogUC.Create(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusCreated, test)
+}
+
+func (h *TestCatalogHandler) GetByCode(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	test, err := h.testCatalogUC.GetByCode(r.Context(), code)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, test)
+}
+
+func (h *TestCatalogHandler) Update(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	var req dto.TestCatalogRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	test, err := h.testCatalogUC.Update(r.Context(), code, &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, test)
+}
+
+func (h *TestCatalogHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	if err := h.testCatalogUC.Delete(r.Context(), code); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, map[string]string{
+		"message": "Test catalog entry deleted successfully",
+	})
+}
+
+func (h *TestCatalogHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	tests, err := h.testCatalogUC.GetAll(r.Context())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, tests)
+}
+
+func (h *TestCatalogHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+)
+
+type TurnaroundHandler struct {
+	turnaroundUC usecase.TurnaroundUsecase
+}
+
+func NewTurnaroundHandler(turnaroundUC usecase.TurnaroundUsecase) *TurnaroundHandler {
+	return &TurnaroundHandler{
+		turnaroundUC: turnaroundUC,
+	}
+}
+
+func (h *TurnaroundHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	req := dto.TATRequest{
+		Department: query.Get("department"),
+		Priority:   entitiy.Priority(query.Get("priority")),
+	}
+
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		for _, dimension := range strings.Split(groupBy, ",") {
+			dimension = strings.TrimSpace(dimension)
+			switch dimension {
+			case dto.TATGroupTest, dto.TATGroupPriority, dto.TATGroupDepartment:
+				req.GroupBy = append(req.GroupBy, dimension)
+			default:
+				respondError(w, r, apperror.Invalid("group_by accepts test, priority and department"))
+				return
+			}
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			respondError(w, r, apperror.Field("from", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.From = &t
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			respondError(w, r, apperror.Field("to", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.To = &t
+	}
+
+	metrics, err := h.turnaroundUC.GetMetrics(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, metrics)
+}
+
+func (h *TurnaroundHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
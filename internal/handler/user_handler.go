@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+	"github.com/BioSystems-Indonesia/lis/internal/validation"
+)
+
+type UserHandler struct {
+	userUC usecase.UserUsecase
+}
+
+func NewUserHandler(userUC usecase.UserUsecase) *UserHandler {
+	return &UserHandler{
+		userUC: userUC,
+	}
+}
+
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.UserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	if err := validation.Validate(
+		validation.Field("username", req.Username, validation.Required),
+		validation.Field("full_name", req.FullName, validation.Required),
+		validation.Field("password", req.Password, validation.Required),
+	); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	user, err := h.userUC.Create(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusCreated, user)
+}
+
+func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := h.userUC.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req dto.UserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	user, err := h.userUC.Update(r.Context(), id, &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := h.userUC.Unlock(r.Context(), id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	users, err := h.userUC.GetAll(r.Context())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, users)
+}
+
+func (h *UserHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
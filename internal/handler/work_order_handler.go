@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
 	"github.com/BioSystems-Indonesia/lis/internal/usecase"
 )
 
@@ -19,20 +24,15 @@ func NewWorkOrderHandler(workOrderUC usecase.WorkOrderUsecase) *WorkOrderHandler
 }
 
 func (h *WorkOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
 	var req dto.WorkOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
 	workOrder, err := h.workOrderUC.Create(r.Context(), &req)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
@@ -40,20 +40,15 @@ func (h *WorkOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WorkOrderHandler) GetByNoOrder(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
+	noOrder := r.PathValue("no")
 
-	noOrder := r.URL.Query().Get("no_order")
-	if noOrder == "" {
-		h.respondError(w, http.StatusBadRequest, "no_order parameter is required")
+	workOrder, err := h.workOrderUC.GetByNoOrder(r.Context(), noOrder)
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	workOrder, err := h.workOrderUC.GetByNoOrder(r.Context(), noOrder)
-	if err != nil {
-		h.respondError(w, http.StatusNotFound, err.Error())
+	if setETag(w, r, workOrder) {
 		return
 	}
 
@@ -61,46 +56,48 @@ func (h *WorkOrderHandler) GetByNoOrder(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *WorkOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	noOrder := r.URL.Query().Get("no_order")
-	if noOrder == "" {
-		h.respondError(w, http.StatusBadRequest, "no_order parameter is required")
-		return
-	}
+	noOrder := r.PathValue("no")
 
 	var req dto.WorkOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
-	workOrder, err := h.workOrderUC.Update(r.Context(), noOrder, &req)
+	workOrder, err := h.workOrderUC.Update(r.Context(), noOrder, &req, r.Header.Get("If-Match"))
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
+	setETag(w, r, workOrder)
 	h.respondSuccess(w, http.StatusOK, workOrder)
 }
 
-func (h *WorkOrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+// Patch applies a partial update (JSON merge patch of the work order and
+// patient fields).
+func (h *WorkOrderHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	var req dto.WorkOrderPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
-	noOrder := r.URL.Query().Get("no_order")
-	if noOrder == "" {
-		h.respondError(w, http.StatusBadRequest, "no_order parameter is required")
+	workOrder, err := h.workOrderUC.Patch(r.Context(), r.PathValue("no"), &req, r.Header.Get("If-Match"))
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	if err := h.workOrderUC.Delete(r.Context(), noOrder); err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+	setETag(w, r, workOrder)
+	h.respondSuccess(w, http.StatusOK, workOrder)
+}
+
+func (h *WorkOrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	noOrder := r.PathValue("no")
+
+	if err := h.workOrderUC.Delete(r.Context(), noOrder, r.Header.Get("If-Match")); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -109,61 +106,159 @@ func (h *WorkOrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetAll returns one page of work orders. Filters combine: patient_id,
+// doctor, analyst, priority, status, test_code and a creation date range.
 func (h *WorkOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	h.list(w, r, r.URL.Query().Get("patient_id"))
+}
+
+// GetByPatient returns one page of the work orders of the patient in the
+// path, with the same filters as GetAll.
+func (h *WorkOrderHandler) GetByPatient(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, r.PathValue("id"))
+}
+
+func (h *WorkOrderHandler) list(w http.ResponseWriter, r *http.Request, patientID string) {
+	query := r.URL.Query()
+
+	page, err := parsePageRequest(query, dto.WorkOrderSortFields)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	req := dto.WorkOrderListRequest{
+		PatientID:   patientID,
+		Doctor:      query.Get("doctor"),
+		Analyst:     query.Get("analyst"),
+		Priority:    entitiy.Priority(query.Get("priority")),
+		TestCode:    query.Get("test_code"),
+		PageRequest: page,
+	}
+
+	if status := query.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			req.Status = append(req.Status, entitiy.TestStatus(strings.TrimSpace(s)))
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			respondError(w, r, apperror.Field("from", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.From = &t
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			respondError(w, r, apperror.Field("to", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.To = &t
+	}
+
+	workOrders, meta, err := h.workOrderUC.GetAll(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	workOrders, err := h.workOrderUC.GetAll(r.Context())
+	h.respondPage(w, workOrders, meta)
+}
+
+func (h *WorkOrderHandler) GetTests(w http.ResponseWriter, r *http.Request) {
+	noOrder := r.PathValue("no")
+
+	tests, err := h.workOrderUC.GetTests(r.Context(), noOrder)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, workOrders)
+	h.respondSuccess(w, http.StatusOK, tests)
 }
 
-func (h *WorkOrderHandler) GetByDoctor(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+func (h *WorkOrderHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	h.testStep(w, r, h.workOrderUC.Receive)
+}
+
+func (h *WorkOrderHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	h.testStep(w, r, h.workOrderUC.Validate)
+}
+
+func (h *WorkOrderHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	h.testStep(w, r, h.workOrderUC.Authorize)
+}
+
+func (h *WorkOrderHandler) RecordResults(w http.ResponseWriter, r *http.Request) {
+	noOrder := r.PathValue("no")
+
+	var req dto.ResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
+		return
+	}
+
+	tests, err := h.workOrderUC.RecordResults(r.Context(), noOrder, &req)
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	doctor := r.URL.Query().Get("doctor")
-	if doctor == "" {
-		h.respondError(w, http.StatusBadRequest, "doctor parameter is required")
+	h.respondSuccess(w, http.StatusOK, tests)
+}
+
+func (h *WorkOrderHandler) Amend(w http.ResponseWriter, r *http.Request) {
+	noOrder := r.PathValue("no")
+
+	var req dto.AmendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
-	workOrders, err := h.workOrderUC.GetByDoctor(r.Context(), doctor)
+	tests, err := h.workOrderUC.Amend(r.Context(), noOrder, &req)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, workOrders)
+	h.respondSuccess(w, http.StatusOK, tests)
 }
 
-func (h *WorkOrderHandler) GetByAnalyst(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+func (h *WorkOrderHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	noOrder := r.PathValue("no")
+
+	history, err := h.workOrderUC.GetHistory(r.Context(), noOrder)
+	if err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	analyst := r.URL.Query().Get("analyst")
-	if analyst == "" {
-		h.respondError(w, http.StatusBadRequest, "analyst parameter is required")
+	h.respondSuccess(w, http.StatusOK, history)
+}
+
+// testStep decodes an optional TestStepRequest body and runs a lifecycle
+// step for the work order in the path.
+func (h *WorkOrderHandler) testStep(w http.ResponseWriter, r *http.Request, step func(context.Context, string, *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error)) {
+	noOrder := r.PathValue("no")
+
+	var req dto.TestStepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondError(w, r, apperror.Invalid("Invalid request body"))
 		return
 	}
 
-	workOrders, err := h.workOrderUC.GetByAnalyst(r.Context(), analyst)
+	tests, err := step(r.Context(), noOrder, &req)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, r, err)
 		return
 	}
 
-	h.respondSuccess(w, http.StatusOK, workOrders)
+	h.respondSuccess(w, http.StatusOK, tests)
 }
 
 func (h *WorkOrderHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
@@ -179,14 +274,15 @@ func (h *WorkOrderHandler) respondSuccess(w http.ResponseWriter, code int, data
 	json.NewEncoder(w).Encode(response)
 }
 
-func (h *WorkOrderHandler) respondError(w http.ResponseWriter, code int, message string) {
+func (h *WorkOrderHandler) respondPage(w http.ResponseWriter, data interface{}, meta *dto.PageMeta) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
+	w.WriteHeader(http.StatusOK)
 
-	response := dto.ResponseError{
-		Code:    code,
-		Status:  "error",
-		Message: message,
+	response := dto.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   data,
+		Meta:   meta,
 	}
 
 	json.NewEncoder(w).Encode(response)
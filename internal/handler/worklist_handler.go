@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/report"
+	"github.com/BioSystems-Indonesia/lis/internal/usecase"
+)
+
+var (
+	worklistPriorities = []entitiy.Priority{entitiy.PriorityRoutine, entitiy.PriorityUrgent, entitiy.PrioritySTAT}
+	worklistStatuses   = []entitiy.TestStatus{entitiy.TestStatusPending, entitiy.TestStatusReceived, entitiy.TestStatusResulted, entitiy.TestStatusValidated, entitiy.TestStatusAuthorized}
+)
+
+type WorklistHandler struct {
+	worklistUC usecase.WorklistUsecase
+}
+
+func NewWorklistHandler(worklistUC usecase.WorklistUsecase) *WorklistHandler {
+	return &WorklistHandler{
+		worklistUC: worklistUC,
+	}
+}
+
+// Get returns pending test lines matching the query filters as JSON, CSV
+// or PDF depending on the format parameter. The parameters are checked
+// before the worklist is read, so that a refused request is not recorded
+// as a view of the patients on it.
+func (h *WorklistHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" && format != "pdf" {
+		respondError(w, r, apperror.Field("format", "must be json, csv or pdf"))
+		return
+	}
+
+	req := dto.WorklistRequest{
+		Department: query.Get("department"),
+		Instrument: query.Get("instrument"),
+		Priority:   entitiy.Priority(query.Get("priority")),
+	}
+
+	if req.Priority != "" && !slices.Contains(worklistPriorities, req.Priority) {
+		respondError(w, r, apperror.Field("priority", "must be routine, urgent or stat"))
+		return
+	}
+
+	if status := query.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			testStatus := entitiy.TestStatus(strings.TrimSpace(s))
+			if !slices.Contains(worklistStatuses, testStatus) {
+				respondError(w, r, apperror.Field("status", "unknown status %q; use pending, received, resulted, validated or authorized", testStatus))
+				return
+			}
+			req.Status = append(req.Status, testStatus)
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			respondError(w, r, apperror.Field("from", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.From = &t
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			respondError(w, r, apperror.Field("to", "must be YYYY-MM-DD or RFC 3339"))
+			return
+		}
+		req.To = &t
+	}
+
+	items, err := h.worklistUC.Get(r.Context(), &req)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	switch format {
+	case "csv":
+		var buf bytes.Buffer
+		if err := report.WriteWorklistCSV(&buf, items); err != nil {
+			respondError(w, r, err)
+			return
+		}
+		h.respondFile(w, "text/csv; charset=utf-8", "worklist.csv", buf.Bytes())
+	case "pdf":
+		var buf bytes.Buffer
+		if err := report.WriteWorklistPDF(&buf, worklistTitle(&req), items); err != nil {
+			respondError(w, r, err)
+			return
+		}
+		h.respondFile(w, "application/pdf", "worklist.pdf", buf.Bytes())
+	default:
+		h.respondSuccess(w, http.StatusOK, items)
+	}
+}
+
+// parseDateParam accepts a date or an RFC 3339 timestamp. A bare date used
+// as an upper bound is moved to the start of the next day so that the whole
+// day is included.
+func parseDateParam(value string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
+
+func worklistTitle(req *dto.WorklistRequest) string {
+	parts := []string{"Worklist"}
+	if req.Department != "" {
+		parts = append(parts, req.Department)
+	}
+	if req.Instrument != "" {
+		parts = append(parts, req.Instrument)
+	}
+	if req.Priority != "" {
+		parts = append(parts, strings.ToUpper(string(req.Priority)))
+	}
+
+	return strings.Join(parts, " - ")
+}
+
+func (h *WorklistHandler) respondFile(w http.ResponseWriter, contentType, filename string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (h *WorklistHandler) respondSuccess(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	response := dto.Response{
+		Code:   code,
+		Status: "success",
+		Data:   data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
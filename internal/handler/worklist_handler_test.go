@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// worklistRecorder records the worklist requests that reach the usecase.
+type worklistRecorder struct {
+	requests []*dto.WorklistRequest
+}
+
+func (u *worklistRecorder) Get(ctx context.Context, req *dto.WorklistRequest) ([]*dto.WorklistItemResponse, error) {
+	u.requests = append(u.requests, req)
+	return nil, nil
+}
+
+func TestWorklistHandlerChecksParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		field string
+		want  dto.WorklistRequest
+	}{
+		{"defaults", "", http.StatusOK, "", dto.WorklistRequest{}},
+		{"filters", "status=pending,%20resulted&priority=stat&format=csv", http.StatusOK, "", dto.WorklistRequest{
+			Priority: entitiy.PrioritySTAT,
+			Status:   []entitiy.TestStatus{entitiy.TestStatusPending, entitiy.TestStatusResulted},
+		}},
+		{"unknown format", "format=xml", http.StatusBadRequest, "format", dto.WorklistRequest{}},
+		{"unknown priority", "priority=asap", http.StatusBadRequest, "priority", dto.WorklistRequest{}},
+		{"unknown status", "status=pending,done", http.StatusBadRequest, "status", dto.WorklistRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worklist := &worklistRecorder{}
+			response := httptest.NewRecorder()
+			NewWorklistHandler(worklist).Get(response, httptest.NewRequest(http.MethodGet, "/worklist?"+tt.query, nil))
+
+			if response.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", response.Code, tt.code, response.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				if len(worklist.requests) > 0 {
+					t.Error("refused request read the worklist")
+				}
+				if !strings.Contains(response.Body.String(), `"`+tt.field+`"`) {
+					t.Errorf("response does not name %s: %s", tt.field, response.Body)
+				}
+				return
+			}
+
+			if len(worklist.requests) != 1 {
+				t.Fatalf("worklist read %d times, want once", len(worklist.requests))
+			}
+			if req := worklist.requests[0]; req.Priority != tt.want.Priority || !slices.Equal(req.Status, tt.want.Status) {
+				t.Errorf("priority, status = %q, %q, want %q, %q", req.Priority, req.Status, tt.want.Priority, tt.want.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Database pings db and reports the state of its connection pool.
+func Database(db *sql.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) CheckResult {
+			stats := db.Stats()
+			details := map[string]interface{}{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+				"wait_count":       stats.WaitCount,
+			}
+
+			if err := db.PingContext(ctx); err != nil {
+				return Fail(fmt.Errorf("ping failed: %w", err), details)
+			}
+			return OK(details)
+		},
+	}
+}
+
+// Migrations fails while pending returns migrations that have not been
+// applied: the schema is then older than the code expects.
+func Migrations(pending func(ctx context.Context) ([]string, error)) Check {
+	return Check{
+		Name:     "migrations",
+		Critical: true,
+		Run: func(ctx context.Context) CheckResult {
+			versions, err := pending(ctx)
+			if err != nil {
+				return Fail(err, nil)
+			}
+
+			details := map[string]interface{}{"pending": len(versions)}
+			if len(versions) > 0 {
+				details["versions"] = versions
+				return Fail(fmt.Errorf("not applied: %s", strings.Join(versions, ", ")), details)
+			}
+			return OK(details)
+		},
+	}
+}
+
+// Disk reports the free space of the file systems holding paths, and
+// warns when any has less than minFree bytes free. Paths on the same file
+// system are reported once.
+func Disk(paths []string, minFree uint64) Check {
+	return Check{
+		Name: "disk",
+		Run: func(ctx context.Context) CheckResult {
+			details := make(map[string]interface{}, len(paths))
+			var low []string
+
+			for _, path := range paths {
+				if abs, err := filepath.Abs(path); err == nil {
+					path = abs
+				}
+				if _, ok := details[path]; ok {
+					continue
+				}
+
+				usage, err := diskUsage(path)
+				if err != nil {
+					return Fail(err, details)
+				}
+
+				details[path] = map[string]interface{}{
+					"free_bytes":   usage.free,
+					"total_bytes":  usage.total,
+					"free_percent": usage.freePercent(),
+				}
+				if usage.free < minFree {
+					low = append(low, path)
+				}
+			}
+
+			if len(low) > 0 {
+				return Warn(fmt.Sprintf("less than %d MB free on %s", minFree>>20, strings.Join(low, ", ")), details)
+			}
+			return OK(details)
+		},
+	}
+}
+
+type usage struct {
+	free  uint64
+	total uint64
+}
+
+func (u usage) freePercent() float64 {
+	if u.total == 0 {
+		return 0
+	}
+	return float64(u.free*1000/u.total) / 10
+}
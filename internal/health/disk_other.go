@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || freebsd)
+
+package health
+
+import (
+	"errors"
+	"runtime"
+)
+
+func diskUsage(path string) (usage, error) {
+	return usage{}, errors.New("free space is not reported on " + runtime.GOOS)
+}
@@ -0,0 +1,22 @@
+//go:build linux || darwin || freebsd
+
+package health
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// diskUsage returns the space available to unprivileged users on the file
+// system holding path.
+func diskUsage(path string) (usage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return usage{}, fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+
+	return usage{
+		free:  uint64(stat.Bavail) * uint64(stat.Bsize),
+		total: uint64(stat.Blocks) * uint64(stat.Bsize),
+	}, nil
+}
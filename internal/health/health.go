@@ -0,0 +1,166 @@
+// Package health runs the checks behind the readiness endpoint: each
+// dependency of the server is checked concurrently within a timeout, and
+// the results are gathered into one report.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a check, or of a whole report.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// CheckResult is the outcome of one check. Details are the figures a monitor
+// graphs or alerts on, such as a latency or a queue depth.
+type CheckResult struct {
+	Status     Status                 `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMS float64                `json:"duration_ms"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// OK returns a passing result with details.
+func OK(details map[string]interface{}) CheckResult {
+	return CheckResult{Status: StatusOK, Details: details}
+}
+
+// Warn returns a result that degrades the report without making the
+// server unready.
+func Warn(message string, details map[string]interface{}) CheckResult {
+	return CheckResult{Status: StatusWarn, Error: message, Details: details}
+}
+
+// Fail returns a failing result.
+func Fail(err error, details map[string]interface{}) CheckResult {
+	return CheckResult{Status: StatusFail, Error: err.Error(), Details: details}
+}
+
+// Check is one dependency of the server.
+type Check struct {
+	Name string
+	// Critical checks make the server unready when they fail. A failed
+	// check that is not critical only degrades the report.
+	Critical bool
+	// Run checks the dependency. It should return once ctx is done.
+	Run func(ctx context.Context) CheckResult
+}
+
+// Readiness is the outcome of every check. Its status is fail when a
+// critical check failed, warn when any other check did not pass, and ok
+// otherwise.
+type Readiness struct {
+	Status    Status                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Ready reports whether the server can take traffic.
+func (r Readiness) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Summary is a report without the details and errors of its checks, the
+// part of it that is served to anyone.
+type Summary struct {
+	Status    Status            `json:"status"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Checks    map[string]Status `json:"checks"`
+}
+
+// Summary returns the status of the report and of each check.
+func (r Readiness) Summary() Summary {
+	checks := make(map[string]Status, len(r.Checks))
+	for name, result := range r.Checks {
+		checks[name] = result.Status
+	}
+
+	return Summary{Status: r.Status, CheckedAt: r.CheckedAt, Checks: checks}
+}
+
+// Checker runs a fixed set of checks.
+type Checker struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+// NewChecker returns a checker that gives each check timeout to run.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check. It must be called before the checker is used.
+func (c *Checker) Add(check Check) {
+	c.checks = append(c.checks, check)
+}
+
+// Run runs every check concurrently and returns the report once all have
+// finished or timed out.
+func (c *Checker) Run(ctx context.Context) Readiness {
+	results := make([]CheckResult, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Readiness{
+		Status:    StatusOK,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+	}
+
+	for i, check := range c.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+
+		switch {
+		case result.Status == StatusOK:
+		case check.Critical && result.Status == StatusFail:
+			report.Status = StatusFail
+		case report.Status == StatusOK:
+			report.Status = StatusWarn
+		}
+	}
+
+	return report
+}
+
+// run runs check within the timeout. A check that does not return in time
+// fails; its goroutine is left to finish on its own.
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	done := make(chan CheckResult, 1)
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var result CheckResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Fail(ctx.Err(), nil)
+	}
+
+	result.DurationMS = milliseconds(time.Since(started))
+	return result
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,192 @@
+// Package lifecycle runs the long-running parts of the server: it starts
+// them, stops them in reverse order on SIGINT or SIGTERM, giving each the
+// chance to finish its work in progress, and closes shared resources such
+// as the database pool last.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Service is a long-running part of the server, such as a listener or a
+// background worker.
+type Service struct {
+	Name string
+	// Start runs the service. It blocks until the service fails or is
+	// stopped, and returns nil when it was stopped.
+	Start func() error
+	// Stop stops the service, waiting for its work in progress until ctx
+	// is done.
+	Stop func(ctx context.Context) error
+}
+
+// HTTPServer returns the service of server. serve is server.Serve on a
+// listener, plain or TLS, that is already open, so that connections are
+// accepted as soon as the manager reports ready.
+func HTTPServer(name string, server *http.Server, serve func() error) Service {
+	return Service{
+		Name: name,
+		Start: func() error {
+			if err := serve(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: server.Shutdown,
+	}
+}
+
+// Worker returns the service of a background worker. run must return
+// when its context is cancelled.
+func Worker(name string, run func(ctx context.Context) error) Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	return Service{
+		Name: name,
+		Start: func() error {
+			defer close(done)
+
+			if err := run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		},
+		Stop: func(stopCtx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	}
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+// Manager runs services until the process is told to stop.
+type Manager struct {
+	shutdownTimeout time.Duration
+	services        []Service
+	closers         []closer
+	ready           atomic.Bool
+	stopping        atomic.Bool
+}
+
+// New returns a manager that gives its services shutdownTimeout in total
+// to stop.
+func New(shutdownTimeout time.Duration) *Manager {
+	return &Manager{shutdownTimeout: shutdownTimeout}
+}
+
+// Add registers a service. Services start in the order they are added and
+// stop in the reverse order.
+func (m *Manager) Add(service Service) {
+	m.services = append(m.services, service)
+}
+
+// OnClose registers a resource to close once every service has stopped.
+// Resources are closed in the reverse order they are registered, so the
+// first one registered, typically the database, is closed last.
+func (m *Manager) OnClose(name string, close func() error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Ready reports whether every service has been started and shutdown has
+// not begun.
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// Stopping reports whether shutdown has begun.
+func (m *Manager) Stopping() bool {
+	return m.stopping.Load()
+}
+
+// Run starts every service and blocks until ctx is done, SIGINT or
+// SIGTERM is received, or a service fails. It then stops the services and
+// closes the resources. It returns the error of the service that failed,
+// or of the shutdown.
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, len(m.services))
+
+	for _, service := range m.services {
+		go func(service Service) {
+			if err := service.Start(); err != nil {
+				failed <- fmt.Errorf("%s: %w", service.Name, err)
+				return
+			}
+			if !m.Stopping() {
+				failed <- fmt.Errorf("%s stopped unexpectedly", service.Name)
+			}
+		}(service)
+	}
+
+	m.ready.Store(true)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutting down")
+	case runErr = <-failed:
+		slog.Error("Shutting down", "error", runErr)
+	}
+
+	// A second signal stops the process without waiting.
+	stop()
+
+	m.ready.Store(false)
+	m.stopping.Store(true)
+
+	return errors.Join(runErr, m.shutdown())
+}
+
+// shutdown stops the services in reverse order within the shutdown
+// timeout, then closes the resources.
+func (m *Manager) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	for i := len(m.services) - 1; i >= 0; i-- {
+		service := m.services[i]
+
+		started := time.Now()
+		if err := service.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", service.Name, err))
+			continue
+		}
+		slog.Info("Stopped service", "service", service.Name, "duration", time.Since(started).Round(time.Millisecond))
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		closer := m.closers[i]
+
+		if err := closer.close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", closer.name, err))
+			continue
+		}
+		slog.Info("Closed resource", "resource", closer.name)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,59 @@
+// Package logging adapts log/slog to the server: every record logged with
+// a request context carries the request ID and, when the request is
+// traced, the trace ID, and patient data is redacted before it is
+// written.
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/BioSystems-Indonesia/lis/internal/requestmeta"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler adds the request and trace IDs of the context to each record
+// and redacts its message and attributes before passing it to the next
+// handler.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a handler writing to next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, RedactText(record.Message), record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+
+	if requestID := requestmeta.RequestID(ctx); requestID != "" {
+		redacted.AddAttrs(slog.String("request_id", requestID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		redacted.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
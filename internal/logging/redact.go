@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces sensitive values in logs.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are always redacted:
+// patient identity and contact details, and credentials.
+var sensitiveKeys = map[string]bool{
+	"first_name":    true,
+	"last_name":     true,
+	"full_name":     true,
+	"patient_name":  true,
+	"birth_date":    true,
+	"birthdate":     true,
+	"address":       true,
+	"phone":         true,
+	"email":         true,
+	"nik":           true,
+	"national_id":   true,
+	"password":      true,
+	"token":         true,
+	"secret":        true,
+	"authorization": true,
+}
+
+// sensitivePatterns find patient data in free text, such as driver error
+// messages that quote the value of a column.
+var sensitivePatterns = []*regexp.Regexp{
+	// Email addresses.
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	// Indonesian mobile numbers: 08..., 628... or +628..., with optional
+	// spaces or dashes between the groups of digits.
+	regexp.MustCompile(`(?:\+62[- ]?|\b62|\b0)8\d{1,3}[- ]?\d{3,4}[- ]?\d{3,5}\b`),
+	// National identity numbers (NIK) have 16 digits.
+	regexp.MustCompile(`\b\d{16}\b`),
+	// Values quoted by MySQL and PostgreSQL in constraint violations.
+	regexp.MustCompile(`Duplicate entry '[^']*'`),
+	regexp.MustCompile(`\)=\([^)]*\)`),
+}
+
+// RedactText replaces the patient data found in s.
+func RedactText(s string) string {
+	for _, pattern := range sensitivePatterns {
+		s = pattern.ReplaceAllStringFunc(s, redactMatch)
+	}
+	return s
+}
+
+// redactMatch keeps the wording around values quoted in constraint
+// violations, so that the kind of error is still visible.
+func redactMatch(match string) string {
+	switch {
+	case strings.HasPrefix(match, "Duplicate entry "):
+		return "Duplicate entry '" + Redacted + "'"
+	case strings.HasPrefix(match, ")=("):
+		return ")=(" + Redacted + ")"
+	default:
+		return Redacted
+	}
+}
+
+// redactAttr redacts the value of attr when its key is sensitive, and the
+// patient data found in its text otherwise. Values that are not strings
+// are logged as text, so that no field of a struct escapes redaction;
+// types holding patient data implement slog.LogValuer to log only their
+// ID.
+func redactAttr(attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactText(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]interface{}, len(group))
+		for i, member := range group {
+			redacted[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, RedactText(err.Error()))
+		}
+		return slog.String(attr.Key, RedactText(fmt.Sprintf("%+v", value.Any())))
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
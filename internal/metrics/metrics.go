@@ -0,0 +1,136 @@
+// Package metrics defines the Prometheus metrics of the server and serves
+// them. Metrics are registered on a registry of their own rather than the
+// global one, so that only the metrics documented here are exposed.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "lis"
+
+// Registry holds every metric of the server.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+
+	transactionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_transaction_duration_seconds",
+		Help:      "Time database transactions were open, by usecase method and outcome (commit or rollback).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "outcome"})
+
+	workOrdersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "work_orders_created_total",
+		Help:      "Work orders created, by priority.",
+	}, []string{"priority"})
+
+	resultsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "results_received_total",
+		Help:      "Test results recorded, by the instrument of the test in the catalog.",
+	}, []string{"instrument"})
+
+	testsPending = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tests_pending",
+		Help:      "Test lines not yet authorized, by department and status.",
+	}, []string{"department", "status"})
+
+	criticalResultsOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "critical_results_open",
+		Help:      "Results with a critical flag that are not yet authorized, by department.",
+	}, []string{"department"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		transactionDuration,
+		workOrdersCreated,
+		resultsReceived,
+		testsPending,
+		criticalResultsOpen,
+	)
+}
+
+// RegisterDB exports the connection pool statistics of db as the go_sql_*
+// metrics, labelled with name.
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveTransaction records a transaction of method that was open for
+// duration.
+func ObserveTransaction(method, outcome string, duration time.Duration) {
+	transactionDuration.WithLabelValues(method, outcome).Observe(duration.Seconds())
+}
+
+// WorkOrderCreated counts a work order of priority.
+func WorkOrderCreated(priority string) {
+	workOrdersCreated.WithLabelValues(priority).Inc()
+}
+
+// ResultReceived counts a result for a test run on instrument. Tests
+// without an instrument in the catalog are counted as unassigned.
+func ResultReceived(instrument string) {
+	if instrument == "" {
+		instrument = "unassigned"
+	}
+	resultsReceived.WithLabelValues(instrument).Inc()
+}
+
+// SetWorkload replaces the lab workload gauges with counts. Every
+// department with work is reported, with zero critical results when it
+// has none, so that alerts on critical results see a value.
+func SetWorkload(counts []*entitiy.WorkloadCount) {
+	testsPending.Reset()
+	criticalResultsOpen.Reset()
+
+	for _, count := range counts {
+		department := count.Department
+		if department == "" {
+			department = "unassigned"
+		}
+
+		testsPending.WithLabelValues(department, string(count.Status)).Add(float64(count.Count))
+		criticalResultsOpen.WithLabelValues(department).Add(float64(count.Critical))
+	}
+}
@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//go:embed ui.html
+var uiPage string
+
+// uiAssets is the script and stylesheet of swagger-ui-dist 5.18.2, served
+// by the server itself so that the documentation works without internet
+// access. Replace both files together to upgrade.
+//
+//go:embed swagger-ui
+var uiAssets embed.FS
+
+// Handler serves the document as JSON. The document is encoded on every
+// request, so routes added after the handler is created are included.
+func (s *Spec) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Document())
+	}
+}
+
+// UIHandler serves a Swagger UI page for the document at specURL. The
+// page loads the Swagger UI scripts from assetsURL, where UIAssetHandler
+// serves them.
+func UIHandler(specURL, assetsURL string) http.HandlerFunc {
+	page := strings.NewReplacer("{{SPEC_URL}}", specURL, "{{ASSETS}}", assetsURL).Replace(uiPage)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}
+}
+
+// UIAssetHandler serves the Swagger UI script or stylesheet named by the
+// last element of the request path, such as /docs/swagger-ui.css.
+func UIAssetHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, uiAssets, "swagger-ui/"+path.Base(r.URL.Path))
+	}
+}
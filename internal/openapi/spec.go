@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+// Route documents one endpoint.
+type Route struct {
+	Summary     string
+	Description string
+	// Query lists the query parameters. Path parameters are taken from the
+	// route pattern.
+	Query []Parameter
+	// Body is a value of the request body type, or nil when the endpoint
+	// takes no body.
+	Body         interface{}
+	BodyOptional bool
+	// Response is a value of the type returned in the data field of the
+	// success response.
+	Response interface{}
+	// Status is the success status code; it defaults to 200.
+	Status int
+	// Sort lists the sort fields of a paginated list. Setting it adds the
+	// page, page_size and sort parameters and the meta field of the
+	// response.
+	Sort []string
+	// Produces lists further media types the endpoint can return instead
+	// of JSON, selected by a query parameter.
+	Produces []string
+	// Raw marks an endpoint whose response is not wrapped in the JSON
+	// envelope, such as the health check; it is returned as Produces[0].
+	Raw bool
+}
+
+// Query returns a string query parameter.
+func Query(name, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// Security scheme names.
+const (
+	BearerAuth = "bearerAuth"
+	APIKeyAuth = "apiKey"
+)
+
+// Spec builds an OpenAPI document route by route.
+type Spec struct {
+	doc   Document
+	enums map[reflect.Type][]interface{}
+}
+
+// New returns a Spec for an API whose operations require a bearer access
+// token or an API key unless added as public.
+func New(info Info) *Spec {
+	return &Spec{
+		doc: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+				SecuritySchemes: map[string]*SecurityScheme{
+					BearerAuth: {
+						Type:        "http",
+						Scheme:      "bearer",
+						Description: "Access token from POST /auth/login. An API key is accepted as the bearer token too.",
+					},
+					APIKeyAuth: {
+						Type: "apiKey",
+						In:   "header",
+						Name: "X-API-Key",
+					},
+				},
+			},
+			Security: []SecurityRequirement{{BearerAuth: {}}, {APIKeyAuth: {}}},
+		},
+		enums: map[reflect.Type][]interface{}{},
+	}
+}
+
+// Enum declares the values of a string type, such as entitiy.Priority,
+// so fields of that type are documented with them. Call it before adding
+// the routes that use the type.
+func (s *Spec) Enum(values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	s.enums[reflect.TypeOf(values[0])] = values
+}
+
+var pathParam = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// Add documents the route registered on a ServeMux with pattern. A
+// pattern without a method is documented as GET. permission is the
+// permission the route requires, if any; a public route is served without
+// credentials.
+func (s *Spec) Add(pattern string, route Route, permission string, public bool) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = http.MethodGet, pattern
+	}
+
+	op := &Operation{
+		Tags:        []string{tagOf(path)},
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(method, path),
+		Responses:   map[string]*Response{},
+		Permission:  permission,
+	}
+
+	if permission != "" {
+		op.Description = strings.TrimSpace(op.Description + fmt.Sprintf("\n\nRequires the `%s` permission.", permission))
+	}
+	if public {
+		op.Security = &[]SecurityRequirement{}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	op.Parameters = append(op.Parameters, route.Query...)
+
+	if route.Sort != nil {
+		op.Parameters = append(op.Parameters,
+			Parameter{Name: "page", In: "query", Description: "Page number, starting at 1.", Schema: &Schema{Type: "integer"}},
+			Parameter{Name: "page_size", In: "query", Description: "Items per page.", Schema: &Schema{Type: "integer"}},
+			Parameter{Name: "sort", In: "query", Description: "Sort field, prefixed with - for descending order.", Schema: &Schema{Type: "string", Enum: sortValues(route.Sort)}},
+		)
+	}
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: !route.BodyOptional,
+			Content:  map[string]MediaType{"application/json": {Schema: s.schemaOf(reflect.TypeOf(route.Body))}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := &Response{Description: http.StatusText(status), Content: map[string]MediaType{}}
+	if route.Raw {
+		success.Content[route.Produces[0]] = MediaType{Schema: s.rawSchema(route)}
+	} else {
+		success.Content["application/json"] = MediaType{Schema: s.envelope(route)}
+		for _, mediaType := range route.Produces {
+			success.Content[mediaType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	}
+	op.Responses[fmt.Sprint(status)] = success
+
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]MediaType{"application/json": {Schema: s.schemaOf(reflect.TypeOf(dto.ResponseError{}))}},
+	}
+
+	item := s.doc.Paths[openAPIPath(path)]
+	if item == nil {
+		item = PathItem{}
+		s.doc.Paths[openAPIPath(path)] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// envelope is the schema of the JSON success response of route: its data
+// wrapped with the status code, and the page metadata for lists.
+func (s *Spec) envelope(route Route) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":   {Type: "integer"},
+			"status": {Type: "string"},
+			"data":   {},
+		},
+	}
+	if route.Response != nil {
+		schema.Properties["data"] = s.schemaOf(reflect.TypeOf(route.Response))
+	}
+	if route.Sort != nil {
+		schema.Properties["meta"] = s.schemaOf(reflect.TypeOf(dto.PageMeta{}))
+	}
+	return schema
+}
+
+func (s *Spec) rawSchema(route Route) *Schema {
+	if route.Response == nil {
+		return &Schema{Type: "string"}
+	}
+	return s.schemaOf(reflect.TypeOf(route.Response))
+}
+
+// Document returns the document built so far, with its tags sorted.
+func (s *Spec) Document() Document {
+	doc := s.doc
+
+	seen := map[string]bool{}
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			for _, tag := range op.Tags {
+				seen[tag] = true
+			}
+		}
+	}
+
+	doc.Tags = nil
+	for tag := range seen {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+// tagOf groups operations by the first segment of their path.
+func tagOf(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
+}
+
+// operationID derives an identifier such as getWorkOrdersNoTests from the
+// method and path.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '{' || r == '}' || r == '.' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// openAPIPath turns a ServeMux path into an OpenAPI path template: a
+// {name...} wildcard becomes {name} and a trailing {$} is dropped.
+func openAPIPath(path string) string {
+	path = strings.TrimSuffix(path, "{$}")
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+func sortValues(fields []string) []interface{} {
+	values := make([]interface{}, 0, 2*len(fields))
+	for _, field := range fields {
+		values = append(values, field, "-"+field)
+	}
+	return values
+}
@@ -0,0 +1,345 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// Letterhead is the laboratory identity printed at the top of every page
+// of a patient report.
+type Letterhead struct {
+	Name     string
+	Address  string
+	Phone    string
+	Email    string
+	LogoPath string
+}
+
+// Verification is printed next to the signature block so that a report
+// can be checked for authenticity. URL is encoded in a QR code.
+type Verification struct {
+	Code string
+	URL  string
+}
+
+const (
+	reportMargin    = 15.0
+	reportQRSize    = 28.0
+	reportRowHeight = 6.0
+)
+
+var reportColumns = []struct {
+	header string
+	width  float64
+	align  string
+}{
+	{"Test", 62, "L"},
+	{"Result", 28, "R"},
+	{"Flag", 14, "C"},
+	{"Unit", 24, "L"},
+	{"Reference Range", 52, "L"},
+}
+
+// WriteLabReportPDF renders the patient report of a work order as an A4
+// PDF with the laboratory letterhead, the results grouped by department,
+// the validation and authorization block and the verification code with
+// its QR code.
+func WriteLabReportPDF(w io.Writer, letterhead Letterhead, report *dto.LabReportResponse, verification Verification) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(reportMargin, reportMargin, reportMargin)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	printedAt := time.Now()
+
+	pdf.SetTitle(fmt.Sprintf("Laboratory Report %s", report.NoOrder), true)
+	pdf.SetAuthor(letterhead.Name, true)
+	pdf.SetHeaderFunc(func() {
+		writeLetterhead(pdf, tr, letterhead)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("No Order %s - printed %s", report.NoOrder, printedAt.Format("2006-01-02 15:04"))), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+	pdf.AddPage()
+
+	title := "LABORATORY REPORT"
+	switch report.Status {
+	case dto.LabReportPreliminary:
+		title += " (PRELIMINARY)"
+	case dto.LabReportAmended:
+		title += " (AMENDED)"
+	}
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, title, "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	writePatientBlock(pdf, tr, report)
+	pdf.Ln(4)
+
+	for _, section := range report.Sections {
+		writeResultSection(pdf, tr, section)
+		pdf.Ln(3)
+	}
+
+	if len(report.Sections) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, 8, "No results available yet.", "", 1, "L", false, 0, "")
+	}
+
+	if err := writeSignatureBlock(pdf, tr, report, verification); err != nil {
+		return err
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to write lab report pdf: %w", err)
+	}
+
+	return nil
+}
+
+func writeLetterhead(pdf *gofpdf.Fpdf, tr func(string) string, letterhead Letterhead) {
+	textX := reportMargin
+
+	if letterhead.LogoPath != "" {
+		info := pdf.RegisterImageOptions(letterhead.LogoPath, gofpdf.ImageOptions{ReadDpi: true})
+		if pdf.Ok() && info != nil {
+			pdf.ImageOptions(letterhead.LogoPath, reportMargin, 10, 0, 18, false, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+			textX += info.Width()*18/info.Height() + 4
+		} else {
+			// A missing logo must not prevent the report from printing.
+			pdf.ClearError()
+		}
+	}
+
+	pdf.SetXY(textX, 10)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 7, tr(letterhead.Name), "", 2, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	if letterhead.Address != "" {
+		pdf.CellFormat(0, 4.5, tr(letterhead.Address), "", 2, "L", false, 0, "")
+	}
+
+	var contact []string
+	if letterhead.Phone != "" {
+		contact = append(contact, "Tel. "+letterhead.Phone)
+	}
+	if letterhead.Email != "" {
+		contact = append(contact, letterhead.Email)
+	}
+	if len(contact) > 0 {
+		pdf.CellFormat(0, 4.5, tr(strings.Join(contact, "  |  ")), "", 2, "L", false, 0, "")
+	}
+
+	pageWidth, _ := pdf.GetPageSize()
+	pdf.SetLineWidth(0.6)
+	pdf.Line(reportMargin, 30, pageWidth-reportMargin, 30)
+	pdf.SetLineWidth(0.2)
+	pdf.SetXY(reportMargin, 33)
+}
+
+func writePatientBlock(pdf *gofpdf.Fpdf, tr func(string) string, report *dto.LabReportResponse) {
+	var name, patientID, birth, sex, phone string
+	if p := report.Patient; p != nil {
+		name = patientName(p.FirstName, p.LastName)
+		patientID = p.ID
+		birth = fmt.Sprintf("%s (%s)", p.Birthdate.Format("02 Jan 2006"), age(p.Birthdate, report.OrderedAt))
+		sex = string(p.Sex)
+		phone = p.Phone
+	}
+
+	left := [][2]string{
+		{"Patient", name},
+		{"Patient ID", patientID},
+		{"Birth Date", birth},
+		{"Sex", sex},
+		{"Phone", phone},
+	}
+	right := [][2]string{
+		{"No Order", report.NoOrder},
+		{"Doctor", report.Doctor},
+		{"Analyst", report.Analyst},
+		{"Priority", strings.ToUpper(string(report.Priority))},
+		{"Ordered At", report.OrderedAt.Format("02 Jan 2006 15:04")},
+	}
+
+	for i := range left {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(24, 5, left[i][0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(66, 5, tr(": "+left[i][1]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(24, 5, right[i][0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, tr(": "+right[i][1]), "", 1, "L", false, 0, "")
+	}
+}
+
+func writeResultSection(pdf *gofpdf.Fpdf, tr func(string) string, section *dto.LabReportSection) {
+	department := section.Department
+	if department == "" {
+		department = "Other"
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(220, 230, 240)
+	pdf.CellFormat(0, 7, tr(strings.ToUpper(department)), "", 1, "L", true, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 9)
+	for _, col := range reportColumns {
+		pdf.CellFormat(col.width, reportRowHeight, col.header, "B", 0, col.align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for _, result := range section.Results {
+		style := ""
+		if result.Flag != "" && !strings.EqualFold(result.Flag, "N") {
+			style = "B"
+		}
+
+		row := []string{result.TestName, result.Value, result.Flag, result.Unit, result.ReferenceRange}
+		for i, col := range reportColumns {
+			pdf.SetFont("Helvetica", style, 9)
+			pdf.CellFormat(col.width, reportRowHeight, tr(truncate(pdf, row[i], col.width-2)), "", 0, col.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		if result.Comment != "" {
+			pdf.SetFont("Helvetica", "I", 8)
+			pdf.SetX(reportMargin + 4)
+			pdf.MultiCell(0, 4.5, tr("Note: "+result.Comment), "", "L", false)
+		}
+
+		if result.Amended {
+			note := fmt.Sprintf("Amended %s by %s. Previous result: %s %s", formatTime(result.AmendedAt), result.AmendedBy, result.PreviousValue, result.PreviousFlag)
+			if result.AmendReason != "" {
+				note += ". Reason: " + result.AmendReason
+			}
+			pdf.SetFont("Helvetica", "I", 8)
+			pdf.SetX(reportMargin + 4)
+			pdf.MultiCell(0, 4.5, tr(strings.TrimSpace(note)), "", "L", false)
+		}
+	}
+}
+
+func writeSignatureBlock(pdf *gofpdf.Fpdf, tr func(string) string, report *dto.LabReportResponse, verification Verification) error {
+	validatedBy, validatedAt := latestSigner(report, func(r *dto.LabReportResult) (string, *time.Time) {
+		return r.ValidatedBy, r.ValidatedAt
+	})
+	authorizedBy, authorizedAt := latestSigner(report, func(r *dto.LabReportResult) (string, *time.Time) {
+		return r.AuthorizedBy, r.AuthorizedAt
+	})
+
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+reportQRSize+12 > pageHeight-20 {
+		pdf.AddPage()
+	}
+
+	pdf.Ln(4)
+	top := pdf.GetY()
+	pageWidth, _ := pdf.GetPageSize()
+
+	signer := func(x float64, label, name string, at *time.Time) {
+		pdf.SetXY(x, top)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(60, 5, label, "", 2, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		if name == "" {
+			name = "-"
+		}
+		pdf.CellFormat(60, 5, tr(name), "", 2, "L", false, 0, "")
+		when := formatTime(at)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(60, 5, when, "", 2, "L", false, 0, "")
+		if at != nil {
+			pdf.CellFormat(60, 5, "Electronically signed", "", 2, "L", false, 0, "")
+		}
+	}
+
+	signer(reportMargin, "Validated by", validatedBy, validatedAt)
+	signer(reportMargin+62, "Authorized by", authorizedBy, authorizedAt)
+
+	if verification.URL == "" {
+		pdf.SetY(top + reportQRSize)
+		return nil
+	}
+
+	png, err := qrcode.Encode(verification.URL, qrcode.Medium, 256)
+	if err != nil {
+		return fmt.Errorf("failed to encode verification qr code: %w", err)
+	}
+
+	options := gofpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("verification-qr", options, bytes.NewReader(png))
+	qrX := pageWidth - reportMargin - reportQRSize
+	pdf.ImageOptions("verification-qr", qrX, top, reportQRSize, reportQRSize, false, options, 0, verification.URL)
+
+	pdf.SetXY(qrX-20, top+reportQRSize)
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(reportQRSize+20, 4, "Scan to verify this report", "", 2, "R", false, 0, "")
+	if verification.Code != "" {
+		pdf.SetFont("Courier", "B", 9)
+		pdf.CellFormat(reportQRSize+20, 4, formatVerificationCode(verification.Code), "", 1, "R", false, 0, "")
+	}
+
+	return nil
+}
+
+// latestSigner returns the person and time of the most recent signature of
+// one kind across all reported results.
+func latestSigner(report *dto.LabReportResponse, get func(*dto.LabReportResult) (string, *time.Time)) (string, *time.Time) {
+	var name string
+	var latest *time.Time
+
+	for _, section := range report.Sections {
+		for _, result := range section.Results {
+			n, at := get(result)
+			if at != nil && (latest == nil || at.After(*latest)) {
+				name, latest = n, at
+			}
+		}
+	}
+
+	return name, latest
+}
+
+func age(birthdate, at time.Time) string {
+	years := at.Year() - birthdate.Year()
+	if at.YearDay() < birthdate.YearDay() {
+		years--
+	}
+
+	if years > 0 {
+		return fmt.Sprintf("%d y", years)
+	}
+
+	months := int(at.Sub(birthdate).Hours() / 24 / 30)
+	return fmt.Sprintf("%d mo", months)
+}
+
+// formatVerificationCode groups the code in blocks of five for reading.
+func formatVerificationCode(code string) string {
+	var groups []string
+	for len(code) > 5 {
+		groups = append(groups, code[:5])
+		code = code[5:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("02 Jan 2006 15:04")
+}
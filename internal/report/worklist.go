@@ -0,0 +1,168 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/jung-kurt/gofpdf"
+)
+
+var worklistHeader = []string{
+	"No Order", "Priority", "Test Code", "Test Name", "Department", "Instrument",
+	"Status", "Patient ID", "Patient Name", "Birth Date", "Sex", "Doctor", "Ordered At",
+}
+
+// WriteWorklistCSV writes the worklist as CSV with a header row. Cells are
+// escaped with csvCell, since patient names and other free text are
+// entered by users.
+func WriteWorklistCSV(w io.Writer, items []*dto.WorklistItemResponse) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(worklistHeader); err != nil {
+		return fmt.Errorf("failed to write worklist header: %w", err)
+	}
+
+	for _, item := range items {
+		record := []string{
+			item.NoOrder,
+			strings.ToUpper(string(item.Priority)),
+			item.TestCode,
+			item.TestName,
+			item.Department,
+			item.Instrument,
+			string(item.Status),
+			item.Patient.ID,
+			patientName(item.Patient.FirstName, item.Patient.LastName),
+			item.Patient.Birthdate.Format("2006-01-02"),
+			string(item.Patient.Sex),
+			item.Doctor,
+			item.OrderedAt.Format("2006-01-02 15:04"),
+		}
+		for i := range record {
+			record[i] = csvCell(record[i])
+		}
+
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write worklist row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteWorklistPDF writes the worklist as a landscape A4 table ready for
+// printing at the bench.
+func WriteWorklistPDF(w io.Writer, title string, items []*dto.WorklistItemResponse) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetTitle(title, true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 6, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+
+	columns := []struct {
+		header string
+		width  float64
+	}{
+		{"No Order", 28},
+		{"Pri", 14},
+		{"Test", 22},
+		{"Test Name", 45},
+		{"Instrument", 30},
+		{"Status", 20},
+		{"Patient", 50},
+		{"Birth Date", 22},
+		{"Sex", 12},
+		{"Ordered At", 28},
+	}
+
+	printHeader := func() {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 8, tr(title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 6, fmt.Sprintf("Generated %s - %d test(s)", time.Now().Format("2006-01-02 15:04"), len(items)), "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, col := range columns {
+			pdf.CellFormat(col.width, 7, col.header, "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+
+	pdf.AddPage()
+	printHeader()
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+
+	for _, item := range items {
+		if pdf.GetY()+7 > pageHeight-bottomMargin-10 {
+			pdf.AddPage()
+			printHeader()
+		}
+
+		row := []string{
+			item.NoOrder,
+			strings.ToUpper(string(item.Priority)),
+			item.TestCode,
+			item.TestName,
+			item.Instrument,
+			string(item.Status),
+			patientName(item.Patient.FirstName, item.Patient.LastName),
+			item.Patient.Birthdate.Format("2006-01-02"),
+			string(item.Patient.Sex),
+			item.OrderedAt.Format("2006-01-02 15:04"),
+		}
+
+		for i, col := range columns {
+			pdf.CellFormat(col.width, 7, tr(truncate(pdf, row[i], col.width-2)), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to write worklist pdf: %w", err)
+	}
+
+	return nil
+}
+
+// csvCell prefixes value with a quote when it starts with a character
+// that makes spreadsheet applications read the cell as a formula, so that
+// a name such as =HYPERLINK(...) is shown as text instead of run.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func patientName(firstName, lastName string) string {
+	return strings.TrimSpace(firstName + " " + lastName)
+}
+
+// truncate shortens text so that it fits in the given cell width.
+func truncate(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+func TestWriteWorklistCSVEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		firstName string
+		want      string
+	}{
+		{"Siti", "Siti Rahayu"},
+		{`=HYPERLINK("http://example.com")`, `'=HYPERLINK("http://example.com") Rahayu`},
+		{"+62812", "'+62812 Rahayu"},
+		{"-1+1", "'-1+1 Rahayu"},
+		{"@SUM(A1)", "'@SUM(A1) Rahayu"},
+		{"\t=1", "'=1 Rahayu"},
+		{"Nur=1", "Nur=1 Rahayu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.firstName, func(t *testing.T) {
+			item := &dto.WorklistItemResponse{
+				NoOrder:   "WO1",
+				TestCode:  "GLU",
+				Patient:   dto.WorklistPatientInfo{ID: "p-1", FirstName: tt.firstName, LastName: "Rahayu", Birthdate: time.Date(1975, 6, 30, 0, 0, 0, 0, time.UTC)},
+				OrderedAt: time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC),
+			}
+
+			var buf bytes.Buffer
+			if err := WriteWorklistCSV(&buf, []*dto.WorklistItemResponse{item}); err != nil {
+				t.Fatal(err)
+			}
+
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := records[1][8]; got != tt.want {
+				t.Errorf("patient name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, key *entitiy.APIKey) error
+	GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.APIKey, error)
+	GetByKeyHash(ctx context.Context, tx *sql.Tx, hash string) (*entitiy.APIKey, error)
+	Update(ctx context.Context, tx *sql.Tx, key *entitiy.APIKey) error
+	TouchLastUsed(ctx context.Context, tx *sql.Tx, id string, at time.Time) error
+	GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.APIKey, error)
+}
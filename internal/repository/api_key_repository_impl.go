@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type APIKeyRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+func NewAPIKeyRepository(db *sql.DB, d dialect.Dialect) APIKeyRepository {
+	return &APIKeyRepositoryImpl{dialect: d}
+}
+
+const apiKeyColumns = `id, name, key_prefix, key_hash, scopes, COALESCE(created_by, ''), expires_at, last_used_at, revoked_at, created_at, updated_at`
+
+func (r *APIKeyRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, key *entitiy.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, created_by, expires_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?)
+	`
+
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		strings.Join(key.Scopes, ","),
+		key.CreatedBy,
+		key.ExpiresAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepositoryImpl) GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+
+	return r.scanOne(tx.QueryRowContext(ctx, r.dialect.Rebind(query), id))
+}
+
+func (r *APIKeyRepositoryImpl) GetByKeyHash(ctx context.Context, tx *sql.Tx, hash string) (*entitiy.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+
+	return r.scanOne(tx.QueryRowContext(ctx, r.dialect.Rebind(query), hash))
+}
+
+// Update stores the name, key and revocation of an API key. Scopes are
+// fixed at creation.
+func (r *APIKeyRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, key *entitiy.APIKey) error {
+	query := `
+		UPDATE api_keys
+		SET name = ?, key_prefix = ?, key_hash = ?, expires_at = ?, revoked_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.ExpiresAt,
+		key.RevokedAt,
+		key.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apperror.NotFound("API key not found")
+	}
+
+	return nil
+}
+
+// TouchLastUsed records when a key was last used without counting as a
+// change of the key.
+func (r *APIKeyRepositoryImpl) TouchLastUsed(ctx context.Context, tx *sql.Tx, id string, at time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = ?, updated_at = updated_at WHERE id = ?`
+
+	if _, err := tx.ExecContext(ctx, r.dialect.Rebind(query), at, id); err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*entitiy.APIKey
+	for rows.Next() {
+		key, err := r.scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepositoryImpl) scanOne(row *sql.Row) (*entitiy.APIKey, error) {
+	key, err := r.scan(row)
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("API key not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepositoryImpl) scan(row interface{ Scan(...interface{}) error }) (*entitiy.APIKey, error) {
+	key := &entitiy.APIKey{}
+	var scopes string
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.CreatedBy,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// AuditLogRepository is append-only: entries can be added and queried but
+// never changed.
+type AuditLogRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, entry *entitiy.AuditLog) error
+	CreateBatch(ctx context.Context, tx *sql.Tx, entries []*entitiy.AuditLog) error
+	Find(ctx context.Context, tx *sql.Tx, filter entitiy.AuditFilter) ([]*entitiy.AuditLog, error)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type AuditLogRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+func NewAuditLogRepository(db *sql.DB, d dialect.Dialect) AuditLogRepository {
+	return &AuditLogRepositoryImpl{dialect: d}
+}
+
+const (
+	auditLogInsert = `
+		INSERT INTO audit_logs (occurred_at, user_id, username, action, entity_type, entity_id, patient_id, changes, client_ip, request_id)
+		VALUES `
+	auditLogValues = `(?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''))`
+	// auditLogColumns is the number of values bound per entry.
+	auditLogColumns = 10
+)
+
+func (r *AuditLogRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, entry *entitiy.AuditLog) error {
+	id, err := r.dialect.InsertID(ctx, tx, auditLogInsert+auditLogValues, auditLogArgs(entry)...)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	entry.ID = id
+
+	return nil
+}
+
+// CreateBatch writes entries with one multi-row INSERT per batch, for
+// operations that record an entry per row they list. The IDs of the new
+// entries are not set.
+func (r *AuditLogRepositoryImpl) CreateBatch(ctx context.Context, tx *sql.Tx, entries []*entitiy.AuditLog) error {
+	rowsPerBatch := maxBatch / auditLogColumns
+
+	for len(entries) > 0 {
+		batch := entries
+		if len(batch) > rowsPerBatch {
+			batch = batch[:rowsPerBatch]
+		}
+		entries = entries[len(batch):]
+
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*auditLogColumns)
+		for i, entry := range batch {
+			values[i] = auditLogValues
+			args = append(args, auditLogArgs(entry)...)
+		}
+
+		query := auditLogInsert + strings.Join(values, ", ")
+		if _, err := tx.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func auditLogArgs(entry *entitiy.AuditLog) []interface{} {
+	return []interface{}{
+		entry.OccurredAt,
+		entry.UserID,
+		entry.Username,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		entry.PatientID,
+		entry.Changes,
+		entry.ClientIP,
+		entry.RequestID,
+	}
+}
+
+func (r *AuditLogRepositoryImpl) Find(ctx context.Context, tx *sql.Tx, filter entitiy.AuditFilter) ([]*entitiy.AuditLog, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.PatientID != "" {
+		conditions = append(conditions, "patient_id = ?")
+		args = append(args, filter.PatientID)
+	}
+
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+
+	if filter.EntityID != "" {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "occurred_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	query := `
+		SELECT id, occurred_at, COALESCE(user_id, ''), COALESCE(username, ''), action, entity_type, entity_id,
+			COALESCE(patient_id, ''), COALESCE(changes, ''), COALESCE(client_ip, ''), COALESCE(request_id, '')
+		FROM audit_logs
+	`
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY occurred_at DESC, id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*entitiy.AuditLog
+
+	for rows.Next() {
+		entry := &entitiy.AuditLog{}
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OccurredAt,
+			&entry.UserID,
+			&entry.Username,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.PatientID,
+			&entry.Changes,
+			&entry.ClientIP,
+			&entry.RequestID,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+func auditViews(patientID string, n int) []*entitiy.AuditLog {
+	entries := make([]*entitiy.AuditLog, n)
+	for i := range entries {
+		entries[i] = &entitiy.AuditLog{
+			OccurredAt: time.Now(),
+			Username:   "analyst",
+			Action:     entitiy.AuditView,
+			EntityType: entitiy.AuditEntityWorkOrder,
+			EntityID:   fmt.Sprintf("WO%d", i),
+			PatientID:  patientID,
+			RequestID:  "req-1",
+		}
+	}
+	return entries
+}
+
+func TestAuditLogRepositoryCreateBatch(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			auditLogs := NewAuditLogRepository(database.db, database.dialect)
+			rowsPerBatch := maxBatch / auditLogColumns
+
+			for _, n := range []int{0, 1, rowsPerBatch, 2*rowsPerBatch + 1} {
+				t.Run(fmt.Sprint(n), func(t *testing.T) {
+					patientID := uniqueKey("p-")
+
+					var found []*entitiy.AuditLog
+					err := inTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+						if err := auditLogs.CreateBatch(ctx, tx, auditViews(patientID, n)); err != nil {
+							return err
+						}
+
+						var err error
+						found, err = auditLogs.Find(ctx, tx, entitiy.AuditFilter{PatientID: patientID, Limit: 1000})
+						return err
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if len(found) != n {
+						t.Fatalf("found %d entries, want %d", len(found), n)
+					}
+					for _, entry := range found {
+						if entry.Action != entitiy.AuditView || entry.Username != "analyst" || entry.UserID != "" || entry.RequestID != "req-1" {
+							t.Fatalf("entry = %+v", entry)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkAuditLogViews compares recording the views of a listed page
+// one insert at a time with recording them in a batch.
+func BenchmarkAuditLogViews(b *testing.B) {
+	const pageSize = 100
+
+	for _, database := range testDatabases(b) {
+		auditLogs := NewAuditLogRepository(database.db, database.dialect)
+
+		b.Run(database.name+"/one by one", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := inTx(b, database.db, func(ctx context.Context, tx *sql.Tx) error {
+					for _, entry := range auditViews("p-1", pageSize) {
+						if err := auditLogs.Create(ctx, tx, entry); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(database.name+"/batch", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := inTx(b, database.db, func(ctx context.Context, tx *sql.Tx) error {
+					return auditLogs.CreateBatch(ctx, tx, auditViews("p-1", pageSize))
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
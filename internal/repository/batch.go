@@ -0,0 +1,34 @@
+package repository
+
+import "strings"
+
+// maxBatch caps the number of values bound to one IN list, well below the
+// placeholder limits of MySQL, PostgreSQL and SQLite.
+const maxBatch = 500
+
+// placeholders returns n comma-separated ? placeholders for an IN list.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// batches splits values into consecutive slices of at most maxBatch values.
+func batches(values []string) [][]string {
+	var out [][]string
+	for len(values) > maxBatch {
+		out = append(out, values[:maxBatch])
+		values = values[maxBatch:]
+	}
+	if len(values) > 0 {
+		out = append(out, values)
+	}
+	return out
+}
+
+// stringArgs converts values into query arguments.
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
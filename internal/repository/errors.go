@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+)
+
+// constraintError turns a constraint violation reported for a write of
+// what (e.g. "work order WO001") into a conflict or validation error.
+// Other errors are returned unchanged.
+func constraintError(err error, what string) error {
+	switch dialect.ViolationOf(err) {
+	case dialect.Duplicate:
+		return apperror.Conflict("%s already exists", what)
+	case dialect.Referenced:
+		return apperror.Conflict("%s is still referenced by other records", what)
+	case dialect.MissingReference:
+		return apperror.Invalid("%s refers to a record that does not exist", what)
+	default:
+		return err
+	}
+}
+
+// deleteError is constraintError for a delete of what. A foreign key
+// violation on delete always means the row is still referenced; SQLite
+// reports it without saying which side of the key failed.
+func deleteError(err error, what string) error {
+	switch dialect.ViolationOf(err) {
+	case dialect.Referenced, dialect.MissingReference:
+		return apperror.Conflict("%s is still referenced by other records", what)
+	default:
+		return err
+	}
+}
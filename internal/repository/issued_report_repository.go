@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type IssuedReportRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, report *entitiy.IssuedReport) error
+	GetByVerificationCode(ctx context.Context, tx *sql.Tx, code string) (*entitiy.IssuedReport, error)
+	GetLatestByNoOrder(ctx context.Context, tx *sql.Tx, noOrder string) (*entitiy.IssuedReport, error)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type IssuedReportRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+var errIssuedReportNotFound = errors.New("issued report not found")
+
+func NewIssuedReportRepository(db *sql.DB, d dialect.Dialect) IssuedReportRepository {
+	return &IssuedReportRepositoryImpl{dialect: d}
+}
+
+func (r *IssuedReportRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, report *entitiy.IssuedReport) error {
+	query := `
+		INSERT INTO issued_reports (no_order, verification_code, content_hash, status, issued_at, content)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertID(ctx, tx, query,
+		report.NoOrder,
+		report.VerificationCode,
+		report.ContentHash,
+		report.Status,
+		report.IssuedAt,
+		report.Content,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create issued report: %w", err)
+	}
+
+	report.ID = id
+
+	return nil
+}
+
+func (r *IssuedReportRepositoryImpl) GetByVerificationCode(ctx context.Context, tx *sql.Tx, code string) (*entitiy.IssuedReport, error) {
+	query := `
+		SELECT id, no_order, verification_code, content_hash, status, issued_at, COALESCE(content, '')
+		FROM issued_reports
+		WHERE verification_code = ?
+	`
+
+	report, err := r.scanOne(tx.QueryRowContext(ctx, r.dialect.Rebind(query), code))
+	if err == errIssuedReportNotFound {
+		return nil, apperror.NotFound("No report was issued with this verification code")
+	}
+
+	return report, err
+}
+
+// GetLatestByNoOrder returns the most recently issued report of a work
+// order, or nil when no report has been issued yet.
+func (r *IssuedReportRepositoryImpl) GetLatestByNoOrder(ctx context.Context, tx *sql.Tx, noOrder string) (*entitiy.IssuedReport, error) {
+	query := `
+		SELECT id, no_order, verification_code, content_hash, status, issued_at, COALESCE(content, '')
+		FROM issued_reports
+		WHERE no_order = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	report, err := r.scanOne(tx.QueryRowContext(ctx, r.dialect.Rebind(query), noOrder))
+	if err == errIssuedReportNotFound {
+		return nil, nil
+	}
+
+	return report, err
+}
+
+func (r *IssuedReportRepositoryImpl) scanOne(row *sql.Row) (*entitiy.IssuedReport, error) {
+	report := &entitiy.IssuedReport{}
+
+	err := row.Scan(
+		&report.ID,
+		&report.NoOrder,
+		&report.VerificationCode,
+		&report.ContentHash,
+		&report.Status,
+		&report.IssuedAt,
+		&report.Content,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errIssuedReportNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issued report: %w", err)
+	}
+
+	return report, nil
+}
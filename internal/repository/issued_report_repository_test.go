@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+func TestIssuedReportRepository(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			patients := NewPatientRepository(database.db, database.dialect, testKeyring(t))
+			workOrders := NewWorkOrderRepository(database.db, database.dialect)
+			reports := NewIssuedReportRepository(database.db, database.dialect)
+
+			patient := &entitiy.Patient{ID: uniqueKey("p-"), FirstName: "Budi", Birthdate: time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC), Sex: entitiy.Male}
+			workOrder := &entitiy.WorkOrder{NoOrder: uniqueKey("WO-"), PatientID: patient.ID, Priority: entitiy.PriorityRoutine}
+			first := &entitiy.IssuedReport{NoOrder: workOrder.NoOrder, VerificationCode: uniqueKey("A"), ContentHash: "hash-1", Status: "preliminary", IssuedAt: time.Now(), Content: `{"results":[]}`}
+			second := &entitiy.IssuedReport{NoOrder: workOrder.NoOrder, VerificationCode: uniqueKey("B"), ContentHash: "hash-2", Status: "final", IssuedAt: time.Now()}
+
+			mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+				if err := patients.Create(ctx, tx, patient); err != nil {
+					return err
+				}
+				if err := workOrders.Create(ctx, tx, workOrder); err != nil {
+					return err
+				}
+				if err := reports.Create(ctx, tx, first); err != nil {
+					return err
+				}
+				return reports.Create(ctx, tx, second)
+			})
+
+			if first.ID == 0 || second.ID <= first.ID {
+				t.Fatalf("ids = %d, %d, want increasing generated ids", first.ID, second.ID)
+			}
+
+			tests := []struct {
+				name string
+				get  func(ctx context.Context, tx *sql.Tx) (*entitiy.IssuedReport, error)
+				want *entitiy.IssuedReport
+				err  error
+			}{
+				{"by code", func(ctx context.Context, tx *sql.Tx) (*entitiy.IssuedReport, error) {
+					return reports.GetByVerificationCode(ctx, tx, first.VerificationCode)
+				}, first, nil},
+				{"by unknown code", func(ctx context.Context, tx *sql.Tx) (*entitiy.IssuedReport, error) {
+					return reports.GetByVerificationCode(ctx, tx, uniqueKey("X"))
+				}, nil, apperror.ErrNotFound},
+				{"latest", func(ctx context.Context, tx *sql.Tx) (*entitiy.IssuedReport, error) {
+					return reports.GetLatestByNoOrder(ctx, tx, workOrder.NoOrder)
+				}, second, nil},
+				{"latest of an order never issued", func(ctx context.Context, tx *sql.Tx) (*entitiy.IssuedReport, error) {
+					return reports.GetLatestByNoOrder(ctx, tx, uniqueKey("WO-"))
+				}, nil, nil},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					var got *entitiy.IssuedReport
+					err := inTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+						var err error
+						got, err = tt.get(ctx, tx)
+						return err
+					})
+
+					if tt.err != nil || err != nil {
+						if !errors.Is(err, tt.err) {
+							t.Fatalf("err = %v, want %v", err, tt.err)
+						}
+						return
+					}
+
+					if tt.want == nil {
+						if got != nil {
+							t.Fatalf("got %+v, want nil", got)
+						}
+						return
+					}
+
+					if got.ID != tt.want.ID || got.VerificationCode != tt.want.VerificationCode || got.ContentHash != tt.want.ContentHash || got.Status != tt.want.Status || got.Content != tt.want.Content {
+						t.Errorf("got %+v, want %+v", got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
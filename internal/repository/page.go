@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// pageClause returns the ORDER BY and LIMIT clause of a page. columns maps
+// the sort field names to SQL expressions; tieBreaker is appended so that
+// rows with equal sort values keep a stable order across pages.
+func pageClause(columns map[string]string, page entitiy.Page, tieBreaker string) (string, []interface{}, error) {
+	column, ok := columns[page.Sort]
+	if !ok {
+		return "", nil, fmt.Errorf("cannot sort by %q", page.Sort)
+	}
+
+	direction := "ASC"
+	if page.Desc {
+		direction = "DESC"
+	}
+
+	clause := fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT ? OFFSET ?", column, direction, tieBreaker, direction)
+
+	return clause, []interface{}{page.Limit, page.Offset}, nil
+}
@@ -5,13 +5,18 @@ import (
 	"database/sql"
 
 	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/search"
 )
 
 type PatientRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, patient *entitiy.Patient) error
 	GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.Patient, error)
+	GetByIDs(ctx context.Context, tx *sql.Tx, ids []string) (map[string]*entitiy.Patient, error)
+	Lock(ctx context.Context, tx *sql.Tx, id string) error
 	Update(ctx context.Context, tx *sql.Tx, patient *entitiy.Patient) error
 	Delete(ctx context.Context, tx *sql.Tx, id string) error
-	GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.Patient, error)
-	Search(ctx context.Context, tx *sql.Tx, query string) ([]*entitiy.Patient, error)
+	GetAll(ctx context.Context, tx *sql.Tx, filter entitiy.PatientFilter) ([]*entitiy.Patient, int, error)
+	Search(ctx context.Context, tx *sql.Tx, query search.Query, limit int) ([]*entitiy.Patient, error)
+	ReencryptBatch(ctx context.Context, tx *sql.Tx, afterID string, limit int) (string, int, int, error)
+	ReindexBatch(ctx context.Context, tx *sql.Tx, afterID string, limit int) (string, int, error)
 }
@@ -4,64 +4,89 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+	"github.com/BioSystems-Indonesia/lis/internal/search"
 )
 
-type PatientRepositoryImpl struct{}
+// PatientRepositoryImpl stores address, phone, email and national ID
+// encrypted. Phone, email and national ID also get a blind index so they
+// can be looked up by exact value.
+type PatientRepositoryImpl struct {
+	dialect dialect.Dialect
+	keyring *fieldcrypt.Keyring
+}
+
+func NewPatientRepository(db *sql.DB, d dialect.Dialect, keyring *fieldcrypt.Keyring) PatientRepository {
+	return &PatientRepositoryImpl{
+		dialect: d,
+		keyring: keyring,
+	}
+}
+
+const patientColumns = `id, first_name, last_name, birthdate, sex, COALESCE(address, ''), COALESCE(phone, ''), COALESCE(email, ''), COALESCE(national_id, ''), created_at, updated_at`
+
+// Blind index kinds, so equal values of different fields hash differently.
+const (
+	bidxPhone      = "patients.phone"
+	bidxEmail      = "patients.email"
+	bidxNationalID = "patients.national_id"
+)
 
-func NewPatientRepository(db *sql.DB) PatientRepository {
-	return &PatientRepositoryImpl{}
+// sealedPatient holds the encrypted columns and blind indexes of a patient.
+type sealedPatient struct {
+	Address        string
+	Phone          string
+	Email          string
+	NationalID     string
+	PhoneBidx      string
+	EmailBidx      string
+	NationalIDBidx string
 }
 
 func (r *PatientRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, patient *entitiy.Patient) error {
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO patients (id, first_name, last_name, birthdate, sex, address, phone, email)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO patients (id, first_name, last_name, birthdate, sex, address, phone, email, national_id, phone_bidx, email_bidx, national_id_bidx)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''))
 	`
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, r.dialect.Rebind(query),
 		patient.ID,
 		patient.FirstName,
 		patient.LastName,
 		patient.Birthdate.Format("2006-01-02"),
 		patient.Sex,
-		patient.Address,
-		patient.Phone,
-		patient.Email,
+		sealed.Address,
+		sealed.Phone,
+		sealed.Email,
+		sealed.NationalID,
+		sealed.PhoneBidx,
+		sealed.EmailBidx,
+		sealed.NationalIDBidx,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to create patient: %w", err)
+		return fmt.Errorf("failed to create patient: %w", constraintError(err, "patient"))
 	}
 
-	return nil
+	return r.indexName(ctx, tx, patient.ID, patient.FirstName, patient.LastName)
 }
 
 func (r *PatientRepositoryImpl) GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.Patient, error) {
-	query := `
-		SELECT id, first_name, last_name, birthdate, sex, address, phone, email, created_at, updated_at
-		FROM patients
-		WHERE id = ?
-	`
-
-	patient := &entitiy.Patient{}
-
-	err := tx.QueryRowContext(ctx, query, id).Scan(
-		&patient.ID,
-		&patient.FirstName,
-		&patient.LastName,
-		&patient.Birthdate,
-		&patient.Sex,
-		&patient.Address,
-		&patient.Phone,
-		&patient.Email,
-		&patient.CreatedAt,
-		&patient.UpdatedAt,
-	)
+	query := `SELECT ` + patientColumns + ` FROM patients WHERE id = ?`
 
+	patient, err := r.scan(tx.QueryRowContext(ctx, r.dialect.Rebind(query), id))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("patient not found")
+		return nil, apperror.NotFound("patient not found")
 	}
 
 	if err != nil {
@@ -71,25 +96,81 @@ func (r *PatientRepositoryImpl) GetByID(ctx context.Context, tx *sql.Tx, id stri
 	return patient, nil
 }
 
+// GetByIDs returns the patients with the given IDs, keyed by ID. IDs that
+// match no patient are left out of the result.
+func (r *PatientRepositoryImpl) GetByIDs(ctx context.Context, tx *sql.Tx, ids []string) (map[string]*entitiy.Patient, error) {
+	patients := make(map[string]*entitiy.Patient, len(ids))
+
+	for _, batch := range batches(ids) {
+		query := `SELECT ` + patientColumns + ` FROM patients WHERE id IN (` + placeholders(len(batch)) + `)`
+
+		rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), stringArgs(batch)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get patients: %w", err)
+		}
+
+		found, err := r.scanAll(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, patient := range found {
+			patients[patient.ID] = patient
+		}
+	}
+
+	return patients, nil
+}
+
+// Lock locks a patient row until the end of the transaction, so that a
+// read-check-write sequence cannot interleave with another writer.
+func (r *PatientRepositoryImpl) Lock(ctx context.Context, tx *sql.Tx, id string) error {
+	var locked string
+
+	err := tx.QueryRowContext(ctx, r.dialect.Rebind(`SELECT id FROM patients WHERE id = ?`+r.dialect.ForUpdate()), id).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("patient not found")
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to lock patient: %w", err)
+	}
+
+	return nil
+}
+
 func (r *PatientRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, patient *entitiy.Patient) error {
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE patients
-		SET first_name = ?, birthdate = ?, sex = ?, address = ?, phone = ?, email = ?
+		SET first_name = ?, last_name = ?, birthdate = ?, sex = ?, address = ?, phone = ?, email = ?, national_id = ?,
+			phone_bidx = NULLIF(?, ''), email_bidx = NULLIF(?, ''), national_id_bidx = NULLIF(?, ''),
+			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
-	result, err := tx.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
 		patient.FirstName,
+		patient.LastName,
 		patient.Birthdate.Format("2006-01-02"),
 		patient.Sex,
-		patient.Address,
-		patient.Phone,
-		patient.Email,
+		sealed.Address,
+		sealed.Phone,
+		sealed.Email,
+		sealed.NationalID,
+		sealed.PhoneBidx,
+		sealed.EmailBidx,
+		sealed.NationalIDBidx,
 		patient.ID,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update patient: %w", err)
+		return fmt.Errorf("failed to update patient: %w", constraintError(err, "patient"))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -98,18 +179,18 @@ func (r *PatientRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, patient
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("patient not found")
+		return apperror.NotFound("patient not found")
 	}
 
-	return nil
+	return r.indexName(ctx, tx, patient.ID, patient.FirstName, patient.LastName)
 }
 
 func (r *PatientRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id string) error {
-	query := `DELETE FROM patients WHERE`
+	query := `DELETE FROM patients WHERE id = ?`
 
-	result, err := tx.ExecContext(ctx, query)
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query), id)
 	if err != nil {
-		return fmt.Errorf("failed to delete patient: %w", err)
+		return fmt.Errorf("failed to delete patient: %w", deleteError(err, "patient"))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -118,88 +199,435 @@ func (r *PatientRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id strin
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("patient not found")
+		return apperror.NotFound("patient not found")
 	}
 
 	return nil
 }
 
-func (r *PatientRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.Patient, error) {
+// patientSortColumns maps the sort fields of dto.PatientSortFields to
+// columns.
+var patientSortColumns = map[string]string{
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"birth_date": "birthdate",
+	"created_at": "created_at",
+}
+
+// GetAll returns one page of the patients matching filter and the number
+// of matching patients.
+func (r *PatientRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx, filter entitiy.PatientFilter) ([]*entitiy.Patient, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Sex != "" {
+		conditions = append(conditions, "sex = ?")
+		args = append(args, filter.Sex)
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, r.dialect.Rebind(`SELECT COUNT(*) FROM patients`+where), args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count patients: %w", err)
+	}
+
+	order, orderArgs, err := pageClause(patientSortColumns, filter.Page, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + patientColumns + ` FROM patients` + where + order
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), append(args, orderArgs...)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get patients: %w", err)
+	}
+	defer rows.Close()
+
+	patients, err := r.scanAll(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return patients, total, nil
+}
+
+// Search returns up to limit candidate matches of query, closest first:
+// patients that may match every term, and patients whose ID, phone, email
+// or national ID is the whole search text. Names are looked up in
+// patient_name_tokens by token prefix and phonetic key; the candidates
+// still have to be scored with search.Score.
+func (r *PatientRepositoryImpl) Search(ctx context.Context, tx *sql.Tx, query search.Query, limit int) ([]*entitiy.Patient, error) {
+	var terms []string
+	var args []interface{}
+
+	for _, name := range query.Names {
+		key := search.Key(name)
+		terms = append(terms, "id IN (SELECT patient_id FROM patient_name_tokens WHERE token LIKE ? OR name_key = ? OR name_key LIKE ?)")
+		args = append(args, name+"%", key, search.KeyPrefix(key)+"%")
+	}
+
+	for _, date := range query.Birthdates {
+		terms = append(terms, "birthdate = ?")
+		args = append(args, date.Format("2006-01-02"))
+	}
+
+	for _, identifier := range query.Identifiers {
+		terms = append(terms, "(id = ? OR phone_bidx = ? OR national_id_bidx = ?)")
+		args = append(args,
+			identifier,
+			r.keyring.BlindIndex(bidxPhone, fieldcrypt.NormalizePhone(identifier)),
+			r.keyring.BlindIndex(bidxNationalID, fieldcrypt.NormalizeNationalID(identifier)),
+		)
+	}
+
+	for _, email := range query.Emails {
+		terms = append(terms, "email_bidx = ?")
+		args = append(args, r.keyring.BlindIndex(bidxEmail, fieldcrypt.NormalizeEmail(email)))
+	}
+
+	exact := "id = ? OR phone_bidx = ? OR email_bidx = ? OR national_id_bidx = ?"
+	exactArgs := []interface{}{
+		query.Text,
+		r.keyring.BlindIndex(bidxPhone, fieldcrypt.NormalizePhone(query.Text)),
+		r.keyring.BlindIndex(bidxEmail, fieldcrypt.NormalizeEmail(query.Text)),
+		r.keyring.BlindIndex(bidxNationalID, fieldcrypt.NormalizeNationalID(query.Text)),
+	}
+
+	condition := exact
+	if len(terms) > 0 {
+		condition = "(" + strings.Join(terms, " AND ") + ") OR " + exact
+	}
+	args = append(args, exactArgs...)
+
+	// Candidates are read best first, so that the limit drops the weakest
+	// ones: exact matches of the whole text, then by how closely the names
+	// match each name term, exactly, by prefix or by phonetic key.
+	order := []string{"CASE WHEN " + exact + " THEN 0 ELSE 1 END"}
+	args = append(args, exactArgs...)
+
+	if len(query.Names) > 0 {
+		nameRanks := make([]string, len(query.Names))
+		for i, name := range query.Names {
+			nameRanks[i] = `COALESCE((
+				SELECT MIN(CASE WHEN token = ? THEN 0 WHEN token LIKE ? THEN 1 WHEN name_key = ? THEN 2 ELSE 3 END)
+				FROM patient_name_tokens
+				WHERE patient_id = patients.id
+			), 3)`
+			args = append(args, name, name+"%", search.Key(name))
+		}
+		order = append(order, strings.Join(nameRanks, " + "))
+	}
+	order = append(order, "id")
+	args = append(args, limit)
+
+	searchQuery := `SELECT ` + patientColumns + ` FROM patients WHERE ` + condition + ` ORDER BY ` + strings.Join(order, ", ") + ` LIMIT ?`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(searchQuery), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search patients: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanAll(rows)
+}
+
+// ReindexBatch rewrites the name tokens of up to limit patients with an ID
+// greater than afterID. It returns the last ID read and how many patients
+// were read.
+func (r *PatientRepositoryImpl) ReindexBatch(ctx context.Context, tx *sql.Tx, afterID string, limit int) (string, int, error) {
 	query := `
-		SELECT id, first_name, last_name, birthdate, sex, address, phone, email, 
+		SELECT id, first_name, last_name
 		FROM patients
-		ORDER BY first_name, last_name
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
 	`
 
-	rows, err := tx.QueryContext(ctx, query)
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), afterID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get patients: %w", err)
+		return afterID, 0, fmt.Errorf("failed to read patients: %w", err)
 	}
-	defer rows.Close()
 
-	var patients []*entitiy.Patient
+	type patientName struct {
+		id, firstName, lastName string
+	}
 
+	var batch []patientName
 	for rows.Next() {
-		patient := &entitiy.Patient{}
-
-		err := rows.Scan(
-			&patient.ID,
-			&patient.FirstName,
-			&patient.LastName,
-			&patient.Birthdate,
-			&patient.Address,
-			&patient.Phone,
-			&patient.Email,
+		var p patientName
+		if err := rows.Scan(&p.id, &p.firstName, &p.lastName); err != nil {
+			rows.Close()
+			return afterID, 0, fmt.Errorf("failed to scan patient: %w", err)
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return afterID, 0, fmt.Errorf("error iterating patients: %w", err)
+	}
+
+	lastID := afterID
+	for _, p := range batch {
+		if err := r.indexName(ctx, tx, p.id, p.firstName, p.lastName); err != nil {
+			return lastID, len(batch), err
+		}
+		lastID = p.id
+	}
+
+	return lastID, len(batch), nil
+}
+
+// ReencryptBatch re-seals up to limit patients with an ID greater than
+// afterID: plaintext columns are encrypted, values under an older key are
+// rewrapped under the active key and stale blind indexes are recomputed.
+// It returns the last ID read, how many rows were read and how many were
+// rewritten.
+func (r *PatientRepositoryImpl) ReencryptBatch(ctx context.Context, tx *sql.Tx, afterID string, limit int) (string, int, int, error) {
+	query := `
+		SELECT id, COALESCE(address, ''), COALESCE(phone, ''), COALESCE(email, ''), COALESCE(national_id, ''),
+			COALESCE(phone_bidx, ''), COALESCE(email_bidx, ''), COALESCE(national_id_bidx, '')
+		FROM patients
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
+	`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), afterID, limit)
+	if err != nil {
+		return afterID, 0, 0, fmt.Errorf("failed to read patients: %w", err)
+	}
+
+	type storedPatient struct {
+		id     string
+		stored sealedPatient
+	}
+
+	var batch []storedPatient
+	for rows.Next() {
+		var p storedPatient
+		err := rows.Scan(&p.id, &p.stored.Address, &p.stored.Phone, &p.stored.Email, &p.stored.NationalID,
+			&p.stored.PhoneBidx, &p.stored.EmailBidx, &p.stored.NationalIDBidx)
+		if err != nil {
+			rows.Close()
+			return afterID, 0, 0, fmt.Errorf("failed to scan patient: %w", err)
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return afterID, 0, 0, fmt.Errorf("error iterating patients: %w", err)
+	}
+
+	lastID := afterID
+	updated := 0
+
+	for _, p := range batch {
+		lastID = p.id
+
+		resealed, changed, err := r.reseal(p.id, p.stored)
+		if err != nil {
+			return lastID, len(batch), updated, fmt.Errorf("patient %s: %w", p.id, err)
+		}
+
+		if !changed {
+			continue
+		}
+
+		// updated_at is kept: re-encryption is not a change of the record.
+		updateQuery := `
+			UPDATE patients
+			SET address = ?, phone = ?, email = ?, national_id = ?,
+				phone_bidx = NULLIF(?, ''), email_bidx = NULLIF(?, ''), national_id_bidx = NULLIF(?, ''),
+				updated_at = updated_at
+			WHERE id = ?
+		`
+
+		_, err = tx.ExecContext(ctx, r.dialect.Rebind(updateQuery),
+			resealed.Address,
+			resealed.Phone,
+			resealed.Email,
+			resealed.NationalID,
+			resealed.PhoneBidx,
+			resealed.EmailBidx,
+			resealed.NationalIDBidx,
+			p.id,
 		)
+		if err != nil {
+			return lastID, len(batch), updated, fmt.Errorf("failed to re-encrypt patient %s: %w", p.id, err)
+		}
+
+		updated++
+	}
+
+	return lastID, len(batch), updated, nil
+}
+
+// indexName replaces the name tokens of a patient in patient_name_tokens.
+func (r *PatientRepositoryImpl) indexName(ctx context.Context, tx *sql.Tx, id, firstName, lastName string) error {
+	if _, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM patient_name_tokens WHERE patient_id = ?`), id); err != nil {
+		return fmt.Errorf("failed to delete patient name tokens: %w", err)
+	}
+
+	tokens := search.NameTokens(firstName, lastName)
+	if len(tokens) == 0 {
+		return nil
+	}
 
+	values := make([]string, len(tokens))
+	args := make([]interface{}, 0, 3*len(tokens))
+	for i, token := range tokens {
+		values[i] = "(?, ?, ?)"
+		args = append(args, id, token, search.Key(token))
+	}
+
+	query := `INSERT INTO patient_name_tokens (patient_id, token, name_key) VALUES ` + strings.Join(values, ", ")
+
+	if _, err := tx.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to index patient name: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PatientRepositoryImpl) seal(patient *entitiy.Patient) (*sealedPatient, error) {
+	sealed := &sealedPatient{
+		PhoneBidx:      r.keyring.BlindIndex(bidxPhone, fieldcrypt.NormalizePhone(patient.Phone)),
+		EmailBidx:      r.keyring.BlindIndex(bidxEmail, fieldcrypt.NormalizeEmail(patient.Email)),
+		NationalIDBidx: r.keyring.BlindIndex(bidxNationalID, fieldcrypt.NormalizeNationalID(patient.NationalID)),
+	}
+
+	fields := []struct {
+		column string
+		value  string
+		target *string
+	}{
+		{"address", patient.Address, &sealed.Address},
+		{"phone", patient.Phone, &sealed.Phone},
+		{"email", patient.Email, &sealed.Email},
+		{"national_id", patient.NationalID, &sealed.NationalID},
+	}
+
+	for _, field := range fields {
+		value, err := r.keyring.Encrypt(field.value, patientAAD(field.column, patient.ID))
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan patient: %w", err)
+			return nil, fmt.Errorf("failed to encrypt patient %s: %w", field.column, err)
 		}
+		*field.target = value
+	}
 
-		patients = append(patients, patient)
+	return sealed, nil
+}
+
+func (r *PatientRepositoryImpl) reseal(id string, stored sealedPatient) (*sealedPatient, bool, error) {
+	resealed := stored
+	changed := false
+
+	fields := []struct {
+		column string
+		target *string
+	}{
+		{"address", &resealed.Address},
+		{"phone", &resealed.Phone},
+		{"email", &resealed.Email},
+		{"national_id", &resealed.NationalID},
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating patients: %w", err)
+	plaintext := make(map[string]string, len(fields))
+
+	for _, field := range fields {
+		aad := patientAAD(field.column, id)
+
+		value, err := r.keyring.Decrypt(*field.target, aad)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decrypt %s: %w", field.column, err)
+		}
+		plaintext[field.column] = value
+
+		sealed, fieldChanged, err := r.keyring.Reseal(*field.target, aad)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to re-encrypt %s: %w", field.column, err)
+		}
+
+		*field.target = sealed
+		changed = changed || fieldChanged
 	}
 
-	return patients, nil
+	resealed.PhoneBidx = r.keyring.BlindIndex(bidxPhone, fieldcrypt.NormalizePhone(plaintext["phone"]))
+	resealed.EmailBidx = r.keyring.BlindIndex(bidxEmail, fieldcrypt.NormalizeEmail(plaintext["email"]))
+	resealed.NationalIDBidx = r.keyring.BlindIndex(bidxNationalID, fieldcrypt.NormalizeNationalID(plaintext["national_id"]))
+
+	changed = changed ||
+		resealed.PhoneBidx != stored.PhoneBidx ||
+		resealed.EmailBidx != stored.EmailBidx ||
+		resealed.NationalIDBidx != stored.NationalIDBidx
+
+	return &resealed, changed, nil
 }
 
-func (r *PatientRepositoryImpl) Search(ctx context.Context, tx *sql.Tx, query string) ([]*entitiy.Patient, error) {
-	searchQuery := `
-		SELECT id, first_name, last_name, sex, address, phone, email, created_at, updated_at
-		FROM patients
-		WHERE first_name LIKE ? OR last_name LIKE ? OR phone LIKE ? OR email LIKE ?
-		ORDER BY first_name, last_name
-	`
+func (r *PatientRepositoryImpl) scan(row interface{ Scan(...interface{}) error }) (*entitiy.Patient, error) {
+	patient := &entitiy.Patient{}
 
-	searchPattern := "%" + query + "%"
+	err := row.Scan(
+		&patient.ID,
+		&patient.FirstName,
+		&patient.LastName,
+		&patient.Birthdate,
+		&patient.Sex,
+		&patient.Address,
+		&patient.Phone,
+		&patient.Email,
+		&patient.NationalID,
+		&patient.CreatedAt,
+		&patient.UpdatedAt,
+	)
 
-	rows, err := tx.QueryContext(ctx, searchQuery, searchPattern, searchPattern, searchPattern, searchPattern)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search patients: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
+	fields := []struct {
+		column string
+		target *string
+	}{
+		{"address", &patient.Address},
+		{"phone", &patient.Phone},
+		{"email", &patient.Email},
+		{"national_id", &patient.NationalID},
+	}
+
+	for _, field := range fields {
+		value, err := r.keyring.Decrypt(*field.target, patientAAD(field.column, patient.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt patient %s: %w", field.column, err)
+		}
+		*field.target = value
+	}
+
+	return patient, nil
+}
+
+func (r *PatientRepositoryImpl) scanAll(rows *sql.Rows) ([]*entitiy.Patient, error) {
 	var patients []*entitiy.Patient
 
 	for rows.Next() {
-		patient := &entitiy.Patient{}
-
-		err := rows.Scan(
-			&patient.ID,
-			&patient.FirstName,
-			&patient.LastName,
-			&patient.Birthdate,
-			&patient.Sex,
-			&patient.Address,
-			&patient.Phone,
-			&patient.Email,
-			&patient.CreatedAt,
-			&patient.UpdatedAt,
-		)
-
+		patient, err := r.scan(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan patient: %w", err)
 		}
@@ -207,9 +635,14 @@ func (r *PatientRepositoryImpl) Search(ctx context.Context, tx *sql.Tx, query st
 		patients = append(patients, patient)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating patients: %w", err)
 	}
 
 	return patients, nil
 }
+
+// patientAAD binds an encrypted value to its column and row.
+func patientAAD(column, id string) string {
+	return "patients." + column + ":" + id
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/search"
+)
+
+func TestPatientRepositoryEncryptsContactFields(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			patients := NewPatientRepository(database.db, database.dialect, testKeyring(t))
+
+			patient := &entitiy.Patient{
+				ID:         uniqueKey("p-"),
+				FirstName:  "Siti",
+				LastName:   "Rahayu",
+				Birthdate:  time.Date(1975, 6, 30, 0, 0, 0, 0, time.UTC),
+				Sex:        entitiy.Female,
+				Address:    "Jl. Merdeka 1",
+				Phone:      "081234567890",
+				Email:      "siti@example.com",
+				NationalID: "3201010101010001",
+			}
+			mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+				return patients.Create(ctx, tx, patient)
+			})
+
+			var stored [4]string
+			row := database.db.QueryRow(database.dialect.Rebind(`SELECT address, phone, email, national_id FROM patients WHERE id = ?`), patient.ID)
+			if err := row.Scan(&stored[0], &stored[1], &stored[2], &stored[3]); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, plain := range []string{patient.Address, patient.Phone, patient.Email, patient.NationalID} {
+				if strings.Contains(stored[i], plain) {
+					t.Errorf("column %d is stored in plain text: %q", i, stored[i])
+				}
+			}
+
+			tests := []struct {
+				name string
+				id   string
+				want *entitiy.Patient
+				err  error
+			}{
+				{"existing", patient.ID, patient, nil},
+				{"missing", uniqueKey("missing-"), nil, apperror.ErrNotFound},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					var got *entitiy.Patient
+					err := inTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+						var err error
+						got, err = patients.GetByID(ctx, tx, tt.id)
+						return err
+					})
+
+					if tt.err != nil {
+						if !errors.Is(err, tt.err) {
+							t.Fatalf("err = %v, want %v", err, tt.err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if got.Address != tt.want.Address || got.Phone != tt.want.Phone || got.Email != tt.want.Email || got.NationalID != tt.want.NationalID {
+						t.Errorf("got %+v, want the contact fields of %+v", got, tt.want)
+					}
+					if !got.Birthdate.Equal(tt.want.Birthdate) {
+						t.Errorf("birthdate = %v, want %v", got.Birthdate, tt.want.Birthdate)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestPatientRepositorySearchOrder checks that the closest candidates are
+// read first, so that the limit cuts off the weakest ones rather than
+// whichever the database reads first.
+func TestPatientRepositorySearchOrder(t *testing.T) {
+	// A name of letters only, unique to the run, so that it is parsed as a
+	// name and matches no patients of earlier runs.
+	name := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return 'a' + r - '0'
+		}
+		return r
+	}, uniqueKey("kartika"))
+
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			patients := NewPatientRepository(database.db, database.dialect, testKeyring(t))
+
+			// The prefix matches are created first and sort first by ID.
+			created := []*entitiy.Patient{
+				{ID: uniqueKey("a-"), FirstName: name + "wati", LastName: "Susanto"},
+				{ID: uniqueKey("b-"), FirstName: name + "sari", LastName: "Susanto"},
+				{ID: uniqueKey("c-"), FirstName: name, LastName: "Susanto"},
+			}
+			mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+				for _, patient := range created {
+					patient.Birthdate = time.Date(1990, 3, 4, 0, 0, 0, 0, time.UTC)
+					patient.Sex = entitiy.Female
+					if err := patients.Create(ctx, tx, patient); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			tests := []struct {
+				name  string
+				text  string
+				limit int
+				want  string
+			}{
+				{"exact name", name, 1, created[2].ID},
+				{"exact name and surname", name + " susanto", 1, created[2].ID},
+				{"exact ID", created[1].ID, 1, created[1].ID},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					var found []*entitiy.Patient
+					err := inTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+						var err error
+						found, err = patients.Search(ctx, tx, search.Parse(tt.text), tt.limit)
+						return err
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if len(found) != 1 || found[0].ID != tt.want {
+						t.Fatalf("found %v, want %s first", found, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/config"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the migration log out of the test output.
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// testDatabase is a migrated database the repository tests run against.
+type testDatabase struct {
+	name    string
+	db      *sql.DB
+	dialect dialect.Dialect
+}
+
+// testDatabases returns a migrated SQLite database in a temporary file,
+// and the MySQL and PostgreSQL databases named by LIS_TEST_MYSQL_DSN
+// (with parseTime=true) and LIS_TEST_POSTGRES_DSN when they are set. Those
+// are migrated and written to under keys unique to the run, so they must
+// be scratch databases no server uses.
+func testDatabases(t testing.TB) []testDatabase {
+	t.Helper()
+
+	// The migrations are read from the repository root.
+	t.Chdir(filepath.Join("..", ".."))
+
+	databases := []testDatabase{{
+		name:    "sqlite",
+		dialect: dialect.SQLite,
+		db:      openSQLite(t),
+	}}
+
+	for _, server := range []struct {
+		dialect dialect.Dialect
+		env     string
+	}{
+		{dialect.MySQL, "LIS_TEST_MYSQL_DSN"},
+		{dialect.Postgres, "LIS_TEST_POSTGRES_DSN"},
+	} {
+		dsn := os.Getenv(server.env)
+		if dsn == "" {
+			t.Logf("%s not set, skipping %s", server.env, server.dialect)
+			continue
+		}
+
+		db, err := sql.Open(server.dialect.Driver(), dsn)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", server.dialect, err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		databases = append(databases, testDatabase{name: string(server.dialect), dialect: server.dialect, db: db})
+	}
+
+	for _, database := range databases {
+		if err := config.RunMigrations(database.db, database.dialect); err != nil {
+			t.Fatalf("failed to migrate %s: %v", database.name, err)
+		}
+	}
+
+	return databases
+}
+
+func openSQLite(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := config.NewDatabaseConnection(config.DatabaseConfig{
+		Dialect:      dialect.SQLite,
+		Path:         filepath.Join(t.TempDir(), "lis.db"),
+		MaxOpenConns: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func testKeyring(t testing.TB) *fieldcrypt.Keyring {
+	t.Helper()
+
+	key, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexKey, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := fieldcrypt.ParseKeyring("test:"+key, "", indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+// uniqueKey returns prefix followed by a suffix not used by earlier runs,
+// so that tests against a shared database do not collide.
+func uniqueKey(prefix string) string {
+	return prefix + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// inTx runs fn in a transaction that is rolled back afterwards, returning
+// the error of fn.
+func inTx(t testing.TB, db *sql.DB, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	return fn(ctx, tx)
+}
+
+// mustTx runs fn in a committed transaction and fails the test on error.
+func mustTx(t testing.TB, db *sql.DB, fn func(ctx context.Context, tx *sql.Tx) error) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestConstraintViolations checks that each kind of constraint violation
+// reaches the usecases as the same kind of error on every database.
+func TestConstraintViolations(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			patients := NewPatientRepository(database.db, database.dialect, testKeyring(t))
+			workOrders := NewWorkOrderRepository(database.db, database.dialect)
+			versions := NewResultVersionRepository(database.db, database.dialect)
+
+			patient := &entitiy.Patient{
+				ID:        uniqueKey("p-"),
+				FirstName: "Budi",
+				LastName:  "Santoso",
+				Birthdate: time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC),
+				Sex:       entitiy.Male,
+				Phone:     "081234567890",
+			}
+			resulted := &entitiy.WorkOrder{NoOrder: uniqueKey("WO-R-"), PatientID: patient.ID, Priority: entitiy.PriorityRoutine, TestCode: []string{"GLU"}}
+			pending := &entitiy.WorkOrder{NoOrder: uniqueKey("WO-P-"), PatientID: patient.ID, Priority: entitiy.PriorityRoutine, TestCode: []string{"GLU"}}
+
+			mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+				if err := patients.Create(ctx, tx, patient); err != nil {
+					return err
+				}
+				if err := workOrders.Create(ctx, tx, resulted); err != nil {
+					return err
+				}
+				if err := workOrders.Create(ctx, tx, pending); err != nil {
+					return err
+				}
+				return versions.Create(ctx, tx, &entitiy.ResultVersion{NoOrder: resulted.NoOrder, TestCode: "GLU", Version: 1, Value: "5.4", ChangedBy: "analyst", ChangedAt: time.Now()})
+			})
+
+			tests := []struct {
+				name string
+				fn   func(ctx context.Context, tx *sql.Tx) error
+				want error
+			}{
+				{"duplicate patient", func(ctx context.Context, tx *sql.Tx) error {
+					duplicate := *patient
+					return patients.Create(ctx, tx, &duplicate)
+				}, apperror.ErrConflict},
+				{"work order of a missing patient", func(ctx context.Context, tx *sql.Tx) error {
+					return workOrders.Create(ctx, tx, &entitiy.WorkOrder{NoOrder: uniqueKey("WO-M-"), PatientID: uniqueKey("missing-"), Priority: entitiy.PriorityRoutine})
+				}, apperror.ErrValidation},
+				{"delete of a work order with result history", func(ctx context.Context, tx *sql.Tx) error {
+					return workOrders.Delete(ctx, tx, resulted.NoOrder)
+				}, apperror.ErrConflict},
+				{"delete of a patient with result history", func(ctx context.Context, tx *sql.Tx) error {
+					return patients.Delete(ctx, tx, patient.ID)
+				}, apperror.ErrConflict},
+				{"delete of a work order without results", func(ctx context.Context, tx *sql.Tx) error {
+					return workOrders.Delete(ctx, tx, pending.NoOrder)
+				}, nil},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					err := inTx(t, database.db, tt.fn)
+					if tt.want == nil && err != nil {
+						t.Fatalf("err = %v, want nil", err)
+					}
+					if tt.want != nil && !errors.Is(err, tt.want) {
+						t.Fatalf("err = %v, want %v", err, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type ResultVersionRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, version *entitiy.ResultVersion) error
+	GetByNoOrder(ctx context.Context, tx *sql.Tx, noOrder string) ([]*entitiy.ResultVersion, error)
+}
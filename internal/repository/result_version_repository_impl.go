@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type ResultVersionRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+func NewResultVersionRepository(db *sql.DB, d dialect.Dialect) ResultVersionRepository {
+	return &ResultVersionRepositoryImpl{dialect: d}
+}
+
+// Create appends a version to the history of a test result. The version
+// number is assigned here as one above the current latest version; the
+// unique index on (no_order, test_code, version) rejects a concurrent
+// writer that raced for the same number.
+func (r *ResultVersionRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, version *entitiy.ResultVersion) error {
+	latestQuery := `
+		SELECT COALESCE(MAX(version), 0)
+		FROM result_versions
+		WHERE no_order = ? AND test_code = ?
+	`
+
+	var latest int
+	if err := tx.QueryRowContext(ctx, r.dialect.Rebind(latestQuery), version.NoOrder, version.TestCode).Scan(&latest); err != nil {
+		return fmt.Errorf("failed to get latest result version: %w", err)
+	}
+
+	version.Version = latest + 1
+
+	query := `
+		INSERT INTO result_versions (no_order, test_code, version, result_value, result_flag, result_comment, reason, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertID(ctx, tx, query,
+		version.NoOrder,
+		version.TestCode,
+		version.Version,
+		version.Value,
+		version.Flag,
+		version.Comment,
+		version.Reason,
+		version.ChangedBy,
+		version.ChangedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create result version: %w", err)
+	}
+
+	version.ID = id
+
+	return nil
+}
+
+func (r *ResultVersionRepositoryImpl) GetByNoOrder(ctx context.Context, tx *sql.Tx, noOrder string) ([]*entitiy.ResultVersion, error) {
+	query := `
+		SELECT id, no_order, test_code, version, COALESCE(result_value, ''), COALESCE(result_flag, ''), COALESCE(result_comment, ''),
+			COALESCE(reason, ''), COALESCE(changed_by, ''), changed_at
+		FROM result_versions
+		WHERE no_order = ?
+		ORDER BY test_code, version
+	`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*entitiy.ResultVersion
+
+	for rows.Next() {
+		version := &entitiy.ResultVersion{}
+
+		err := rows.Scan(
+			&version.ID,
+			&version.NoOrder,
+			&version.TestCode,
+			&version.Version,
+			&version.Value,
+			&version.Flag,
+			&version.Comment,
+			&version.Reason,
+			&version.ChangedBy,
+			&version.ChangedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan result version: %w", err)
+		}
+
+		versions = append(versions, version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating result versions: %w", err)
+	}
+
+	return versions, nil
+}
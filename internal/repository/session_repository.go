@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, session *entitiy.Session) error
+	GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, tx *sql.Tx, hash string) (*entitiy.Session, error)
+	Revoke(ctx context.Context, tx *sql.Tx, id string, at time.Time) error
+	DeleteExpired(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error)
+}
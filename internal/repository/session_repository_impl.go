@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type SessionRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+func NewSessionRepository(db *sql.DB, d dialect.Dialect) SessionRepository {
+	return &SessionRepositoryImpl{dialect: d}
+}
+
+const sessionColumns = `id, user_id, refresh_token_hash, COALESCE(client_ip, ''), COALESCE(user_agent, ''), expires_at, revoked_at, created_at`
+
+func (r *SessionRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, session *entitiy.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, refresh_token_hash, client_ip, user_agent, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		session.ID,
+		session.UserID,
+		session.RefreshTokenHash,
+		session.ClientIP,
+		session.UserAgent,
+		session.ExpiresAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepositoryImpl) GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = ?`
+
+	return r.scanOne(tx.QueryRowContext(ctx, r.dialect.Rebind(query), id))
+}
+
+func (r *SessionRepositoryImpl) GetByRefreshTokenHash(ctx context.Context, tx *sql.Tx, hash string) (*entitiy.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE refresh_token_hash = ?`
+
+	return r.scanOne(tx.QueryRowContext(ctx, r.dialect.Rebind(query), hash))
+}
+
+// Revoke revokes a session that is not revoked yet. Of two transactions
+// revoking the same session, the second one finds it revoked and fails
+// with a not found error.
+func (r *SessionRepositoryImpl) Revoke(ctx context.Context, tx *sql.Tx, id string, at time.Time) error {
+	query := `UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query), at, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if affected == 0 {
+		return apperror.NotFound("session not found or already revoked")
+	}
+
+	return nil
+}
+
+func (r *SessionRepositoryImpl) DeleteExpired(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at < ?`
+
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query), before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *SessionRepositoryImpl) scanOne(row *sql.Row) (*entitiy.Session, error) {
+	session := &entitiy.Session{}
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.RefreshTokenHash,
+		&session.ClientIP,
+		&session.UserAgent,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("session not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
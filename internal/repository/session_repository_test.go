@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+func TestSessionRepository(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			users := NewUserRepository(database.db, database.dialect)
+			sessions := NewSessionRepository(database.db, database.dialect)
+
+			now := time.Now()
+			user := &entitiy.User{ID: uniqueKey("u-"), Username: uniqueKey("user-"), FullName: "Siti Aminah", PasswordHash: "hash", Active: true}
+			current := &entitiy.Session{ID: uniqueKey("s-"), UserID: user.ID, RefreshTokenHash: uniqueKey("current-"), ExpiresAt: now.Add(time.Hour)}
+			expired := &entitiy.Session{ID: uniqueKey("s-"), UserID: user.ID, RefreshTokenHash: uniqueKey("expired-"), ExpiresAt: now.Add(-time.Hour)}
+
+			mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+				if err := users.Create(ctx, tx, user); err != nil {
+					return err
+				}
+				if err := sessions.Create(ctx, tx, current); err != nil {
+					return err
+				}
+				return sessions.Create(ctx, tx, expired)
+			})
+
+			t.Run("revoke", func(t *testing.T) {
+				mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+					return sessions.Revoke(ctx, tx, current.ID, now)
+				})
+
+				err := inTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+					return sessions.Revoke(ctx, tx, current.ID, now)
+				})
+				if !errors.Is(err, apperror.ErrNotFound) {
+					t.Errorf("revoking a revoked session: got %v, want %v", err, apperror.ErrNotFound)
+				}
+			})
+
+			t.Run("delete expired", func(t *testing.T) {
+				mustTx(t, database.db, func(ctx context.Context, tx *sql.Tx) error {
+					if _, err := sessions.DeleteExpired(ctx, tx, now); err != nil {
+						return err
+					}
+
+					if _, err := sessions.GetByRefreshTokenHash(ctx, tx, current.RefreshTokenHash); err != nil {
+						t.Errorf("session not expired yet: %v", err)
+					}
+					if _, err := sessions.GetByRefreshTokenHash(ctx, tx, expired.RefreshTokenHash); !errors.Is(err, apperror.ErrNotFound) {
+						t.Errorf("expired session: got %v, want %v", err, apperror.ErrNotFound)
+					}
+					return nil
+				})
+			})
+		})
+	}
+}
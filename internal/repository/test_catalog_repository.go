@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type TestCatalogRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, test *entitiy.TestCatalog) error
+	GetByCode(ctx context.Context, tx *sql.Tx, code string) (*entitiy.TestCatalog, error)
+	Update(ctx context.Context, tx *sql.Tx, test *entitiy.TestCatalog) error
+	Delete(ctx context.Context, tx *sql.Tx, code string) error
+	GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.TestCatalog, error)
+	GetInstruments(ctx context.Context, tx *sql.Tx, codes []string) (map[string]string, error)
+}
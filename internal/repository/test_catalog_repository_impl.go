@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type TestCatalogRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+func NewTestCatalogRepository(db *sql.DB, d dialect.Dialect) TestCatalogRepository {
+	return &TestCatalogRepositoryImpl{dialect: d}
+}
+
+func (r *TestCatalogRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, test *entitiy.TestCatalog) error {
+	query := `
+		INSERT INTO test_catalog (code, name, department, instrument, unit, reference_range)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		test.Code,
+		test.Name,
+		test.Department,
+		test.Instrument,
+		test.Unit,
+		test.ReferenceRange,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create test catalog entry: %w", constraintError(err, "test catalog entry "+test.Code))
+	}
+
+	return nil
+}
+
+func (r *TestCatalogRepositoryImpl) GetByCode(ctx context.Context, tx *sql.Tx, code string) (*entitiy.TestCatalog, error) {
+	query := `
+		SELECT code, name, department, COALESCE(instrument, ''), COALESCE(unit, ''), COALESCE(reference_range, ''), created_at, updated_at
+		FROM test_catalog
+		WHERE code = ?
+	`
+
+	test := &entitiy.TestCatalog{}
+
+	err := tx.QueryRowContext(ctx, r.dialect.Rebind(query), code).Scan(
+		&test.Code,
+		&test.Name,
+		&test.Department,
+		&test.Instrument,
+		&test.Unit,
+		&test.ReferenceRange,
+		&test.CreatedAt,
+		&test.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("test catalog entry not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog entry: %w", err)
+	}
+
+	return test, nil
+}
+
+func (r *TestCatalogRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, test *entitiy.TestCatalog) error {
+	query := `
+		UPDATE test_catalog
+		SET name = ?, department = ?, instrument = ?, unit = ?, reference_range = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE code = ?
+	`
+
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		test.Name,
+		test.Department,
+		test.Instrument,
+		test.Unit,
+		test.ReferenceRange,
+		test.Code,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update test catalog entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apperror.NotFound("test catalog entry not found")
+	}
+
+	return nil
+}
+
+func (r *TestCatalogRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, code string) error {
+	query := `DELETE FROM test_catalog WHERE code = ?`
+
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query), code)
+	if err != nil {
+		return fmt.Errorf("failed to delete test catalog entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apperror.NotFound("test catalog entry not found")
+	}
+
+	return nil
+}
+
+func (r *TestCatalogRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.TestCatalog, error) {
+	query := `
+		SELECT code, name, department, COALESCE(instrument, ''), COALESCE(unit, ''), COALESCE(reference_range, ''), created_at, updated_at
+		FROM test_catalog
+		ORDER BY department, code
+	`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog: %w", err)
+	}
+	defer rows.Close()
+
+	var tests []*entitiy.TestCatalog
+
+	for rows.Next() {
+		test := &entitiy.TestCatalog{}
+
+		err := rows.Scan(
+			&test.Code,
+			&test.Name,
+			&test.Department,
+			&test.Instrument,
+			&test.Unit,
+			&test.ReferenceRange,
+			&test.CreatedAt,
+			&test.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan test catalog entry: %w", err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test catalog: %w", err)
+	}
+
+	return tests, nil
+}
+
+// GetInstruments returns the instrument of each of codes, keyed by code.
+// Codes missing from the catalog are left out.
+func (r *TestCatalogRepositoryImpl) GetInstruments(ctx context.Context, tx *sql.Tx, codes []string) (map[string]string, error) {
+	instruments := make(map[string]string, len(codes))
+
+	for _, batch := range batches(codes) {
+		query := `
+			SELECT code, COALESCE(instrument, '')
+			FROM test_catalog
+			WHERE code IN (` + placeholders(len(batch)) + `)
+		`
+
+		rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), stringArgs(batch)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get test instruments: %w", err)
+		}
+
+		for rows.Next() {
+			var code, instrument string
+			if err := rows.Scan(&code, &instrument); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan test instrument: %w", err)
+			}
+			instruments[code] = instrument
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating test instruments: %w", err)
+		}
+	}
+
+	return instruments, nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, user *entitiy.User) error
+	GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.User, error)
+	GetByUsername(ctx context.Context, tx *sql.Tx, username string) (*entitiy.User, error)
+	LockByUsername(ctx context.Context, tx *sql.Tx, username string) error
+	Update(ctx context.Context, tx *sql.Tx, user *entitiy.User) error
+	SetRoles(ctx context.Context, tx *sql.Tx, userID string, roles []entitiy.Role) error
+	GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.User, error)
+	Count(ctx context.Context, tx *sql.Tx) (int, error)
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type UserRepositoryImpl struct {
+	dialect dialect.Dialect
+}
+
+func NewUserRepository(db *sql.DB, d dialect.Dialect) UserRepository {
+	return &UserRepositoryImpl{dialect: d}
+}
+
+const userColumns = `id, username, full_name, password_hash, active, failed_attempts, locked_until, last_login_at, created_at, updated_at`
+
+func (r *UserRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, user *entitiy.User) error {
+	query := `
+		INSERT INTO users (id, username, full_name, password_hash, active)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		user.ID,
+		user.Username,
+		user.FullName,
+		user.PasswordHash,
+		user.Active,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", constraintError(err, "user "+user.Username))
+	}
+
+	return r.insertRoles(ctx, tx, user.ID, user.Roles)
+}
+
+func (r *UserRepositoryImpl) GetByID(ctx context.Context, tx *sql.Tx, id string) (*entitiy.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+
+	return r.scanOne(ctx, tx, tx.QueryRowContext(ctx, r.dialect.Rebind(query), id))
+}
+
+func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, tx *sql.Tx, username string) (*entitiy.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = ?`
+
+	return r.scanOne(ctx, tx, tx.QueryRowContext(ctx, r.dialect.Rebind(query), username))
+}
+
+// LockByUsername locks a user row until the end of the transaction, so
+// that concurrent logins count failed attempts one after the other.
+func (r *UserRepositoryImpl) LockByUsername(ctx context.Context, tx *sql.Tx, username string) error {
+	var locked string
+
+	err := tx.QueryRowContext(ctx, r.dialect.Rebind(`SELECT id FROM users WHERE username = ?`+r.dialect.ForUpdate()), username).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("user not found")
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, user *entitiy.User) error {
+	query := `
+		UPDATE users
+		SET full_name = ?, password_hash = ?, active = ?, failed_attempts = ?, locked_until = ?, last_login_at = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		user.FullName,
+		user.PasswordHash,
+		user.Active,
+		user.FailedAttempts,
+		user.LockedUntil,
+		user.LastLoginAt,
+		user.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepositoryImpl) SetRoles(ctx context.Context, tx *sql.Tx, userID string, roles []entitiy.Role) error {
+	if _, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM user_roles WHERE user_id = ?`), userID); err != nil {
+		return fmt.Errorf("failed to clear user roles: %w", err)
+	}
+
+	return r.insertRoles(ctx, tx, userID, roles)
+}
+
+func (r *UserRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY username`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*entitiy.User
+
+	for rows.Next() {
+		user, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	for _, user := range users {
+		if user.Roles, err = r.getRoles(ctx, tx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepositoryImpl) Count(ctx context.Context, tx *sql.Tx) (int, error) {
+	var count int
+
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *UserRepositoryImpl) scanOne(ctx context.Context, tx *sql.Tx, row *sql.Row) (*entitiy.User, error) {
+	user, err := r.scan(row)
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Roles, err = r.getRoles(ctx, tx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepositoryImpl) getRoles(ctx context.Context, tx *sql.Tx, userID string) ([]entitiy.Role, error) {
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(`SELECT role FROM user_roles WHERE user_id = ? ORDER BY role`), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []entitiy.Role
+
+	for rows.Next() {
+		var role entitiy.Role
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (r *UserRepositoryImpl) insertRoles(ctx context.Context, tx *sql.Tx, userID string, roles []entitiy.Role) error {
+	for _, role := range roles {
+		_, err := tx.ExecContext(ctx, r.dialect.Rebind(`INSERT INTO user_roles (user_id, role) VALUES (?, ?)`), userID, role)
+		if err != nil {
+			return fmt.Errorf("failed to insert user role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *UserRepositoryImpl) scan(row interface{ Scan(...interface{}) error }) (*entitiy.User, error) {
+	user := &entitiy.User{}
+
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.FullName,
+		&user.PasswordHash,
+		&user.Active,
+		&user.FailedAttempts,
+		&user.LockedUntil,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan user: %w", err)
+	}
+
+	return user, nil
+}
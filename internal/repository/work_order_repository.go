@@ -10,9 +10,13 @@ import (
 type WorkOrderRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, workOrder *entitiy.WorkOrder) error
 	GetByNoOrder(ctx context.Context, tx *sql.Tx, noOrder string) (*entitiy.WorkOrder, error)
+	Lock(ctx context.Context, tx *sql.Tx, noOrder string) error
 	Update(ctx context.Context, tx *sql.Tx, workOrder *entitiy.WorkOrder) error
 	Delete(ctx context.Context, tx *sql.Tx, noOrder string) error
-	GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.WorkOrder, error)
-	GetByDoctor(ctx context.Context, tx *sql.Tx, doctor string) ([]*entitiy.WorkOrder, error)
-	GetByAnalyst(ctx context.Context, tx *sql.Tx, analyst string) ([]*entitiy.WorkOrder, error)
+	GetAll(ctx context.Context, tx *sql.Tx, filter entitiy.WorkOrderFilter) ([]*entitiy.WorkOrder, int, error)
+	GetTests(ctx context.Context, tx *sql.Tx, noOrder string) ([]*entitiy.WorkOrderTest, error)
+	UpdateTest(ctx context.Context, tx *sql.Tx, test *entitiy.WorkOrderTest) error
+	GetTATSamples(ctx context.Context, tx *sql.Tx, filter entitiy.TATFilter) ([]*entitiy.TATSample, error)
+	GetWorklist(ctx context.Context, tx *sql.Tx, filter entitiy.WorklistFilter) ([]*entitiy.WorklistItem, error)
+	GetWorkload(ctx context.Context, tx *sql.Tx) ([]*entitiy.WorkloadCount, error)
 }
@@ -4,39 +4,48 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
 )
 
-type WorkOrderRepositoryImpl struct{}
+type WorkOrderRepositoryImpl struct {
+	dialect dialect.Dialect
+}
 
-func NewWorkOrderRepository(db *sql.DB) WorkOrderRepository {
-	return &WorkOrderRepositoryImpl{}
+func NewWorkOrderRepository(db *sql.DB, d dialect.Dialect) WorkOrderRepository {
+	return &WorkOrderRepositoryImpl{dialect: d}
 }
 
 func (r *WorkOrderRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, workOrder *entitiy.WorkOrder) error {
 	query := `
-		INSERT INTO work_orders (no_order, patient_id, analyst, doctor)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO work_orders (no_order, patient_id, analyst, analyst_id, doctor, doctor_id, priority)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, NULLIF(?, ''), ?)
 	`
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
 		workOrder.NoOrder,
 		workOrder.PatientID,
 		workOrder.Analyst,
+		workOrder.AnalystID,
+		workOrder.Doctor,
+		workOrder.DoctorID,
+		workOrder.Priority,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to create work order: %w", err)
+		return fmt.Errorf("failed to create work order: %w", constraintError(err, "work order "+workOrder.NoOrder))
 	}
 
 	if len(workOrder.TestCode) > 0 {
-		testCodeQuery := `INSERT INTO work_order_test_code (no_order, test_code) VALUES (?, ?)`
+		testCodeQuery := `INSERT INTO work_order_test_codes (no_order, test_code) VALUES (?, ?)`
 
 		for _, testCode := range workOrder.TestCode {
-			_, err = tx.ExecContext(ctx, testCodeQuery, workOrder.NoOrder, testCode)
+			_, err = tx.ExecContext(ctx, r.dialect.Rebind(testCodeQuery), workOrder.NoOrder, testCode)
 			if err != nil {
-				return fmt.Errorf("failed to insert test code: %w", err)
+				return fmt.Errorf("failed to insert test code: %w", constraintError(err, "test "+testCode+" of work order "+workOrder.NoOrder))
 			}
 		}
 	}
@@ -46,72 +55,79 @@ func (r *WorkOrderRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, workOr
 
 func (r *WorkOrderRepositoryImpl) GetByNoOrder(ctx context.Context, tx *sql.Tx, noOrder string) (*entitiy.WorkOrder, error) {
 	query := `
-		SELECT no_order, patient_id, analyst, doctor
+		SELECT no_order, patient_id, analyst, COALESCE(analyst_id, ''), doctor, COALESCE(doctor_id, ''), priority, created_at, updated_at
 		FROM work_orders
 		WHERE no_order = ?
 	`
 
 	workOrder := &entitiy.WorkOrder{}
 
-	err := tx.QueryRowContext(ctx, query, noOrder).Scan(
+	err := tx.QueryRowContext(ctx, r.dialect.Rebind(query), noOrder).Scan(
 		&workOrder.NoOrder,
 		&workOrder.PatientID,
 		&workOrder.Analyst,
+		&workOrder.AnalystID,
 		&workOrder.Doctor,
+		&workOrder.DoctorID,
+		&workOrder.Priority,
+		&workOrder.CreatedAt,
+		&workOrder.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("work order not found")
+		return nil, apperror.NotFound("work order not found")
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get work order: %w", err)
 	}
 
-	testCodeQuery := `
-		SELECT test_code
-		FROM work_order_test_codes
-		ORDER BY id
-	`
-
-	rows, err := tx.QueryContext(ctx, testCodeQuery, noOrder)
+	testCodes, err := r.getTestCodes(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get test codes: %w", err)
+		return nil, err
 	}
 
-	var testCodes []string
-	for rows.Next() {
-		var testCode string
-		if err := rows.Scan(&testCode); err != nil {
-			return nil, fmt.Errorf("failed to scan test code: %w", err)
-		}
-		testCodes = append(testCodes, testCode)
-	}
+	workOrder.TestCode = testCodes
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating test codes: %w", err)
+	return workOrder, nil
+}
+
+// Lock locks a work order row until the end of the transaction, so that a
+// read-check-write sequence cannot interleave with another writer.
+func (r *WorkOrderRepositoryImpl) Lock(ctx context.Context, tx *sql.Tx, noOrder string) error {
+	var locked string
+
+	err := tx.QueryRowContext(ctx, r.dialect.Rebind(`SELECT no_order FROM work_orders WHERE no_order = ?`+r.dialect.ForUpdate()), noOrder).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("work order not found")
 	}
 
-	workOrder.TestCode = testCodes
+	if err != nil {
+		return fmt.Errorf("failed to lock work order: %w", err)
+	}
 
-	return workOrder, nil
+	return nil
 }
 
 func (r *WorkOrderRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, workOrder *entitiy.WorkOrder) error {
 	query := `
 		UPDATE work_orders
-		SET analyst = ?, doctor = ?
+		SET analyst = ?, analyst_id = NULLIF(?, ''), doctor = ?, doctor_id = NULLIF(?, ''), priority = ?,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE no_order = ?
 	`
 
-	result, err := tx.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
 		workOrder.Analyst,
+		workOrder.AnalystID,
 		workOrder.Doctor,
+		workOrder.DoctorID,
+		workOrder.Priority,
 		workOrder.NoOrder,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update work order: %w", err)
+		return fmt.Errorf("failed to update work order: %w", constraintError(err, "work order "+workOrder.NoOrder))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -120,23 +136,49 @@ func (r *WorkOrderRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, workOr
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("work order not found")
+		return apperror.NotFound("work order not found")
 	}
 
-	deleteTestCodesQuery := `DELETE FROM work_order_test_codes`
-	_, err = tx.ExecContext(ctx, deleteTestCodesQuery, workOrder.NoOrder)
+	return r.syncTestCodes(ctx, tx, workOrder.NoOrder, workOrder.TestCode)
+}
+
+// syncTestCodes inserts test lines that are new and deletes the ones no
+// longer requested, keeping existing lines and their lifecycle data intact.
+func (r *WorkOrderRepositoryImpl) syncTestCodes(ctx context.Context, tx *sql.Tx, noOrder string, testCodes []string) error {
+	existing, err := r.getTestCodes(ctx, tx, noOrder)
 	if err != nil {
-		return fmt.Errorf("failed to delete test codes: %w", err)
+		return err
 	}
 
-	if len(workOrder.TestCode) > 0 {
-		testCodeQuery := `INSERT INTO work_order_test_codes (no_order, test_code) VALUES (?, ?)`
+	wanted := make(map[string]bool, len(testCodes))
+	for _, testCode := range testCodes {
+		wanted[testCode] = true
+	}
 
-		for _, testCode := range workOrder.TestCode {
-			_, err = tx.ExecContext(ctx, testCodeQuery, workOrder.NoOrder, testCode)
-			if err != nil {
-				return fmt.Errorf("failed to insert test code: %w", err)
-			}
+	current := make(map[string]bool, len(existing))
+	for _, testCode := range existing {
+		current[testCode] = true
+
+		if wanted[testCode] {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, r.dialect.Rebind(`DELETE FROM work_order_test_codes WHERE no_order = ? AND test_code = ?`), noOrder, testCode)
+		if err != nil {
+			return fmt.Errorf("failed to delete test code: %w", err)
+		}
+	}
+
+	testCodeQuery := `INSERT INTO work_order_test_codes (no_order, test_code) VALUES (?, ?)`
+
+	for _, testCode := range testCodes {
+		if current[testCode] {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, r.dialect.Rebind(testCodeQuery), noOrder, testCode)
+		if err != nil {
+			return fmt.Errorf("failed to insert test code: %w", constraintError(err, "test "+testCode+" of work order "+noOrder))
 		}
 	}
 
@@ -145,16 +187,16 @@ func (r *WorkOrderRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, workOr
 
 func (r *WorkOrderRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, noOrder string) error {
 	deleteTestCodesQuery := `DELETE FROM work_order_test_codes WHERE no_order = ?`
-	_, err := tx.ExecContext(ctx, deleteTestCodesQuery, noOrder)
+	_, err := tx.ExecContext(ctx, r.dialect.Rebind(deleteTestCodesQuery), noOrder)
 	if err != nil {
 		return fmt.Errorf("failed to delete test codes: %w", err)
 	}
 
 	query := `DELETE FROM work_orders WHERE no_order = ?`
 
-	result, err := tx.ExecContext(ctx, query, noOrder)
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query), noOrder)
 	if err != nil {
-		return fmt.Errorf("failed to delete work order: %w", err)
+		return fmt.Errorf("failed to delete work order: %w", deleteError(err, "work order "+noOrder))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -163,23 +205,103 @@ func (r *WorkOrderRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, noOrde
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("work order not found")
+		return apperror.NotFound("work order not found")
 	}
 
 	return nil
 }
 
-func (r *WorkOrderRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx) ([]*entitiy.WorkOrder, error) {
+// workOrderSortColumns maps the sort fields of dto.WorkOrderSortFields to
+// columns. Priority sorts stat first.
+var workOrderSortColumns = map[string]string{
+	"no_order":   "w.no_order",
+	"created_at": "w.created_at",
+	"priority":   "CASE w.priority WHEN 'stat' THEN 0 WHEN 'urgent' THEN 1 ELSE 2 END",
+	"doctor":     "w.doctor",
+	"analyst":    "w.analyst",
+}
+
+// GetAll returns one page of the work orders matching filter and the
+// number of matching work orders.
+func (r *WorkOrderRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx, filter entitiy.WorkOrderFilter) ([]*entitiy.WorkOrder, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.PatientID != "" {
+		conditions = append(conditions, "w.patient_id = ?")
+		args = append(args, filter.PatientID)
+	}
+
+	if filter.Doctor != "" {
+		conditions = append(conditions, "(w.doctor_id = ? OR w.doctor = ?)")
+		args = append(args, filter.Doctor, filter.Doctor)
+	}
+
+	if filter.Analyst != "" {
+		conditions = append(conditions, "(w.analyst_id = ? OR w.analyst = ?)")
+		args = append(args, filter.Analyst, filter.Analyst)
+	}
+
+	if filter.Priority != "" {
+		conditions = append(conditions, "w.priority = ?")
+		args = append(args, filter.Priority)
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "w.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "w.created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	if len(filter.Status) > 0 || filter.TestCode != "" {
+		var testConditions []string
+
+		if len(filter.Status) > 0 {
+			placeholders := make([]string, len(filter.Status))
+			for i, status := range filter.Status {
+				placeholders[i] = "?"
+				args = append(args, status)
+			}
+			testConditions = append(testConditions, "t.status IN ("+strings.Join(placeholders, ", ")+")")
+		}
+
+		if filter.TestCode != "" {
+			testConditions = append(testConditions, "t.test_code = ?")
+			args = append(args, filter.TestCode)
+		}
+
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM work_order_test_codes t WHERE t.no_order = w.no_order AND "+
+			strings.Join(testConditions, " AND ")+")")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, r.dialect.Rebind(`SELECT COUNT(*) FROM work_orders w`+where), args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count work orders: %w", err)
+	}
+
+	order, orderArgs, err := pageClause(workOrderSortColumns, filter.Page, "w.no_order")
+	if err != nil {
+		return nil, 0, err
+	}
+
 	query := `
-		SELECT no_order, patient_id, analyst, doctor
-		FROM work_orders
-		ORDER BY no_order
-	`
+		SELECT w.no_order, w.patient_id, w.analyst, COALESCE(w.analyst_id, ''), w.doctor, COALESCE(w.doctor_id, ''), w.priority, w.created_at, w.updated_at
+		FROM work_orders w` + where + order
 
-	rows, err := tx.QueryContext(ctx, query)
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), append(args, orderArgs...)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get work orders: %w", err)
+		return nil, 0, fmt.Errorf("failed to get work orders: %w", err)
 	}
+	defer rows.Close()
 
 	var workOrders []*entitiy.WorkOrder
 
@@ -190,125 +312,351 @@ func (r *WorkOrderRepositoryImpl) GetAll(ctx context.Context, tx *sql.Tx) ([]*en
 			&workOrder.NoOrder,
 			&workOrder.PatientID,
 			&workOrder.Analyst,
+			&workOrder.AnalystID,
 			&workOrder.Doctor,
+			&workOrder.DoctorID,
+			&workOrder.Priority,
+			&workOrder.CreatedAt,
+			&workOrder.UpdatedAt,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan work order: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan work order: %w", err)
 		}
 
 		workOrders = append(workOrders, workOrder)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating work orders: %w", err)
+		return nil, 0, fmt.Errorf("error iterating work orders: %w", err)
+	}
+
+	rows.Close()
+
+	noOrders := make([]string, len(workOrders))
+	for i, workOrder := range workOrders {
+		noOrders[i] = workOrder.NoOrder
+	}
+
+	testCodes, err := r.getTestCodesByOrder(ctx, tx, noOrders)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	for _, workOrder := range workOrders {
-		testCodes, err := r.getTestCodes(ctx, tx, workOrder.NoOrder)
-		if err != nil {
-			return nil, err
-		}
-		workOrder.TestCode = testCodes
+		workOrder.TestCode = testCodes[workOrder.NoOrder]
 	}
 
-	return workOrders, nil
+	return workOrders, total, nil
 }
 
-func (r *WorkOrderRepositoryImpl) GetByDoctor(ctx context.Context, tx *sql.Tx, doctor string) ([]*entitiy.WorkOrder, error) {
+func (r *WorkOrderRepositoryImpl) GetWorklist(ctx context.Context, tx *sql.Tx, filter entitiy.WorklistFilter) ([]*entitiy.WorklistItem, error) {
 	query := `
-		SELECT no_order, patient_id, analyst, doctor
-		FROM work_orders
-		WHERE doctor = ?
-		ORDER BY no_order
+		SELECT t.no_order, t.test_code, COALESCE(c.name, ''), COALESCE(c.department, ''), COALESCE(c.instrument, ''),
+			t.status, w.priority, p.id, p.first_name, p.last_name, p.birthdate, p.sex, w.doctor, w.created_at
+		FROM work_order_test_codes t
+		JOIN work_orders w ON w.no_order = t.no_order
+		JOIN patients p ON p.id = w.patient_id
+		LEFT JOIN test_catalog c ON c.code = t.test_code
 	`
 
-	rows, err := tx.QueryContext(ctx, query, doctor)
+	var conditions []string
+	var args []interface{}
+
+	if filter.Department != "" {
+		conditions = append(conditions, "c.department = ?")
+		args = append(args, filter.Department)
+	}
+
+	if filter.Instrument != "" {
+		conditions = append(conditions, "c.instrument = ?")
+		args = append(args, filter.Instrument)
+	}
+
+	if len(filter.Status) > 0 {
+		placeholders := make([]string, len(filter.Status))
+		for i, status := range filter.Status {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		conditions = append(conditions, "t.status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	if filter.Priority != "" {
+		conditions = append(conditions, "w.priority = ?")
+		args = append(args, filter.Priority)
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "w.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "w.created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += `
+		ORDER BY CASE w.priority WHEN 'stat' THEN 0 WHEN 'urgent' THEN 1 ELSE 2 END,
+			w.created_at, t.no_order, t.id
+	`
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get work orders by doctor: %w", err)
+		return nil, fmt.Errorf("failed to get worklist: %w", err)
 	}
 	defer rows.Close()
 
-	var workOrders []*entitiy.WorkOrder
+	var items []*entitiy.WorklistItem
 
 	for rows.Next() {
-		workOrder := &entitiy.WorkOrder{}
+		item := &entitiy.WorklistItem{}
 
 		err := rows.Scan(
-			&workOrder.NoOrder,
-			&workOrder.PatientID,
-			&workOrder.Analyst,
-			&workOrder.Doctor,
+			&item.NoOrder,
+			&item.TestCode,
+			&item.TestName,
+			&item.Department,
+			&item.Instrument,
+			&item.Status,
+			&item.Priority,
+			&item.PatientID,
+			&item.PatientFirstName,
+			&item.PatientLastName,
+			&item.PatientBirthdate,
+			&item.PatientSex,
+			&item.Doctor,
+			&item.OrderedAt,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan work order: %w", err)
+			return nil, fmt.Errorf("failed to scan worklist item: %w", err)
 		}
 
-		workOrders = append(workOrders, workOrder)
+		items = append(items, item)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating work orders: %w", err)
+		return nil, fmt.Errorf("error iterating worklist: %w", err)
 	}
 
-	for _, workOrder := range workOrders {
-		testCodes, err := r.getTestCodes(ctx, tx, workOrder.NoOrder)
+	return items, nil
+}
+
+func (r *WorkOrderRepositoryImpl) GetTests(ctx context.Context, tx *sql.Tx, noOrder string) ([]*entitiy.WorkOrderTest, error) {
+	query := `
+		SELECT no_order, test_code, status, COALESCE(result_value, ''), COALESCE(result_flag, ''), COALESCE(result_comment, ''),
+			received_at, resulted_at, validated_at, COALESCE(validated_by, ''), authorized_at, COALESCE(authorized_by, ''),
+			amended_at, COALESCE(amended_by, ''), created_at
+		FROM work_order_test_codes
+		WHERE no_order = ?
+		ORDER BY id
+	`
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
+	}
+	defer rows.Close()
+
+	var tests []*entitiy.WorkOrderTest
+
+	for rows.Next() {
+		test := &entitiy.WorkOrderTest{}
+
+		err := rows.Scan(
+			&test.NoOrder,
+			&test.TestCode,
+			&test.Status,
+			&test.ResultValue,
+			&test.ResultFlag,
+			&test.ResultComment,
+			&test.ReceivedAt,
+			&test.ResultedAt,
+			&test.ValidatedAt,
+			&test.ValidatedBy,
+			&test.AuthorizedAt,
+			&test.AuthorizedBy,
+			&test.AmendedAt,
+			&test.AmendedBy,
+			&test.CreatedAt,
+		)
+
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to scan work order test: %w", err)
 		}
-		workOrder.TestCode = testCodes
+
+		tests = append(tests, test)
 	}
 
-	return workOrders, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating work order tests: %w", err)
+	}
+
+	return tests, nil
 }
 
-func (r *WorkOrderRepositoryImpl) GetByAnalyst(ctx context.Context, tx *sql.Tx, analyst string) ([]*entitiy.WorkOrder, error) {
+func (r *WorkOrderRepositoryImpl) UpdateTest(ctx context.Context, tx *sql.Tx, test *entitiy.WorkOrderTest) error {
 	query := `
-		SELECT no_order, patient_id, analyst, doctor
-		FROM work_orders
-		WHERE analyst = ?
-		ORDER BY no_order
+		UPDATE work_order_test_codes
+		SET status = ?, result_value = ?, result_flag = ?, result_comment = ?,
+			received_at = ?, resulted_at = ?, validated_at = ?, validated_by = ?, authorized_at = ?, authorized_by = ?,
+			amended_at = ?, amended_by = ?
+		WHERE no_order = ? AND test_code = ?
 	`
 
-	rows, err := tx.QueryContext(ctx, query, analyst)
+	result, err := tx.ExecContext(ctx, r.dialect.Rebind(query),
+		test.Status,
+		test.ResultValue,
+		test.ResultFlag,
+		test.ResultComment,
+		test.ReceivedAt,
+		test.ResultedAt,
+		test.ValidatedAt,
+		test.ValidatedBy,
+		test.AuthorizedAt,
+		test.AuthorizedBy,
+		test.AmendedAt,
+		test.AmendedBy,
+		test.NoOrder,
+		test.TestCode,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update work order test: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get work orders by analyst: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return apperror.NotFound("work order test not found")
+	}
+
+	return nil
+}
+
+func (r *WorkOrderRepositoryImpl) GetTATSamples(ctx context.Context, tx *sql.Tx, filter entitiy.TATFilter) ([]*entitiy.TATSample, error) {
+	query := `
+		SELECT t.no_order, t.test_code, COALESCE(c.department, ''), w.priority, w.created_at,
+			t.received_at, t.resulted_at, t.authorized_at
+		FROM work_order_test_codes t
+		JOIN work_orders w ON w.no_order = t.no_order
+		LEFT JOIN test_catalog c ON c.code = t.test_code
+	`
+
+	var conditions []string
+	var args []interface{}
+
+	if filter.Department != "" {
+		conditions = append(conditions, "c.department = ?")
+		args = append(args, filter.Department)
+	}
+
+	if filter.Priority != "" {
+		conditions = append(conditions, "w.priority = ?")
+		args = append(args, filter.Priority)
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "w.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "w.created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get turnaround samples: %w", err)
 	}
 	defer rows.Close()
 
-	var workOrders []*entitiy.WorkOrder
+	var samples []*entitiy.TATSample
 
 	for rows.Next() {
-		workOrder := &entitiy.WorkOrder{}
+		sample := &entitiy.TATSample{}
 
 		err := rows.Scan(
-			&workOrder.NoOrder,
-			&workOrder.PatientID,
-			&workOrder.Analyst,
-			&workOrder.Doctor,
+			&sample.NoOrder,
+			&sample.TestCode,
+			&sample.Department,
+			&sample.Priority,
+			&sample.OrderedAt,
+			&sample.ReceivedAt,
+			&sample.ResultedAt,
+			&sample.AuthorizedAt,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan work order: %w", err)
+			return nil, fmt.Errorf("failed to scan turnaround sample: %w", err)
 		}
 
-		workOrders = append(workOrders, workOrder)
+		samples = append(samples, sample)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating work orders: %w", err)
+		return nil, fmt.Errorf("error iterating turnaround samples: %w", err)
 	}
 
-	for _, workOrder := range workOrders {
-		testCodes, err := r.getTestCodes(ctx, tx, workOrder.NoOrder)
-		if err != nil {
-			return nil, err
+	return samples, nil
+}
+
+// GetWorkload counts the test lines that are not yet authorized by the
+// department of their test and their status.
+func (r *WorkOrderRepositoryImpl) GetWorkload(ctx context.Context, tx *sql.Tx) ([]*entitiy.WorkloadCount, error) {
+	query := `
+		SELECT COALESCE(c.department, ''), t.status, COUNT(*),
+			SUM(CASE WHEN UPPER(t.result_flag) IN (` + placeholders(len(entitiy.CriticalResultFlags)) + `) THEN 1 ELSE 0 END)
+		FROM work_order_test_codes t
+		LEFT JOIN test_catalog c ON c.code = t.test_code
+		WHERE t.status <> ?
+		GROUP BY COALESCE(c.department, ''), t.status
+	`
+
+	args := append(stringArgs(entitiy.CriticalResultFlags), entitiy.TestStatusAuthorized)
+
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workload: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*entitiy.WorkloadCount
+
+	for rows.Next() {
+		count := &entitiy.WorkloadCount{}
+
+		if err := rows.Scan(&count.Department, &count.Status, &count.Count, &count.Critical); err != nil {
+			return nil, fmt.Errorf("failed to scan workload: %w", err)
 		}
-		workOrder.TestCode = testCodes
+
+		counts = append(counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workload: %w", err)
 	}
 
-	return workOrders, nil
+	return counts, nil
 }
 
 func (r *WorkOrderRepositoryImpl) getTestCodes(ctx context.Context, tx *sql.Tx, noOrder string) ([]string, error) {
@@ -319,7 +667,7 @@ func (r *WorkOrderRepositoryImpl) getTestCodes(ctx context.Context, tx *sql.Tx,
 		ORDER BY id
 	`
 
-	rows, err := tx.QueryContext(ctx, query, noOrder)
+	rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), noOrder)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get test codes: %w", err)
 	}
@@ -340,3 +688,40 @@ func (r *WorkOrderRepositoryImpl) getTestCodes(ctx context.Context, tx *sql.Tx,
 
 	return testCodes, nil
 }
+
+// getTestCodesByOrder returns the test codes of several work orders, keyed
+// by order number, with one query per batch of orders instead of one per
+// order.
+func (r *WorkOrderRepositoryImpl) getTestCodesByOrder(ctx context.Context, tx *sql.Tx, noOrders []string) (map[string][]string, error) {
+	testCodes := make(map[string][]string, len(noOrders))
+
+	for _, batch := range batches(noOrders) {
+		query := `
+			SELECT no_order, test_code
+			FROM work_order_test_codes
+			WHERE no_order IN (` + placeholders(len(batch)) + `)
+			ORDER BY no_order, id
+		`
+
+		rows, err := tx.QueryContext(ctx, r.dialect.Rebind(query), stringArgs(batch)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get test codes: %w", err)
+		}
+
+		for rows.Next() {
+			var noOrder, testCode string
+			if err := rows.Scan(&noOrder, &testCode); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan test code: %w", err)
+			}
+			testCodes[noOrder] = append(testCodes[noOrder], testCode)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating test codes: %w", err)
+		}
+	}
+
+	return testCodes, nil
+}
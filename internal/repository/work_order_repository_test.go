@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// listedOrders creates n work orders of n patients with three tests each,
+// the shape of a page of the work order list.
+func listedOrders(b *testing.B, database testDatabase, patients PatientRepository, workOrders WorkOrderRepository, n int) (noOrders, patientIDs []string) {
+	b.Helper()
+
+	prefix := uniqueKey("")
+	mustTx(b, database.db, func(ctx context.Context, tx *sql.Tx) error {
+		for i := 0; i < n; i++ {
+			patient := &entitiy.Patient{ID: fmt.Sprintf("p-%s-%d", prefix, i), FirstName: "Budi", Birthdate: time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC), Sex: entitiy.Male}
+			if err := patients.Create(ctx, tx, patient); err != nil {
+				return err
+			}
+
+			workOrder := &entitiy.WorkOrder{NoOrder: fmt.Sprintf("WO-%s-%d", prefix, i), PatientID: patient.ID, Priority: entitiy.PriorityRoutine, TestCode: []string{"GLU", "HB", "WBC"}}
+			if err := workOrders.Create(ctx, tx, workOrder); err != nil {
+				return err
+			}
+
+			noOrders = append(noOrders, workOrder.NoOrder)
+			patientIDs = append(patientIDs, patient.ID)
+		}
+		return nil
+	})
+
+	return noOrders, patientIDs
+}
+
+// BenchmarkWorkOrderListLoading compares loading the test codes and
+// patients of a page of work orders one order at a time, as the list did
+// before, with loading them in batches.
+func BenchmarkWorkOrderListLoading(b *testing.B) {
+	const pageSize = 500
+
+	for _, database := range testDatabases(b) {
+		patients := NewPatientRepository(database.db, database.dialect, testKeyring(b))
+		workOrders := NewWorkOrderRepository(database.db, database.dialect)
+		workOrderRepo := workOrders.(*WorkOrderRepositoryImpl)
+
+		noOrders, patientIDs := listedOrders(b, database, patients, workOrders, pageSize)
+
+		benchmarks := []struct {
+			name string
+			load func(ctx context.Context, tx *sql.Tx) error
+		}{
+			{"test codes/one by one", func(ctx context.Context, tx *sql.Tx) error {
+				for _, noOrder := range noOrders {
+					if _, err := workOrderRepo.getTestCodes(ctx, tx, noOrder); err != nil {
+						return err
+					}
+				}
+				return nil
+			}},
+			{"test codes/batch", func(ctx context.Context, tx *sql.Tx) error {
+				_, err := workOrderRepo.getTestCodesByOrder(ctx, tx, noOrders)
+				return err
+			}},
+			{"patients/one by one", func(ctx context.Context, tx *sql.Tx) error {
+				for _, id := range patientIDs {
+					if _, err := patients.GetByID(ctx, tx, id); err != nil {
+						return err
+					}
+				}
+				return nil
+			}},
+			{"patients/batch", func(ctx context.Context, tx *sql.Tx) error {
+				_, err := patients.GetByIDs(ctx, tx, patientIDs)
+				return err
+			}},
+		}
+
+		for _, bm := range benchmarks {
+			b.Run(database.name+"/"+bm.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := inTx(b, database.db, bm.load); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
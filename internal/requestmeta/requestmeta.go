@@ -0,0 +1,58 @@
+// Package requestmeta carries per-request metadata (request ID and client
+// address) through the context so that lower layers can record it.
+package requestmeta
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header is the header a request ID is read from and echoed in.
+const Header = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type requestIDKey struct{}
+
+type clientIPKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// FromRequest stores the request ID and client IP of r in its context. A
+// well-formed incoming request ID is kept so that IDs can be correlated
+// across services; otherwise a new one is generated. Forwarding headers
+// are not trusted for the client IP.
+func FromRequest(r *http.Request) context.Context {
+	requestID := r.Header.Get(Header)
+	if !validRequestID.MatchString(requestID) {
+		requestID = uuid.New().String()
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	ctx := WithRequestID(r.Context(), requestID)
+	return WithClientIP(ctx, ip)
+}
@@ -0,0 +1,164 @@
+// Package search matches patient search text against patient records. It
+// splits a query into terms, and compares names in a way that tolerates
+// typing mistakes and the spelling variants of Indonesian names: the old
+// (pre-1972) spelling, such as Soekarno for Sukarno or Djoko for Joko, and
+// transliterations such as Achmad for Ahmad.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// respellings rewrite spelling variants into one form. The old j, which is
+// the new y, is left alone: it cannot be told apart from the new j, and Key
+// treats the two alike instead.
+var respellings = strings.NewReplacer(
+	"oe", "u",
+	"dj", "j",
+	"tj", "c",
+	"sj", "sy",
+	"nj", "ny",
+	"ch", "h",
+	"kh", "h",
+	"ph", "f",
+	"th", "t",
+	"dh", "d",
+	"sh", "sy",
+	"q", "k",
+	"v", "f",
+)
+
+// NameTokens returns the distinct name tokens of first and last name,
+// lower-cased and without punctuation.
+func NameTokens(names ...string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+
+	for _, name := range names {
+		for _, token := range splitName(name) {
+			if !seen[token] {
+				seen[token] = true
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// splitName lower-cases name and splits it into words of letters. Other
+// characters separate words, except apostrophes, which are dropped.
+func splitName(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(strings.ReplaceAll(name, "'", "")), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// Respell writes a lower-case name token in current Indonesian spelling.
+func Respell(token string) string {
+	return respellings.Replace(token)
+}
+
+// Key returns the phonetic key of a lower-case name token: its first
+// letter followed by its consonants, after respelling, with vowels, j, y
+// and repeated letters dropped. Spelling variants of a name share a key,
+// e.g. Soekarno and Sukarno, Muhammad and Mochamad, or Jusuf and Yusuf.
+func Key(token string) string {
+	respelled := []rune(Respell(token))
+	if len(respelled) == 0 {
+		return ""
+	}
+
+	if respelled[0] == 'y' {
+		respelled[0] = 'j'
+	}
+
+	var b strings.Builder
+	b.WriteRune(respelled[0])
+
+	last := respelled[0]
+	for _, r := range respelled[1:] {
+		if r == last {
+			continue
+		}
+		last = r
+
+		if strings.ContainsRune("aeiouyj", r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// distance returns the number of single-letter insertions, deletions,
+// substitutions and swaps of adjacent letters that turn a into b.
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(t)]
+}
+
+// maxTypos returns how many typing mistakes are tolerated in a query token
+// of n letters.
+func maxTypos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matchName rates how well the query token q matches the name token t,
+// from 1 for the same token to 0 for no match.
+func matchName(q, t string) float64 {
+	switch {
+	case q == t:
+		return 1
+	case len(q) >= 2 && strings.HasPrefix(t, q):
+		return 0.9
+	case Respell(q) == Respell(t):
+		return 0.85
+	case Key(q) == Key(t):
+		return 0.75
+	}
+
+	typos := distance(Respell(q), Respell(t))
+	if typos > maxTypos(len([]rune(q))) {
+		return 0
+	}
+
+	return 0.7 - 0.1*float64(typos)
+}
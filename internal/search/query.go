@@ -0,0 +1,153 @@
+package search
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+)
+
+// dateLayouts are the birthdate formats recognized in a query.
+var dateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006", "2/1/2006"}
+
+// Query is parsed search text. Every term must match for a patient to be
+// found, unless the whole text is a patient's ID, phone, email or national
+// ID.
+type Query struct {
+	// Text is the search text as entered.
+	Text string
+	// Names are name tokens, matched with tolerance against first and last
+	// name.
+	Names []string
+	// Birthdates are dates, matched against the birthdate.
+	Birthdates []time.Time
+	// Identifiers are words with digits, matched as a whole against the
+	// patient ID (the medical record number), phone and national ID.
+	Identifiers []string
+	// Emails are matched as a whole against the email.
+	Emails []string
+}
+
+// Parse splits search text into terms.
+func Parse(text string) Query {
+	query := Query{Text: strings.TrimSpace(text)}
+
+	for _, word := range strings.Fields(query.Text) {
+		switch {
+		case strings.Contains(word, "@"):
+			query.Emails = append(query.Emails, word)
+		case strings.IndexFunc(word, unicode.IsDigit) >= 0:
+			if date, ok := parseDate(word); ok {
+				query.Birthdates = append(query.Birthdates, date)
+			} else {
+				query.Identifiers = append(query.Identifiers, word)
+			}
+		default:
+			query.Names = append(query.Names, splitName(word)...)
+		}
+	}
+
+	return query
+}
+
+// Terms returns the number of terms of the query.
+func (q Query) Terms() int {
+	return len(q.Names) + len(q.Birthdates) + len(q.Identifiers) + len(q.Emails)
+}
+
+// KeyPrefix returns the first letters of a phonetic key that a candidate
+// name must share with a query token. A name with a different key still
+// matches if its spelling is within a few typing mistakes, as long as the
+// mistakes are not at the start of the name.
+func KeyPrefix(key string) string {
+	if runes := []rune(key); len(runes) > 3 {
+		return string(runes[:3])
+	}
+	return key
+}
+
+// Score rates how well patient matches q, from 1 for a patient whose ID,
+// phone, email or national ID is the whole search text, or who matches
+// every term exactly, down towards 0. It returns false if the patient does
+// not match.
+func Score(q Query, patient *entitiy.Patient) (float64, bool) {
+	if exactIdentifier(q.Text, patient) || (patient.Email != "" && fieldcrypt.NormalizeEmail(q.Text) == fieldcrypt.NormalizeEmail(patient.Email)) {
+		return 1, true
+	}
+
+	terms := q.Terms()
+	if terms == 0 {
+		return 0, false
+	}
+
+	total := 0.0
+
+	if len(q.Names) > 0 {
+		tokens := NameTokens(patient.FirstName, patient.LastName)
+
+		for _, name := range q.Names {
+			best := 0.0
+			for _, token := range tokens {
+				best = max(best, matchName(name, token))
+			}
+
+			if best == 0 {
+				return 0, false
+			}
+			total += best
+		}
+	}
+
+	for _, date := range q.Birthdates {
+		if date.Format("2006-01-02") != patient.Birthdate.Format("2006-01-02") {
+			return 0, false
+		}
+		total++
+	}
+
+	for _, identifier := range q.Identifiers {
+		if !exactIdentifier(identifier, patient) {
+			return 0, false
+		}
+		total++
+	}
+
+	for _, email := range q.Emails {
+		if fieldcrypt.NormalizeEmail(email) != fieldcrypt.NormalizeEmail(patient.Email) {
+			return 0, false
+		}
+		total++
+	}
+
+	return total / float64(terms), true
+}
+
+// exactIdentifier reports whether value is the ID, phone or national ID of
+// patient.
+func exactIdentifier(value string, patient *entitiy.Patient) bool {
+	if value == "" {
+		return false
+	}
+
+	if value == patient.ID {
+		return true
+	}
+
+	if phone := fieldcrypt.NormalizePhone(value); phone != "" && phone == fieldcrypt.NormalizePhone(patient.Phone) {
+		return true
+	}
+
+	nationalID := fieldcrypt.NormalizeNationalID(value)
+	return nationalID != "" && nationalID == fieldcrypt.NormalizeNationalID(patient.NationalID)
+}
+
+func parseDate(word string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, word); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
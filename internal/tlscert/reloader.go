@@ -0,0 +1,125 @@
+// Package tlscert serves TLS from certificate files that can be replaced
+// while the server runs. The certificate, its key and the CA that client
+// certificates are verified against are read again on SIGHUP, so that a
+// renewed certificate takes effect without dropping connections.
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// Files are the PEM files of a TLS server. ClientCAFile is optional; it is
+// required only when client certificates are verified.
+type Files struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Reloader holds the TLS configuration built from the files. Handshakes
+// use the configuration current when they start; connections already
+// established keep theirs.
+type Reloader struct {
+	files   Files
+	base    *tls.Config
+	current atomic.Pointer[tls.Config]
+}
+
+// New loads the files. base sets everything other than the certificate
+// and client CAs, such as ClientAuth and MinVersion.
+func New(files Files, base *tls.Config) (*Reloader, error) {
+	r := &Reloader{files: files, base: base}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again. If any of them cannot be loaded, the
+// current configuration is kept and the error is returned.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	config := r.base.Clone()
+	config.Certificates = []tls.Certificate{cert}
+
+	if r.files.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.files.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("failed to load client CA: %s has no PEM certificate", r.files.ClientCAFile)
+		}
+		config.ClientCAs = pool
+	}
+
+	r.current.Store(config)
+	return nil
+}
+
+// Certificate returns the certificate being served.
+func (r *Reloader) Certificate() *x509.Certificate {
+	return r.current.Load().Certificates[0].Leaf
+}
+
+// Config returns a configuration for a listener that negotiates
+// nextProtos by ALPN, such as h2 and http/1.1 for HTTP. Each handshake
+// uses the certificate loaded last.
+func (r *Reloader) Config(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.base.MinVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			config := r.current.Load()
+			if len(nextProtos) > 0 {
+				config = config.Clone()
+				config.NextProtos = nextProtos
+			}
+			return config, nil
+		},
+	}
+}
+
+// NewListener returns a listener that accepts TLS connections on inner.
+// TCP listeners such as MLLP, which do not negotiate a protocol, pass no
+// nextProtos.
+func (r *Reloader) NewListener(inner net.Listener, nextProtos ...string) net.Listener {
+	return tls.NewListener(inner, r.Config(nextProtos...))
+}
+
+// Run reloads the files on each SIGHUP until ctx is done. A failed reload
+// is logged and the current certificate stays in use.
+func (r *Reloader) Run(ctx context.Context) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-hangup:
+			if err := r.Reload(); err != nil {
+				slog.Error("Failed to reload TLS certificate, keeping the current one", "error", err)
+				continue
+			}
+			cert := r.Certificate()
+			slog.Info("TLS certificate reloaded", "subject", cert.Subject.String(), "not_after", cert.NotAfter)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a span for each request served by next, continuing
+// the trace of the caller when the request carries a traceparent header.
+// Spans are named by the method and the pattern mux routes the request
+// to, such as GET /work-orders/{no}, so that IDs in paths do not make
+// every span name unique.
+func Middleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	routed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			trace.SpanFromContext(r.Context()).SetAttributes(semconv.HTTPRoute(route(pattern)))
+		}
+		next.ServeHTTP(w, r)
+	})
+
+	return otelhttp.NewHandler(routed, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			_, pattern := mux.Handler(r)
+			if pattern == "" {
+				return r.Method + " unmatched"
+			}
+			return r.Method + " " + route(pattern)
+		}),
+	)
+}
+
+// route is pattern without its method, if it has one.
+func route(pattern string) string {
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
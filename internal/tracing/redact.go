@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/logging"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// redactingExporter redacts the error messages of spans before exporting
+// them. The server's own spans are redacted when the error is recorded,
+// but the SQL instrumentation records driver errors as they are, and
+// constraint violations quote the offending values.
+type redactingExporter struct {
+	sdktrace.SpanExporter
+}
+
+func (e redactingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		redacted[i] = redactedSpan{span}
+	}
+	return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+// redactedSpan is a span with its status description and exception
+// messages redacted.
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s redactedSpan) Status() sdktrace.Status {
+	status := s.ReadOnlySpan.Status()
+	status.Description = logging.RedactText(status.Description)
+	return status
+}
+
+func (s redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+
+	redacted := make([]sdktrace.Event, len(events))
+	for i, event := range events {
+		event.Attributes = append([]attribute.KeyValue(nil), event.Attributes...)
+		for j, attr := range event.Attributes {
+			if attr.Key == semconv.ExceptionMessageKey {
+				event.Attributes[j] = semconv.ExceptionMessage(logging.RedactText(attr.Value.AsString()))
+			}
+		}
+		redacted[i] = event
+	}
+
+	return redacted
+}
@@ -0,0 +1,62 @@
+// Package tracing records OpenTelemetry traces of the server: a span for
+// each HTTP request, each usecase method and each SQL statement. Until a
+// provider is installed, spans are dropped by the global no-op provider at
+// almost no cost.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/BioSystems-Indonesia/lis/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the spans started by the server
+// itself, as opposed to the HTTP and SQL instrumentation.
+const instrumentationName = "github.com/BioSystems-Indonesia/lis"
+
+// Start starts a span named name, a child of the span of ctx. Usecase
+// methods name their span after the receiver and method, such as
+// workOrderUsecase.Create.
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name)
+}
+
+// RecordError marks the span of ctx as failed with err. The message is
+// redacted like a log record, since errors may quote patient data.
+func RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	message := logging.RedactText(err.Error())
+	span.RecordError(errors.New(message))
+	span.SetStatus(codes.Error, message)
+}
+
+// NewProvider returns a provider that batches spans to exporter. Traces
+// begun by the server are sampled at ratio; traces continued from a
+// caller follow the caller's decision. Tests pass an in-memory exporter
+// to inspect the spans.
+func NewProvider(exporter sdktrace.SpanExporter, serviceName string, ratio float64) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(redactingExporter{exporter}),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+}
+
+// Install makes provider the global provider, and reads and writes the
+// trace context of requests in the W3C traceparent header.
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
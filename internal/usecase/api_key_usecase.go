@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type APIKeyUsecase interface {
+	Create(ctx context.Context, req *dto.APIKeyRequest) (*dto.APIKeySecretResponse, error)
+	GetAll(ctx context.Context) ([]*dto.APIKeyResponse, error)
+	Revoke(ctx context.Context, id string) (*dto.APIKeyResponse, error)
+	Rotate(ctx context.Context, id string) (*dto.APIKeySecretResponse, error)
+	Authenticate(ctx context.Context, key string) (*auth.Principal, error)
+}
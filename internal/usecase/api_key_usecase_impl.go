@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+	"github.com/google/uuid"
+)
+
+// apiKeyTouchInterval limits how often last_used_at is written for a key
+// that is used on every request.
+const apiKeyTouchInterval = time.Minute
+
+type apiKeyUsecase struct {
+	db         *sql.DB
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+	audit      auditTrail
+}
+
+func NewAPIKeyUsecase(db *sql.DB, apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository, auditLogRepo repository.AuditLogRepository) APIKeyUsecase {
+	return &apiKeyUsecase{
+		db:         db,
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		audit:      auditTrail{repo: auditLogRepo},
+	}
+}
+
+// Create issues a new API key. A caller can only grant scopes they hold
+// themselves, and only a user can create keys, since a key acts on
+// behalf of its creator.
+func (u *apiKeyUsecase) Create(ctx context.Context, req *dto.APIKeyRequest) (*dto.APIKeySecretResponse, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyUsecase.Create")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermAPIKeysManage); err != nil {
+		return nil, err
+	}
+
+	if auth.PrincipalFrom(ctx).UserID == "" {
+		return nil, fmt.Errorf("%w: API keys can only be created by a user", auth.ErrForbidden)
+	}
+
+	if err := validateScopes(ctx, req.Scopes); err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, apperror.Field("expires_at", "must be in the future")
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "apiKey.Create", time.Now())
+
+	key := req.ToEntity(uuid.New().String(), auth.PrincipalFrom(ctx).UserID)
+
+	secret, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key.Prefix = prefix
+	key.KeyHash = hash
+
+	if err := u.apiKeyRepo.Create(ctx, tx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	created, err := u.apiKeyRepo.GetByID(ctx, tx, key.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if err := u.audit.created(ctx, tx, entitiy.AuditEntityAPIKey, created.ID, "", dto.ToAPIKeyResponse(created)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &dto.APIKeySecretResponse{
+		APIKeyResponse: *dto.ToAPIKeyResponse(created),
+		Key:            secret,
+	}, nil
+}
+
+func (u *apiKeyUsecase) GetAll(ctx context.Context) ([]*dto.APIKeyResponse, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyUsecase.GetAll")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermAPIKeysManage); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "apiKey.GetAll", time.Now())
+
+	keys, err := u.apiKeyRepo.GetAll(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	return dto.ToAPIKeyResponseList(keys), nil
+}
+
+// Revoke disables a key for good.
+func (u *apiKeyUsecase) Revoke(ctx context.Context, id string) (*dto.APIKeyResponse, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyUsecase.Revoke")
+	defer span.End()
+
+	key, _, err := u.modify(ctx, "apiKey.Revoke", id, func(key *entitiy.APIKey) (string, error) {
+		if key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+		}
+		return "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Rotate replaces the secret of a key, keeping its ID, name and scopes.
+// The old secret stops working immediately.
+func (u *apiKeyUsecase) Rotate(ctx context.Context, id string) (*dto.APIKeySecretResponse, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyUsecase.Rotate")
+	defer span.End()
+
+	key, secret, err := u.modify(ctx, "apiKey.Rotate", id, func(key *entitiy.APIKey) (string, error) {
+		if !key.Valid(time.Now()) {
+			return "", apperror.Conflict("API key is revoked or expired")
+		}
+
+		secret, prefix, hash, err := auth.NewAPIKey()
+		if err != nil {
+			return "", err
+		}
+		key.Prefix = prefix
+		key.KeyHash = hash
+
+		return secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.APIKeySecretResponse{
+		APIKeyResponse: *key,
+		Key:            secret,
+	}, nil
+}
+
+// Authenticate resolves an API key to a principal limited to the key's
+// scopes. A key acts on behalf of the user who created it: it stops
+// working when that user is deactivated or deleted, and loses the scopes
+// the user's roles no longer grant.
+func (u *apiKeyUsecase) Authenticate(ctx context.Context, secret string) (*auth.Principal, error) {
+	ctx, span := tracing.Start(ctx, "apiKeyUsecase.Authenticate")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "apiKey.Authenticate", time.Now())
+
+	now := time.Now()
+
+	key, err := u.apiKeyRepo.GetByKeyHash(ctx, tx, auth.HashToken(secret))
+	if err != nil || !key.Valid(now) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	creator, err := u.userRepo.GetByID(ctx, tx, key.CreatedBy)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key creator: %w", err)
+	}
+	if !creator.Active {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := u.apiKeyRepo.TouchLastUsed(ctx, tx, key.ID, now); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+
+	principal := &auth.Principal{
+		Username: "api-key:" + key.Name,
+		APIKeyID: key.ID,
+	}
+	granted := auth.PermissionsOf(creator.Roles)
+	for _, scope := range key.Scopes {
+		if slices.Contains(granted, auth.Permission(scope)) {
+			principal.Scopes = append(principal.Scopes, auth.Permission(scope))
+		}
+	}
+
+	return principal, nil
+}
+
+func (u *apiKeyUsecase) modify(ctx context.Context, method string, id string, apply func(key *entitiy.APIKey) (string, error)) (*dto.APIKeyResponse, string, error) {
+	if err := auth.Require(ctx, auth.PermAPIKeysManage); err != nil {
+		return nil, "", err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, method, time.Now())
+
+	key, err := u.apiKeyRepo.GetByID(ctx, tx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	before := dto.ToAPIKeyResponse(key)
+
+	secret, err := apply(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := u.apiKeyRepo.Update(ctx, tx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	updated, err := u.apiKeyRepo.GetByID(ctx, tx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if err := u.audit.updated(ctx, tx, entitiy.AuditEntityAPIKey, id, "", before, dto.ToAPIKeyResponse(updated)); err != nil {
+		return nil, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToAPIKeyResponse(updated), secret, nil
+}
+
+// validateScopes checks that scopes are known permissions held by the
+// caller, so a key never grants more than its creator has.
+func validateScopes(ctx context.Context, scopes []string) error {
+	if len(scopes) == 0 {
+		return apperror.Field("scopes", "at least one scope is required")
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+
+	for _, scope := range scopes {
+		permission := auth.Permission(scope)
+		if !permission.Valid() {
+			return apperror.Field("scopes", "unknown scope %q", scope)
+		}
+		if !principal.Can(permission) {
+			return fmt.Errorf("%w: you cannot grant %s", auth.ErrForbidden, scope)
+		}
+	}
+
+	return nil
+}
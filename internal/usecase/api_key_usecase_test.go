@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// TestAPIKeyFollowsCreator checks that a key is limited to what its
+// creator may still do.
+func TestAPIKeyFollowsCreator(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			apiKeys := NewAPIKeyUsecase(database.db, database.apiKeys, database.users, database.auditLogs)
+
+			tests := []struct {
+				name   string
+				change func(t *testing.T, user *entitiy.User)
+				want   []auth.Permission
+				err    error
+			}{
+				{"unchanged", nil, []auth.Permission{auth.PermResultsRead, auth.PermReportsIssue}, nil},
+				{"role removed", func(t *testing.T, user *entitiy.User) {
+					mustTx(t, database, func(ctx context.Context, tx *sql.Tx) error {
+						return database.users.SetRoles(ctx, tx, user.ID, []entitiy.Role{entitiy.RoleAnalyst})
+					})
+				}, []auth.Permission{auth.PermResultsRead}, nil},
+				{"deactivated", func(t *testing.T, user *entitiy.User) {
+					user.Active = false
+					mustTx(t, database, func(ctx context.Context, tx *sql.Tx) error {
+						return database.users.Update(ctx, tx, user)
+					})
+				}, nil, auth.ErrInvalidToken},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ctx := context.Background()
+					creator := createUser(t, database, entitiy.RoleLabAdmin, entitiy.RoleValidator)
+					asCreator := auth.WithPrincipal(ctx, &auth.Principal{UserID: creator.ID, Username: creator.Username, Roles: creator.Roles})
+
+					key, err := apiKeys.Create(asCreator, &dto.APIKeyRequest{Name: "his", Scopes: []string{string(auth.PermResultsRead), string(auth.PermReportsIssue)}})
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if tt.change != nil {
+						tt.change(t, creator)
+					}
+
+					principal, err := apiKeys.Authenticate(ctx, key.Key)
+					if !errors.Is(err, tt.err) {
+						t.Fatalf("got error %v, want %v", err, tt.err)
+					}
+					if err == nil && !slices.Equal(principal.Scopes, tt.want) {
+						t.Errorf("scopes = %v, want %v", principal.Scopes, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestAPIKeyCreatedByKey checks that a key cannot create keys, which
+// would have no user to act on behalf of.
+func TestAPIKeyCreatedByKey(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			apiKeys := NewAPIKeyUsecase(database.db, database.apiKeys, database.users, database.auditLogs)
+
+			ctx := asAPIKey(context.Background(), auth.PermAPIKeysManage, auth.PermResultsRead)
+			if _, err := apiKeys.Create(ctx, &dto.APIKeyRequest{Name: "his", Scopes: []string{string(auth.PermResultsRead)}}); !errors.Is(err, auth.ErrForbidden) {
+				t.Errorf("got %v, want %v", err, auth.ErrForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/requestmeta"
+)
+
+// auditTrail writes audit log entries in the caller's transaction, so an
+// entry is stored exactly when the change it describes is committed.
+// Snapshots passed as before and after are response DTOs, or for patients
+// a patientAudit; their JSON field names are used in the recorded diff.
+type auditTrail struct {
+	repo repository.AuditLogRepository
+}
+
+// auditFieldChange is the before and after value of one changed field.
+type auditFieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// auditRedactedChange stands for a change to a field whose values are
+// kept out of the audit log.
+type auditRedactedChange struct {
+	Redacted bool `json:"redacted"`
+}
+
+// auditSensitive is implemented by snapshots with fields that are stored
+// encrypted. Their values are not part of the snapshot's JSON; a change to
+// one is recorded by the field name only.
+type auditSensitive interface {
+	auditSensitiveFields() map[string]string
+}
+
+// patientAudit is the audit snapshot of a patient. Address, phone, email
+// and national ID are stored encrypted, so that writing them to the audit
+// log in plain text would defeat the encryption; they are compared but
+// not recorded.
+type patientAudit struct {
+	ID        string         `json:"id"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	Birthdate time.Time      `json:"birth_date"`
+	Sex       entitiy.Gender `json:"sex"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	encrypted map[string]string
+}
+
+func newPatientAudit(patient *entitiy.Patient) *patientAudit {
+	return &patientAudit{
+		ID:        patient.ID,
+		FirstName: patient.FirstName,
+		LastName:  patient.LastName,
+		Birthdate: patient.Birthdate,
+		Sex:       patient.Sex,
+		CreatedAt: patient.CreatedAt,
+		UpdatedAt: patient.UpdatedAt,
+		encrypted: map[string]string{
+			"address":     patient.Address,
+			"phone":       patient.Phone,
+			"email":       patient.Email,
+			"national_id": patient.NationalID,
+		},
+	}
+}
+
+func (p *patientAudit) auditSensitiveFields() map[string]string {
+	return p.encrypted
+}
+
+// auditIgnoredFields change on every write and carry no information.
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+func (a auditTrail) view(ctx context.Context, tx *sql.Tx, entityType, entityID, patientID string) error {
+	return a.record(ctx, tx, entitiy.AuditView, entityType, entityID, patientID, nil, nil)
+}
+
+// auditedEntity identifies one entity of a list recorded by views.
+type auditedEntity struct {
+	ID        string
+	PatientID string
+}
+
+// views records a view of each of entities, as listed by one request, in
+// a single batch rather than one insert per entity.
+func (a auditTrail) views(ctx context.Context, tx *sql.Tx, entityType string, entities []auditedEntity) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	entries := make([]*entitiy.AuditLog, len(entities))
+	for i, entity := range entities {
+		entries[i] = newAuditEntry(ctx, entitiy.AuditView, entityType, entity.ID, entity.PatientID)
+	}
+
+	if err := a.repo.CreateBatch(ctx, tx, entries); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (a auditTrail) created(ctx context.Context, tx *sql.Tx, entityType, entityID, patientID string, after interface{}) error {
+	return a.record(ctx, tx, entitiy.AuditCreate, entityType, entityID, patientID, nil, after)
+}
+
+func (a auditTrail) updated(ctx context.Context, tx *sql.Tx, entityType, entityID, patientID string, before, after interface{}) error {
+	return a.record(ctx, tx, entitiy.AuditUpdate, entityType, entityID, patientID, before, after)
+}
+
+func (a auditTrail) deleted(ctx context.Context, tx *sql.Tx, entityType, entityID, patientID string, before interface{}) error {
+	return a.record(ctx, tx, entitiy.AuditDelete, entityType, entityID, patientID, before, nil)
+}
+
+func (a auditTrail) record(ctx context.Context, tx *sql.Tx, action entitiy.AuditAction, entityType, entityID, patientID string, before, after interface{}) error {
+	entry := newAuditEntry(ctx, action, entityType, entityID, patientID)
+
+	if action != entitiy.AuditView {
+		changes, err := auditDiff(before, after)
+		if err != nil {
+			return err
+		}
+		entry.Changes = changes
+	}
+
+	if err := a.repo.Create(ctx, tx, entry); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// newAuditEntry returns an entry of action by the caller of ctx.
+func newAuditEntry(ctx context.Context, action entitiy.AuditAction, entityType, entityID, patientID string) *entitiy.AuditLog {
+	entry := &entitiy.AuditLog{
+		OccurredAt: time.Now(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		PatientID:  patientID,
+		ClientIP:   requestmeta.ClientIP(ctx),
+		RequestID:  requestmeta.RequestID(ctx),
+	}
+
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		entry.UserID = principal.ActorID()
+		entry.Username = principal.Username
+	}
+
+	return entry
+}
+
+// auditDiff returns the fields that differ between two snapshots as a JSON
+// object. A nil snapshot stands for a record that does not exist, so a
+// create lists every field with a null "from" and a delete every field
+// with a null "to".
+func auditDiff(before, after interface{}) (string, error) {
+	from, err := auditFields(before)
+	if err != nil {
+		return "", err
+	}
+
+	to, err := auditFields(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := make(map[string]interface{})
+
+	for field, value := range from {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if !reflect.DeepEqual(value, to[field]) {
+			changes[field] = auditFieldChange{From: value, To: to[field]}
+		}
+	}
+
+	for field, value := range to {
+		if _, seen := from[field]; seen || auditIgnoredFields[field] {
+			continue
+		}
+		changes[field] = auditFieldChange{To: value}
+	}
+
+	sensitiveFrom, sensitiveTo := auditSensitiveFields(before), auditSensitiveFields(after)
+	for _, fields := range []map[string]string{sensitiveFrom, sensitiveTo} {
+		for field := range fields {
+			if sensitiveFrom[field] != sensitiveTo[field] {
+				changes[field] = auditRedactedChange{Redacted: true}
+			}
+		}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit diff: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// auditSensitiveFields returns the encrypted fields of snapshot, or none
+// if it has none or is nil. A missing field reads as empty, so a create
+// or delete records only the fields that have a value.
+func auditSensitiveFields(snapshot interface{}) map[string]string {
+	sensitive, ok := snapshot.(auditSensitive)
+	if !ok || reflect.ValueOf(snapshot).IsNil() {
+		return nil
+	}
+	return sensitive.auditSensitiveFields()
+}
+
+func auditFields(snapshot interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+
+	if snapshot == nil {
+		return fields, nil
+	}
+
+	if value := reflect.ValueOf(snapshot); value.Kind() == reflect.Ptr && value.IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode audit snapshot: %w", err)
+	}
+
+	return fields, nil
+}
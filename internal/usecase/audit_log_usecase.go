@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type AuditLogUsecase interface {
+	Find(ctx context.Context, req *dto.AuditLogRequest) ([]*dto.AuditLogResponse, error)
+}
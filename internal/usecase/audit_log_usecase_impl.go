@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+type auditLogUsecase struct {
+	db           *sql.DB
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditLogUsecase(db *sql.DB, auditLogRepo repository.AuditLogRepository) AuditLogUsecase {
+	return &auditLogUsecase{
+		db:           db,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (u *auditLogUsecase) Find(ctx context.Context, req *dto.AuditLogRequest) ([]*dto.AuditLogResponse, error) {
+	ctx, span := tracing.Start(ctx, "auditLogUsecase.Find")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermAuditRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "auditLog.Find", time.Now())
+
+	entries, err := u.auditLogRepo.Find(ctx, tx, req.ToFilter())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToAuditLogResponseList(entries), nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+func TestAuditDiffRedactsEncryptedPatientFields(t *testing.T) {
+	patient := &entitiy.Patient{
+		ID:         "p-1",
+		FirstName:  "Budi",
+		LastName:   "Santoso",
+		Birthdate:  time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC),
+		Sex:        entitiy.Gender("male"),
+		Phone:      "081234567890",
+		Email:      "budi@example.com",
+		NationalID: "3201010101010001",
+	}
+
+	updated := *patient
+	updated.FirstName = "Budiman"
+	updated.Phone = "081298765432"
+
+	tests := []struct {
+		name          string
+		before, after interface{}
+		redacted      []string
+		unchanged     []string
+	}{
+		{"create", nil, newPatientAudit(patient), []string{"phone", "email", "national_id"}, []string{"address"}},
+		{"update", newPatientAudit(patient), newPatientAudit(&updated), []string{"phone"}, []string{"email", "national_id", "address"}},
+		{"delete", newPatientAudit(patient), nil, []string{"phone", "email", "national_id"}, []string{"address"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := auditDiff(tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, secret := range []string{patient.Phone, updated.Phone, patient.Email, patient.NationalID} {
+				if strings.Contains(diff, secret) {
+					t.Errorf("diff contains %q: %s", secret, diff)
+				}
+			}
+
+			var changes map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(diff), &changes); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, field := range tt.redacted {
+				if string(changes[field]) != `{"redacted":true}` {
+					t.Errorf("%s = %s, want it recorded as redacted", field, changes[field])
+				}
+			}
+			for _, field := range tt.unchanged {
+				if _, ok := changes[field]; ok {
+					t.Errorf("%s recorded, want it left out", field)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditDiffRecordsPlainFields(t *testing.T) {
+	patient := &entitiy.Patient{ID: "p-1", FirstName: "Budi"}
+	updated := *patient
+	updated.FirstName = "Budiman"
+
+	diff, err := auditDiff(newPatientAudit(patient), newPatientAudit(&updated))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"first_name":{"from":"Budi","to":"Budiman"}}`; diff != want {
+		t.Errorf("diff = %s, want %s", diff, want)
+	}
+}
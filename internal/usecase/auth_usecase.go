@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type AuthUsecase interface {
+	Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string, client dto.ClientInfo) (*dto.TokenResponse, error)
+	Logout(ctx context.Context, principal *auth.Principal) error
+	Authenticate(ctx context.Context, accessToken string) (*auth.Principal, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/config"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+	"github.com/google/uuid"
+)
+
+type authUsecase struct {
+	db          *sql.DB
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	tokens      *auth.TokenManager
+	config      config.AuthConfig
+}
+
+func NewAuthUsecase(db *sql.DB, userRepo repository.UserRepository, sessionRepo repository.SessionRepository, authConfig config.AuthConfig) AuthUsecase {
+	return &authUsecase{
+		db:          db,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokens:      auth.NewTokenManager([]byte(authConfig.JWTSecret), authConfig.AccessTokenTTL),
+		config:      authConfig,
+	}
+}
+
+func (u *authUsecase) Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "authUsecase.Login")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "auth.Login", time.Now())
+
+	now := time.Now()
+
+	// The user is locked before it is read, so that concurrent guesses
+	// each count as a failed attempt.
+	var user *entitiy.User
+	err = u.userRepo.LockByUsername(ctx, tx, req.Username)
+	if err == nil {
+		user, err = u.userRepo.GetByUsername(ctx, tx, req.Username)
+	}
+	if err != nil {
+		auth.CheckPassword("", req.Password)
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	// The password is checked first, so that only a caller who knows it
+	// learns that the account exists and is locked.
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		if user.Locked(now) {
+			return nil, auth.ErrInvalidCredentials
+		}
+
+		// The failed attempt is committed even though the login fails.
+		u.recordFailedLogin(user, now)
+		if err := u.userRepo.Update(ctx, tx, user); err != nil {
+			return nil, fmt.Errorf("failed to record failed login: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	if user.Locked(now) {
+		return nil, auth.ErrAccountLocked
+	}
+
+	if !user.Active {
+		return nil, auth.ErrAccountDisabled
+	}
+
+	user.FailedAttempts = 0
+	user.LockedUntil = nil
+	user.LastLoginAt = &now
+
+	if err := u.userRepo.Update(ctx, tx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	response, err := u.startSession(ctx, tx, user, client, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return response, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The old session
+// is revoked so each refresh token can only be used once.
+func (u *authUsecase) Refresh(ctx context.Context, refreshToken string, client dto.ClientInfo) (*dto.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "authUsecase.Refresh")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "auth.Refresh", time.Now())
+
+	now := time.Now()
+
+	session, err := u.sessionRepo.GetByRefreshTokenHash(ctx, tx, auth.HashToken(refreshToken))
+	if err != nil || !session.Valid(now) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	user, err := u.userRepo.GetByID(ctx, tx, session.UserID)
+	if err != nil || !user.Active || user.Locked(now) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	// A refresh racing with this one on the same token has revoked the
+	// session already; only one of them starts a new session.
+	if err := u.sessionRepo.Revoke(ctx, tx, session.ID, now); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	response, err := u.startSession(ctx, tx, user, client, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return response, nil
+}
+
+func (u *authUsecase) Logout(ctx context.Context, principal *auth.Principal) error {
+	ctx, span := tracing.Start(ctx, "authUsecase.Logout")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "auth.Logout", time.Now())
+
+	// A session revoked in the meantime is logged out already.
+	if err := u.sessionRepo.Revoke(ctx, tx, principal.SessionID, time.Now()); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate verifies an access token and checks that its session has
+// not been revoked and its user is still allowed in.
+func (u *authUsecase) Authenticate(ctx context.Context, accessToken string) (*auth.Principal, error) {
+	ctx, span := tracing.Start(ctx, "authUsecase.Authenticate")
+	defer span.End()
+
+	claims, err := u.tokens.Parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "auth.Authenticate", time.Now())
+
+	now := time.Now()
+
+	session, err := u.sessionRepo.GetByID(ctx, tx, claims.SessionID)
+	if err != nil || !session.Valid(now) || session.UserID != claims.Subject {
+		return nil, auth.ErrInvalidToken
+	}
+
+	user, err := u.userRepo.GetByID(ctx, tx, claims.Subject)
+	if err != nil || !user.Active || user.Locked(now) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return &auth.Principal{
+		UserID:    user.ID,
+		Username:  user.Username,
+		FullName:  user.FullName,
+		SessionID: session.ID,
+		Roles:     user.Roles,
+	}, nil
+}
+
+// DeleteExpiredSessions deletes the sessions whose refresh token has
+// expired, revoked or not, and returns how many were deleted.
+func (u *authUsecase) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "authUsecase.DeleteExpiredSessions")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "auth.DeleteExpiredSessions", time.Now())
+
+	deleted, err := u.sessionRepo.DeleteExpired(ctx, tx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// recordFailedLogin bumps the failure counter and locks the account once
+// it reaches the configured limit.
+func (u *authUsecase) recordFailedLogin(user *entitiy.User, now time.Time) {
+	user.FailedAttempts++
+
+	if u.config.MaxLoginAttempts > 0 && user.FailedAttempts >= u.config.MaxLoginAttempts {
+		lockedUntil := now.Add(u.config.LockoutDuration)
+		user.LockedUntil = &lockedUntil
+		user.FailedAttempts = 0
+	}
+}
+
+func (u *authUsecase) startSession(ctx context.Context, tx *sql.Tx, user *entitiy.User, client dto.ClientInfo, now time.Time) (*dto.TokenResponse, error) {
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &entitiy.Session{
+		ID:               uuid.New().String(),
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		ClientIP:         client.IP,
+		UserAgent:        truncateString(client.UserAgent, 255),
+		ExpiresAt:        now.Add(u.config.RefreshTokenTTL),
+	}
+
+	if err := u.sessionRepo.Create(ctx, tx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	accessToken, expiresAt, err := u.tokens.Issue(user.ID, user.Username, session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             dto.ToUserResponse(user),
+	}, nil
+}
+
+func truncateString(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/config"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+)
+
+const testPassword = "s3cret-pass"
+
+// userLockCheckingRepository holds each read of a user for a while, so
+// that a login racing with another reads the failed attempts before the
+// other writes them unless the user is locked, and counts the reads made
+// in a transaction that has not locked the user.
+type userLockCheckingRepository struct {
+	repository.UserRepository
+	locked        sync.Map
+	unlockedReads atomic.Int32
+}
+
+func (r *userLockCheckingRepository) LockByUsername(ctx context.Context, tx *sql.Tx, username string) error {
+	r.locked.Store(tx, true)
+	return r.UserRepository.LockByUsername(ctx, tx, username)
+}
+
+func (r *userLockCheckingRepository) GetByUsername(ctx context.Context, tx *sql.Tx, username string) (*entitiy.User, error) {
+	if _, ok := r.locked.Load(tx); !ok {
+		r.unlockedReads.Add(1)
+	}
+
+	user, err := r.UserRepository.GetByUsername(ctx, tx, username)
+	time.Sleep(50 * time.Millisecond)
+	return user, err
+}
+
+func testAuthConfig() config.AuthConfig {
+	authConfig := config.Default().Auth
+	authConfig.JWTSecret = "0123456789abcdef0123456789abcdef"
+	authConfig.MaxLoginAttempts = 3
+	return authConfig
+}
+
+// createUser creates an active user with testPassword and roles.
+func createUser(t testing.TB, database *testDatabase, roles ...entitiy.Role) *entitiy.User {
+	t.Helper()
+
+	hash, err := auth.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &entitiy.User{ID: uniqueKey("u-"), Username: uniqueKey("user-"), FullName: "Siti Aminah", PasswordHash: hash, Active: true, Roles: roles}
+
+	ctx := context.Background()
+	tx, err := database.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err := database.users.Create(ctx, tx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.users.SetRoles(ctx, tx, user.ID, roles); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+// TestConcurrentFailedLogins guesses the password of an account with as
+// many logins at once as it takes to lock it. Each guess counts, so the
+// account ends up locked.
+func TestConcurrentFailedLogins(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			ctx := context.Background()
+			authConfig := testAuthConfig()
+			repo := &userLockCheckingRepository{UserRepository: database.users}
+			authUC := NewAuthUsecase(database.db, repo, database.sessions, authConfig)
+			username := createUser(t, database).Username
+
+			errs := make([]error, authConfig.MaxLoginAttempts)
+			var wg sync.WaitGroup
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = authUC.Login(ctx, &dto.LoginRequest{Username: username, Password: "wrong-pass"}, dto.ClientInfo{})
+				}(i)
+			}
+			wg.Wait()
+
+			for _, err := range errs {
+				if !errors.Is(err, auth.ErrInvalidCredentials) {
+					t.Errorf("got %v for a wrong password, want %v", err, auth.ErrInvalidCredentials)
+				}
+			}
+			if n := repo.unlockedReads.Load(); n > 0 {
+				t.Errorf("user was read %d times without locking it", n)
+			}
+
+			if _, err := authUC.Login(ctx, &dto.LoginRequest{Username: username, Password: testPassword}, dto.ClientInfo{}); !errors.Is(err, auth.ErrAccountLocked) {
+				t.Errorf("got %v for the correct password, want %v", err, auth.ErrAccountLocked)
+			}
+		})
+	}
+}
+
+// TestLoginLockedAccount checks that only the correct password reveals
+// that an account is locked.
+func TestLoginLockedAccount(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			ctx := context.Background()
+			authConfig := testAuthConfig()
+			authUC := NewAuthUsecase(database.db, database.users, database.sessions, authConfig)
+			username := createUser(t, database).Username
+
+			for range authConfig.MaxLoginAttempts {
+				if _, err := authUC.Login(ctx, &dto.LoginRequest{Username: username, Password: "wrong-pass"}, dto.ClientInfo{}); !errors.Is(err, auth.ErrInvalidCredentials) {
+					t.Fatalf("got %v for a wrong password, want %v", err, auth.ErrInvalidCredentials)
+				}
+			}
+
+			tests := []struct {
+				name     string
+				username string
+				password string
+				want     error
+			}{
+				{"wrong password", username, "wrong-pass", auth.ErrInvalidCredentials},
+				{"unknown user", uniqueKey("nobody-"), "wrong-pass", auth.ErrInvalidCredentials},
+				{"correct password", username, testPassword, auth.ErrAccountLocked},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					if _, err := authUC.Login(ctx, &dto.LoginRequest{Username: tt.username, Password: tt.password}, dto.ClientInfo{}); !errors.Is(err, tt.want) {
+						t.Errorf("got %v, want %v", err, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestConcurrentRefresh uses one refresh token twice at once. Only one
+// refresh starts a new session; the other finds the token used.
+func TestConcurrentRefresh(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			ctx := context.Background()
+			authUC := NewAuthUsecase(database.db, database.users, database.sessions, testAuthConfig())
+			username := createUser(t, database).Username
+
+			token, err := authUC.Login(ctx, &dto.LoginRequest{Username: username, Password: testPassword}, dto.ClientInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := make([]error, 2)
+			var wg sync.WaitGroup
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = authUC.Refresh(ctx, token.RefreshToken, dto.ClientInfo{})
+				}(i)
+			}
+			wg.Wait()
+
+			var succeeded, refused int
+			for _, err := range errs {
+				switch {
+				case err == nil:
+					succeeded++
+				case errors.Is(err, auth.ErrInvalidToken):
+					refused++
+				default:
+					t.Fatal(err)
+				}
+			}
+			if succeeded != 1 || refused != 1 {
+				t.Errorf("%d refreshes succeeded and %d were refused, want 1 and 1", succeeded, refused)
+			}
+		})
+	}
+}
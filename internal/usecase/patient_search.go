@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/search"
+)
+
+// maxSearchCandidates caps the patients read for one search. Searches that
+// find more are too broad to be ranked usefully; the closest candidates
+// are read first, and the total of the page metadata is then at most this
+// number.
+const maxSearchCandidates = 1000
+
+// rankPatients scores candidates against query, drops the ones that do not
+// match and sorts the rest by page.Sort. Ties are broken by name and ID.
+func rankPatients(query search.Query, candidates []*entitiy.Patient, page entitiy.Page) []*entitiy.Patient {
+	type match struct {
+		patient *entitiy.Patient
+		score   float64
+	}
+
+	var matches []match
+	for _, patient := range candidates {
+		if score, ok := search.Score(query, patient); ok {
+			matches = append(matches, match{patient: patient, score: score})
+		}
+	}
+
+	// compare returns a negative number if a sorts first in ascending
+	// order of the sort field.
+	compare := func(a, b match) int {
+		switch page.Sort {
+		case "first_name":
+			return strings.Compare(strings.ToLower(a.patient.FirstName), strings.ToLower(b.patient.FirstName))
+		case "last_name":
+			return strings.Compare(strings.ToLower(a.patient.LastName), strings.ToLower(b.patient.LastName))
+		case "birth_date":
+			return a.patient.Birthdate.Compare(b.patient.Birthdate)
+		case "created_at":
+			return a.patient.CreatedAt.Compare(b.patient.CreatedAt)
+		default:
+			// Best match first.
+			switch {
+			case a.score > b.score:
+				return -1
+			case a.score < b.score:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		c := compare(matches[i], matches[j])
+		if page.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+
+		a, b := matches[i].patient, matches[j].patient
+		if c := strings.Compare(strings.ToLower(a.LastName+" "+a.FirstName), strings.ToLower(b.LastName+" "+b.FirstName)); c != 0 {
+			return c < 0
+		}
+		return a.ID < b.ID
+	})
+
+	patients := make([]*entitiy.Patient, len(matches))
+	for i, m := range matches {
+		patients[i] = m.patient
+	}
+
+	return patients
+}
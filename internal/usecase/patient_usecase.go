@@ -9,8 +9,9 @@ import (
 type PatientUsecase interface {
 	Create(ctx context.Context, req *dto.PatientRequest) (*dto.PatientResponse, error)
 	GetByID(ctx context.Context, id string) (*dto.PatientResponse, error)
-	Update(ctx context.Context, id string, req *dto.PatientRequest) (*dto.PatientResponse, error)
-	Delete(ctx context.Context, id string) error
-	GetAll(ctx context.Context) ([]*dto.PatientResponse, error)
-	Search(ctx context.Context, query string) ([]*dto.PatientResponse, error)
+	Update(ctx context.Context, id string, req *dto.PatientRequest, ifMatch string) (*dto.PatientResponse, error)
+	Patch(ctx context.Context, id string, req *dto.PatientPatchRequest, ifMatch string) (*dto.PatientResponse, error)
+	Delete(ctx context.Context, id string, ifMatch string) error
+	GetAll(ctx context.Context, req *dto.PatientListRequest) ([]*dto.PatientResponse, *dto.PageMeta, error)
+	Search(ctx context.Context, req *dto.PatientSearchRequest) ([]*dto.PatientResponse, *dto.PageMeta, error)
 }
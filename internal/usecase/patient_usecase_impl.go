@@ -4,29 +4,49 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
 	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/search"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
 	"github.com/google/uuid"
 )
 
 type patientUsecase struct {
 	db          *sql.DB
 	patientRepo repository.PatientRepository
+	audit       auditTrail
 }
 
-func NewPatientUsecase(db *sql.DB, patientRepo repository.PatientRepository) PatientUsecase {
+func NewPatientUsecase(db *sql.DB, patientRepo repository.PatientRepository, auditLogRepo repository.AuditLogRepository) PatientUsecase {
 	return &patientUsecase{
+		db:          db,
 		patientRepo: patientRepo,
+		audit:       auditTrail{repo: auditLogRepo},
 	}
 }
 
 func (u *patientUsecase) Create(ctx context.Context, req *dto.PatientRequest) (*dto.PatientResponse, error) {
+	ctx, span := tracing.Start(ctx, "patientUsecase.Create")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermPatientsWrite); err != nil {
+		return nil, err
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "patient.Create", time.Now())
 
 	id := uuid.New().String()
 
@@ -36,6 +56,10 @@ func (u *patientUsecase) Create(ctx context.Context, req *dto.PatientRequest) (*
 		return nil, fmt.Errorf("failed to create patient: %w", err)
 	}
 
+	if err := u.audit.created(ctx, tx, entitiy.AuditEntityPatient, patient.ID, patient.ID, newPatientAudit(patient)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -44,97 +68,238 @@ func (u *patientUsecase) Create(ctx context.Context, req *dto.PatientRequest) (*
 }
 
 func (u *patientUsecase) GetByID(ctx context.Context, id string) (*dto.PatientResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	ctx, span := tracing.Start(ctx, "patientUsecase.GetByID")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermPatientsRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "patient.GetByID", time.Now())
 
 	patient, err := u.patientRepo.GetByID(ctx, tx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get patient: %w", err)
 	}
 
+	if err := u.audit.view(ctx, tx, entitiy.AuditEntityPatient, patient.ID, patient.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return dto.ToPatientResponse(patient), nil
 }
 
-func (u *patientUsecase) Update(ctx context.Context, id string, req *dto.PatientRequest) (*dto.PatientResponse, error) {
+// Update replaces a patient. A non-empty ifMatch must match the ETag of
+// the current patient.
+func (u *patientUsecase) Update(ctx context.Context, id string, req *dto.PatientRequest, ifMatch string) (*dto.PatientResponse, error) {
+	ctx, span := tracing.Start(ctx, "patientUsecase.Update")
+	defer span.End()
+
+	return u.modify(ctx, "patient.Update", id, ifMatch, req.Validate, req.UpdateEntity)
+}
+
+// Patch changes only the fields present in req.
+func (u *patientUsecase) Patch(ctx context.Context, id string, req *dto.PatientPatchRequest, ifMatch string) (*dto.PatientResponse, error) {
+	ctx, span := tracing.Start(ctx, "patientUsecase.Patch")
+	defer span.End()
+
+	return u.modify(ctx, "patient.Patch", id, ifMatch, req.Validate, req.ApplyTo)
+}
+
+func (u *patientUsecase) Delete(ctx context.Context, id string, ifMatch string) error {
+	ctx, span := tracing.Start(ctx, "patientUsecase.Delete")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermPatientsDelete); err != nil {
+		return err
+	}
+
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "patient.Delete", time.Now())
+
+	if err := u.patientRepo.Lock(ctx, tx, id); err != nil {
+		return fmt.Errorf("failed to get patient: %w", err)
 	}
-	defer tx.Rollback()
 
 	patient, err := u.patientRepo.GetByID(ctx, tx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get patient: %w", err)
+		return fmt.Errorf("failed to get patient: %w", err)
 	}
 
-	req.UpdateEntity(patient)
+	if !dto.MatchesETag(ifMatch, dto.ETag(dto.ToPatientResponse(patient))) {
+		return apperror.ErrPreconditionFailed
+	}
 
-	if err := u.patientRepo.Update(ctx, tx, patient); err != nil {
-		return nil, fmt.Errorf("failed to update patient: %w", err)
+	if err := u.patientRepo.Delete(ctx, tx, id); err != nil {
+		return fmt.Errorf("failed to delete patient: %w", err)
+	}
+
+	if err := u.audit.deleted(ctx, tx, entitiy.AuditEntityPatient, id, id, newPatientAudit(patient)); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToPatientResponse(patient), nil
+	return nil
 }
 
-func (u *patientUsecase) Delete(ctx context.Context, id string) error {
+// GetAll returns one page of patients. Only the patients on the page are
+// recorded as viewed.
+func (u *patientUsecase) GetAll(ctx context.Context, req *dto.PatientListRequest) ([]*dto.PatientResponse, *dto.PageMeta, error) {
+	ctx, span := tracing.Start(ctx, "patientUsecase.GetAll")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermPatientsRead); err != nil {
+		return nil, nil, err
+	}
+
+	filter, err := req.ToFilter()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "patient.GetAll", time.Now())
 
-	if err := u.patientRepo.Delete(ctx, tx, id); err != nil {
-		return fmt.Errorf("failed to delete patient: %w", err)
+	patients, total, err := u.patientRepo.GetAll(ctx, tx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get all patients: %w", err)
+	}
+
+	if err := u.auditPatientViews(ctx, tx, patients); err != nil {
+		return nil, nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return dto.ToPatientResponseList(patients), dto.NewPageMeta(filter.Page, total), nil
 }
 
-func (u *patientUsecase) GetAll(ctx context.Context) ([]*dto.PatientResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+// Search returns one page of the patients matching the search text, best
+// match first unless another sort is requested. Only the patients on the
+// page are recorded as viewed.
+func (u *patientUsecase) Search(ctx context.Context, req *dto.PatientSearchRequest) ([]*dto.PatientResponse, *dto.PageMeta, error) {
+	ctx, span := tracing.Start(ctx, "patientUsecase.Search")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermPatientsRead); err != nil {
+		return nil, nil, err
+	}
+
+	page, err := req.ToPage(dto.PatientSearchSortFields)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, nil, err
+	}
+
+	query := search.Parse(req.Query)
+	if query.Text == "" {
+		return nil, nil, apperror.Field("q", "is required")
 	}
-	defer tx.Rollback()
 
-	patients, err := u.patientRepo.GetAll(ctx, tx)
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "patient.Search", time.Now())
+
+	candidates, err := u.patientRepo.Search(ctx, tx, query, maxSearchCandidates)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all patients: %w", err)
+		return nil, nil, fmt.Errorf("failed to search patients: %w", err)
+	}
+
+	matches := rankPatients(query, candidates, page)
+
+	total := len(matches)
+	matches = matches[min(page.Offset, total):min(page.Offset+page.Limit, total)]
+
+	if err := u.auditPatientViews(ctx, tx, matches); err != nil {
+		return nil, nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToPatientResponseList(patients), nil
+	return dto.ToPatientResponseList(matches), dto.NewPageMeta(page, total), nil
 }
 
-func (u *patientUsecase) Search(ctx context.Context, query string) ([]*dto.PatientResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+// modify validates a change, locks a patient, checks ifMatch against its
+// current ETag, applies the change and stores it. method names the
+// caller in the transaction metrics.
+func (u *patientUsecase) modify(ctx context.Context, method string, id string, ifMatch string, validate func() error, apply func(patient *entitiy.Patient)) (*dto.PatientResponse, error) {
+	if err := auth.Require(ctx, auth.PermPatientsWrite); err != nil {
+		return nil, err
+	}
+
+	if err := validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, method, time.Now())
+
+	if err := u.patientRepo.Lock(ctx, tx, id); err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	patient, err := u.patientRepo.GetByID(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	if !dto.MatchesETag(ifMatch, dto.ETag(dto.ToPatientResponse(patient))) {
+		return nil, apperror.ErrPreconditionFailed
+	}
 
-	patients, err := u.patientRepo.Search(ctx, tx, query)
+	before := newPatientAudit(patient)
+
+	apply(patient)
+
+	if err := u.patientRepo.Update(ctx, tx, patient); err != nil {
+		return nil, fmt.Errorf("failed to update patient: %w", err)
+	}
+
+	updated, err := u.patientRepo.GetByID(ctx, tx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search patients: %w", err)
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	if err := u.audit.updated(ctx, tx, entitiy.AuditEntityPatient, patient.ID, patient.ID, before, newPatientAudit(updated)); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToPatientResponseList(patients), nil
+	return dto.ToPatientResponse(updated), nil
+}
+
+func (u *patientUsecase) auditPatientViews(ctx context.Context, tx *sql.Tx, patients []*entitiy.Patient) error {
+	viewed := make([]auditedEntity, len(patients))
+	for i, patient := range patients {
+		viewed[i] = auditedEntity{ID: patient.ID, PatientID: patient.ID}
+	}
+	return u.audit.views(ctx, tx, entitiy.AuditEntityPatient, viewed)
 }
@@ -0,0 +1,10 @@
+package usecase
+
+import "context"
+
+// PIIProgress is called after each batch with running totals.
+type PIIProgress func(scanned, updated int)
+
+type PIIUsecase interface {
+	Reencrypt(ctx context.Context, batchSize int, progress PIIProgress) (scanned, updated int, err error)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+const defaultPIIBatchSize = 500
+
+type piiUsecase struct {
+	db          *sql.DB
+	patientRepo repository.PatientRepository
+}
+
+// NewPIIUsecase returns the maintenance usecase behind `lis pii`. It runs
+// from the command line, outside any request, so it does no permission
+// checks.
+func NewPIIUsecase(db *sql.DB, patientRepo repository.PatientRepository) PIIUsecase {
+	return &piiUsecase{
+		db:          db,
+		patientRepo: patientRepo,
+	}
+}
+
+// Reencrypt walks every patient and re-seals its PII under the active key.
+// Each batch is committed on its own, so an interrupted run can simply be
+// started again.
+func (u *piiUsecase) Reencrypt(ctx context.Context, batchSize int, progress PIIProgress) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "piiUsecase.Reencrypt")
+	defer span.End()
+
+	if batchSize <= 0 {
+		batchSize = defaultPIIBatchSize
+	}
+
+	lastID := ""
+	scanned, updated := 0, 0
+
+	for {
+		batchLastID, batchScanned, batchUpdated, err := u.reencryptBatch(ctx, lastID, batchSize)
+		if err != nil {
+			return scanned, updated, err
+		}
+
+		lastID = batchLastID
+		scanned += batchScanned
+		updated += batchUpdated
+
+		if progress != nil {
+			progress(scanned, updated)
+		}
+
+		if batchScanned < batchSize {
+			return scanned, updated, nil
+		}
+	}
+}
+
+func (u *piiUsecase) reencryptBatch(ctx context.Context, afterID string, batchSize int) (string, int, int, error) {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return afterID, 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "pii.Reencrypt", time.Now())
+
+	lastID, scanned, updated, err := u.patientRepo.ReencryptBatch(ctx, tx, afterID, batchSize)
+	if err != nil {
+		return afterID, 0, 0, fmt.Errorf("failed to re-encrypt patients: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return afterID, 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return lastID, scanned, updated, nil
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type ReportUsecase interface {
+	GetLabReport(ctx context.Context, noOrder string) (*dto.LabReportResponse, error)
+	Issue(ctx context.Context, noOrder string) (*dto.IssuedReportResponse, error)
+	Verify(ctx context.Context, code string) (*dto.ReportVerificationResponse, error)
+}
@@ -0,0 +1,363 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+type reportUsecase struct {
+	db                *sql.DB
+	workOrderRepo     repository.WorkOrderRepository
+	patientRepo       repository.PatientRepository
+	testCatalogRepo   repository.TestCatalogRepository
+	issuedReportRepo  repository.IssuedReportRepository
+	resultVersionRepo repository.ResultVersionRepository
+	audit             auditTrail
+}
+
+func NewReportUsecase(db *sql.DB, workOrderRepo repository.WorkOrderRepository, patientRepo repository.PatientRepository, testCatalogRepo repository.TestCatalogRepository, issuedReportRepo repository.IssuedReportRepository, resultVersionRepo repository.ResultVersionRepository, auditLogRepo repository.AuditLogRepository) ReportUsecase {
+	return &reportUsecase{
+		db:                db,
+		workOrderRepo:     workOrderRepo,
+		patientRepo:       patientRepo,
+		testCatalogRepo:   testCatalogRepo,
+		issuedReportRepo:  issuedReportRepo,
+		resultVersionRepo: resultVersionRepo,
+		audit:             auditTrail{repo: auditLogRepo},
+	}
+}
+
+func (u *reportUsecase) GetLabReport(ctx context.Context, noOrder string) (*dto.LabReportResponse, error) {
+	ctx, span := tracing.Start(ctx, "reportUsecase.GetLabReport")
+	defer span.End()
+
+	// A report is made of results, so reading one takes both.
+	if err := auth.Require(ctx, auth.PermReportsRead); err != nil {
+		return nil, err
+	}
+	if err := auth.Require(ctx, auth.PermResultsRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "report.GetLabReport", time.Now())
+
+	report, err := u.buildLabReport(ctx, tx, noOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.auditReportView(ctx, tx, report); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return report, nil
+}
+
+// Issue records the report of a work order as handed out to the patient.
+// Issuing unchanged content again returns the existing verification code.
+func (u *reportUsecase) Issue(ctx context.Context, noOrder string) (*dto.IssuedReportResponse, error) {
+	ctx, span := tracing.Start(ctx, "reportUsecase.Issue")
+	defer span.End()
+
+	// Issuing returns the report, so it takes what reading one takes.
+	if err := auth.Require(ctx, auth.PermReportsRead); err != nil {
+		return nil, err
+	}
+	if err := auth.Require(ctx, auth.PermResultsRead); err != nil {
+		return nil, err
+	}
+	if err := auth.Require(ctx, auth.PermReportsIssue); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "report.Issue", time.Now())
+
+	// Concurrent issues of the same report wait for each other, so that
+	// the second one finds the report issued by the first.
+	if err := u.workOrderRepo.Lock(ctx, tx, noOrder); err != nil {
+		return nil, err
+	}
+
+	report, err := u.buildLabReport(ctx, tx, noOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.auditReportView(ctx, tx, report); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashLabReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	issued, err := u.issuedReportRepo.GetLatestByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issued report: %w", err)
+	}
+
+	if issued == nil || issued.ContentHash != hash {
+		code, err := newVerificationCode()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := json.Marshal(dto.ToIssuedReportContent(report))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode issued report: %w", err)
+		}
+
+		issued = &entitiy.IssuedReport{
+			NoOrder:          noOrder,
+			VerificationCode: code,
+			ContentHash:      hash,
+			Status:           report.Status,
+			IssuedAt:         time.Now(),
+			Content:          string(content),
+		}
+
+		if err := u.issuedReportRepo.Create(ctx, tx, issued); err != nil {
+			return nil, fmt.Errorf("failed to issue report: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &dto.IssuedReportResponse{
+		VerificationCode: issued.VerificationCode,
+		ContentHash:      issued.ContentHash,
+		IssuedAt:         issued.IssuedAt,
+		Report:           report,
+	}, nil
+}
+
+// Verify looks up an issued report by its verification code and returns
+// the content it was issued with. The current content of the work order
+// only decides whether the report has been amended since.
+func (u *reportUsecase) Verify(ctx context.Context, code string) (*dto.ReportVerificationResponse, error) {
+	ctx, span := tracing.Start(ctx, "reportUsecase.Verify")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "report.Verify", time.Now())
+
+	issued, err := u.issuedReportRepo.GetByVerificationCode(ctx, tx, normalizeVerificationCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify report: %w", err)
+	}
+
+	var content *dto.IssuedReportContent
+	if issued.Content != "" {
+		content = &dto.IssuedReportContent{}
+		if err := json.Unmarshal([]byte(issued.Content), content); err != nil {
+			return nil, fmt.Errorf("failed to decode issued report: %w", err)
+		}
+	}
+
+	report, err := u.buildLabReport(ctx, tx, issued.NoOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.auditReportView(ctx, tx, report); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashLabReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := u.issuedReportRepo.GetLatestByNoOrder(ctx, tx, issued.NoOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issued report: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	response := dto.ToReportVerificationResponse(issued, content)
+	response.Amended = hash != issued.ContentHash || (latest != nil && latest.ID != issued.ID)
+	if latest != nil && latest.ID != issued.ID {
+		response.LatestIssuedAt = &latest.IssuedAt
+	}
+
+	return response, nil
+}
+
+// auditReportView records that a report was viewed. Public verification
+// lookups are recorded too, without a user.
+func (u *reportUsecase) auditReportView(ctx context.Context, tx *sql.Tx, report *dto.LabReportResponse) error {
+	var patientID string
+	if report.Patient != nil {
+		patientID = report.Patient.ID
+	}
+
+	return u.audit.view(ctx, tx, entitiy.AuditEntityReport, report.NoOrder, patientID)
+}
+
+func (u *reportUsecase) buildLabReport(ctx context.Context, tx *sql.Tx, noOrder string) (*dto.LabReportResponse, error) {
+	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	patient, err := u.patientRepo.GetByID(ctx, tx, workOrder.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	tests, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
+	}
+
+	entries, err := u.testCatalogRepo.GetAll(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog: %w", err)
+	}
+
+	catalog := make(map[string]*entitiy.TestCatalog, len(entries))
+	for _, entry := range entries {
+		catalog[entry.Code] = entry
+	}
+
+	versions, err := u.resultVersionRepo.GetByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result history: %w", err)
+	}
+
+	return dto.ToLabReportResponse(workOrder, patient, tests, catalog, versions), nil
+}
+
+// canonicalReport is the part of a lab report covered by the content hash:
+// the patient identity and every reported value with its sign-off. Fields
+// that change without affecting the clinical content, such as contact
+// details, are left out.
+type canonicalReport struct {
+	NoOrder   string            `json:"no_order"`
+	PatientID string            `json:"patient_id"`
+	FirstName string            `json:"first_name"`
+	LastName  string            `json:"last_name"`
+	Birthdate string            `json:"birth_date"`
+	Sex       string            `json:"sex"`
+	Doctor    string            `json:"doctor"`
+	Status    string            `json:"status"`
+	Results   []canonicalResult `json:"results"`
+}
+
+type canonicalResult struct {
+	TestCode       string `json:"test_code"`
+	Value          string `json:"value"`
+	Unit           string `json:"unit"`
+	ReferenceRange string `json:"reference_range"`
+	Flag           string `json:"flag"`
+	Comment        string `json:"comment"`
+	ValidatedBy    string `json:"validated_by"`
+	AuthorizedBy   string `json:"authorized_by"`
+	AuthorizedAt   string `json:"authorized_at"`
+}
+
+func hashLabReport(report *dto.LabReportResponse) (string, error) {
+	canonical := canonicalReport{
+		NoOrder: report.NoOrder,
+		Doctor:  report.Doctor,
+		Status:  report.Status,
+		Results: []canonicalResult{},
+	}
+
+	if p := report.Patient; p != nil {
+		canonical.PatientID = p.ID
+		canonical.FirstName = p.FirstName
+		canonical.LastName = p.LastName
+		canonical.Birthdate = p.Birthdate.Format("2006-01-02")
+		canonical.Sex = string(p.Sex)
+	}
+
+	for _, section := range report.Sections {
+		for _, result := range section.Results {
+			authorizedAt := ""
+			if result.AuthorizedAt != nil {
+				authorizedAt = result.AuthorizedAt.UTC().Format(time.RFC3339)
+			}
+
+			canonical.Results = append(canonical.Results, canonicalResult{
+				TestCode:       result.TestCode,
+				Value:          result.Value,
+				Unit:           result.Unit,
+				ReferenceRange: result.ReferenceRange,
+				Flag:           result.Flag,
+				Comment:        result.Comment,
+				ValidatedBy:    result.ValidatedBy,
+				AuthorizedBy:   result.AuthorizedBy,
+				AuthorizedAt:   authorizedAt,
+			})
+		}
+	}
+
+	content, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report content: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// verificationAlphabet is Crockford's base32 alphabet, which leaves out
+// letters that are easily confused with digits when read over the phone.
+const verificationAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func newVerificationCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	code := make([]byte, len(buf))
+	for i, b := range buf {
+		code[i] = verificationAlphabet[int(b)%len(verificationAlphabet)]
+	}
+
+	return string(code), nil
+}
+
+// normalizeVerificationCode accepts codes as printed (grouped with a dash)
+// or typed in lower case.
+func normalizeVerificationCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "", "O", "0", "I", "1", "L", "1").Replace(code)
+	return code
+}
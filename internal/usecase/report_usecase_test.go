@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+// TestIssueRequiresReadPermissions checks that issuing a report, which
+// returns it, takes the permissions reading it takes.
+func TestIssueRequiresReadPermissions(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			reports := NewReportUsecase(database.db, database.workOrders, database.patients, database.testCatalog, database.issuedReports, database.resultVersions, database.auditLogs)
+			noOrder := createWorkOrder(t, database, "GLU").NoOrder
+
+			tests := []struct {
+				name   string
+				scopes []auth.Permission
+				err    error
+			}{
+				{"issue only", []auth.Permission{auth.PermReportsIssue}, auth.ErrForbidden},
+				{"without results", []auth.Permission{auth.PermReportsIssue, auth.PermReportsRead}, auth.ErrForbidden},
+				{"without reports", []auth.Permission{auth.PermReportsIssue, auth.PermResultsRead}, auth.ErrForbidden},
+				{"all", []auth.Permission{auth.PermReportsIssue, auth.PermReportsRead, auth.PermResultsRead}, nil},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					_, err := reports.Issue(asAPIKey(context.Background(), tt.scopes...), noOrder)
+					if !errors.Is(err, tt.err) {
+						t.Errorf("got %v, want %v", err, tt.err)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestConcurrentIssue issues the same report twice at once. The issues
+// lock the work order, so the second one returns the report the first one
+// issued instead of issuing it again.
+func TestConcurrentIssue(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			repo := &lockCheckingRepository{WorkOrderRepository: database.workOrders}
+			reports := NewReportUsecase(database.db, repo, database.patients, database.testCatalog, database.issuedReports, database.resultVersions, database.auditLogs)
+			noOrder := createWorkOrder(t, database, "GLU").NoOrder
+			ctx := asUser(context.Background(), entitiy.RoleValidator)
+
+			issued := make([]*dto.IssuedReportResponse, 2)
+			errs := make([]error, 2)
+			var wg sync.WaitGroup
+			for i := range issued {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					issued[i], errs[i] = reports.Issue(ctx, noOrder)
+				}(i)
+			}
+			wg.Wait()
+
+			for _, err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if issued[0].VerificationCode != issued[1].VerificationCode {
+				t.Errorf("report issued twice, under %s and %s", issued[0].VerificationCode, issued[1].VerificationCode)
+			}
+			if n := repo.unlockedReads.Load(); n > 0 {
+				t.Errorf("tests were read %d times without locking the work order", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package usecase
+
+import "context"
+
+// SearchIndexProgress is called after each batch with the running total.
+type SearchIndexProgress func(indexed int)
+
+type SearchIndexUsecase interface {
+	Reindex(ctx context.Context, batchSize int, progress SearchIndexProgress) (indexed int, err error)
+}
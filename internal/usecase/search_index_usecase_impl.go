@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+const defaultSearchIndexBatchSize = 500
+
+type searchIndexUsecase struct {
+	db          *sql.DB
+	patientRepo repository.PatientRepository
+}
+
+// NewSearchIndexUsecase returns the maintenance usecase behind `lis search`.
+// It runs from the command line, outside any request, so it does no
+// permission checks.
+func NewSearchIndexUsecase(db *sql.DB, patientRepo repository.PatientRepository) SearchIndexUsecase {
+	return &searchIndexUsecase{
+		db:          db,
+		patientRepo: patientRepo,
+	}
+}
+
+// Reindex walks every patient and rewrites its name tokens. Each batch is
+// committed on its own, so an interrupted run can simply be started again.
+func (u *searchIndexUsecase) Reindex(ctx context.Context, batchSize int, progress SearchIndexProgress) (int, error) {
+	ctx, span := tracing.Start(ctx, "searchIndexUsecase.Reindex")
+	defer span.End()
+
+	if batchSize <= 0 {
+		batchSize = defaultSearchIndexBatchSize
+	}
+
+	lastID := ""
+	indexed := 0
+
+	for {
+		batchLastID, batchIndexed, err := u.reindexBatch(ctx, lastID, batchSize)
+		if err != nil {
+			return indexed, err
+		}
+
+		lastID = batchLastID
+		indexed += batchIndexed
+
+		if progress != nil {
+			progress(indexed)
+		}
+
+		if batchIndexed < batchSize {
+			return indexed, nil
+		}
+	}
+}
+
+func (u *searchIndexUsecase) reindexBatch(ctx context.Context, afterID string, batchSize int) (string, int, error) {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return afterID, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "searchIndex.Reindex", time.Now())
+
+	lastID, indexed, err := u.patientRepo.ReindexBatch(ctx, tx, afterID, batchSize)
+	if err != nil {
+		return afterID, 0, fmt.Errorf("failed to reindex patients: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return afterID, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return lastID, indexed, nil
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type TestCatalogUsecase interface {
+	Create(ctx context.Context, req *dto.TestCatalogRequest) (*dto.TestCatalogResponse, error)
+	GetByCode(ctx context.Context, code string) (*dto.TestCatalogResponse, error)
+	Update(ctx context.Context, code string, req *dto.TestCatalogRequest) (*dto.TestCatalogResponse, error)
+	Delete(ctx context.Context, code string) error
+	GetAll(ctx context.Context) ([]*dto.TestCatalogResponse, error)
+}
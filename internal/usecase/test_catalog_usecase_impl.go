@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+type testCatalogUsecase struct {
+	db              *sql.DB
+	testCatalogRepo repository.TestCatalogRepository
+}
+
+func NewTestCatalogUsecase(db *sql.DB, testCatalogRepo repository.TestCatalogRepository) TestCatalogUsecase {
+	return &testCatalogUsecase{
+		db:              db,
+		testCatalogRepo: testCatalogRepo,
+	}
+}
+
+func (u *testCatalogUsecase) Create(ctx context.Context, req *dto.TestCatalogRequest) (*dto.TestCatalogResponse, error) {
+	ctx, span := tracing.Start(ctx, "testCatalogUsecase.Create")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermCatalogWrite); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "testCatalog.Create", time.Now())
+
+	test := req.ToEntity()
+
+	if err := u.testCatalogRepo.Create(ctx, tx, test); err != nil {
+		return nil, fmt.Errorf("failed to create test catalog entry: %w", err)
+	}
+
+	created, err := u.testCatalogRepo.GetByCode(ctx, tx, test.Code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToTestCatalogResponse(created), nil
+}
+
+func (u *testCatalogUsecase) GetByCode(ctx context.Context, code string) (*dto.TestCatalogResponse, error) {
+	ctx, span := tracing.Start(ctx, "testCatalogUsecase.GetByCode")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermCatalogRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "testCatalog.GetByCode", time.Now())
+
+	test, err := u.testCatalogRepo.GetByCode(ctx, tx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog entry: %w", err)
+	}
+
+	return dto.ToTestCatalogResponse(test), nil
+}
+
+func (u *testCatalogUsecase) Update(ctx context.Context, code string, req *dto.TestCatalogRequest) (*dto.TestCatalogResponse, error) {
+	ctx, span := tracing.Start(ctx, "testCatalogUsecase.Update")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermCatalogWrite); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "testCatalog.Update", time.Now())
+
+	test, err := u.testCatalogRepo.GetByCode(ctx, tx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog entry: %w", err)
+	}
+
+	req.UpdateEntity(test)
+
+	if err := u.testCatalogRepo.Update(ctx, tx, test); err != nil {
+		return nil, fmt.Errorf("failed to update test catalog entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToTestCatalogResponse(test), nil
+}
+
+func (u *testCatalogUsecase) Delete(ctx context.Context, code string) error {
+	ctx, span := tracing.Start(ctx, "testCatalogUsecase.Delete")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermCatalogWrite); err != nil {
+		return err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "testCatalog.Delete", time.Now())
+
+	if err := u.testCatalogRepo.Delete(ctx, tx, code); err != nil {
+		return fmt.Errorf("failed to delete test catalog entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (u *testCatalogUsecase) GetAll(ctx context.Context) ([]*dto.TestCatalogResponse, error) {
+	ctx, span := tracing.Start(ctx, "testCatalogUsecase.GetAll")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermCatalogRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "testCatalog.GetAll", time.Now())
+
+	tests, err := u.testCatalogRepo.GetAll(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test catalog: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToTestCatalogResponseList(tests), nil
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type TurnaroundUsecase interface {
+	GetMetrics(ctx context.Context, req *dto.TATRequest) ([]*dto.TATGroupResponse, error)
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+type turnaroundUsecase struct {
+	db            *sql.DB
+	workOrderRepo repository.WorkOrderRepository
+}
+
+func NewTurnaroundUsecase(db *sql.DB, workOrderRepo repository.WorkOrderRepository) TurnaroundUsecase {
+	return &turnaroundUsecase{
+		db:            db,
+		workOrderRepo: workOrderRepo,
+	}
+}
+
+func (u *turnaroundUsecase) GetMetrics(ctx context.Context, req *dto.TATRequest) ([]*dto.TATGroupResponse, error) {
+	ctx, span := tracing.Start(ctx, "turnaroundUsecase.GetMetrics")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermMetricsRead); err != nil {
+		return nil, err
+	}
+
+	for _, dimension := range req.GroupBy {
+		switch dimension {
+		case dto.TATGroupTest, dto.TATGroupPriority, dto.TATGroupDepartment:
+		default:
+			return nil, apperror.Field("group_by", "unsupported dimension %q", dimension)
+		}
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "turnaround.GetMetrics", time.Now())
+
+	samples, err := u.workOrderRepo.GetTATSamples(ctx, tx, req.ToFilter())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get turnaround samples: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	type intervals struct {
+		group                                                          map[string]string
+		tests                                                          int
+		orderToReceipt, receiptToResult, resultToReport, orderToReport []float64
+	}
+
+	groups := make(map[string]*intervals)
+	var keys []string
+
+	for _, sample := range samples {
+		group := make(map[string]string, len(req.GroupBy))
+		parts := make([]string, len(req.GroupBy))
+		for i, dimension := range req.GroupBy {
+			group[dimension] = tatDimension(sample, dimension)
+			parts[i] = group[dimension]
+		}
+		key := strings.Join(parts, "\x00")
+
+		g, ok := groups[key]
+		if !ok {
+			g = &intervals{group: group}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+
+		g.tests++
+		g.orderToReceipt = appendInterval(g.orderToReceipt, &sample.OrderedAt, sample.ReceivedAt)
+		g.receiptToResult = appendInterval(g.receiptToResult, sample.ReceivedAt, sample.ResultedAt)
+		g.resultToReport = appendInterval(g.resultToReport, sample.ResultedAt, sample.AuthorizedAt)
+		g.orderToReport = appendInterval(g.orderToReport, &sample.OrderedAt, sample.AuthorizedAt)
+	}
+
+	sort.Strings(keys)
+
+	responses := make([]*dto.TATGroupResponse, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		responses = append(responses, &dto.TATGroupResponse{
+			Group:           g.group,
+			Tests:           g.tests,
+			OrderToReceipt:  tatStats(g.orderToReceipt),
+			ReceiptToResult: tatStats(g.receiptToResult),
+			ResultToReport:  tatStats(g.resultToReport),
+			OrderToReport:   tatStats(g.orderToReport),
+		})
+	}
+
+	return responses, nil
+}
+
+func tatDimension(sample *entitiy.TATSample, dimension string) string {
+	switch dimension {
+	case dto.TATGroupTest:
+		return sample.TestCode
+	case dto.TATGroupPriority:
+		return string(sample.Priority)
+	case dto.TATGroupDepartment:
+		return sample.Department
+	}
+	return ""
+}
+
+// appendInterval adds the minutes between start and end when both steps
+// have happened.
+func appendInterval(minutes []float64, start, end *time.Time) []float64 {
+	if start == nil || end == nil {
+		return minutes
+	}
+	return append(minutes, end.Sub(*start).Minutes())
+}
+
+func tatStats(minutes []float64) dto.TATStats {
+	if len(minutes) == 0 {
+		return dto.TATStats{}
+	}
+
+	sort.Float64s(minutes)
+
+	var sum float64
+	for _, m := range minutes {
+		sum += m
+	}
+
+	return dto.TATStats{
+		Count:      len(minutes),
+		AvgMinutes: roundMinutes(sum / float64(len(minutes))),
+		P50Minutes: roundMinutes(percentile(minutes, 50)),
+		P90Minutes: roundMinutes(percentile(minutes, 90)),
+		P95Minutes: roundMinutes(percentile(minutes, 95)),
+		MaxMinutes: roundMinutes(minutes[len(minutes)-1]),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func roundMinutes(m float64) float64 {
+	return math.Round(m*10) / 10
+}
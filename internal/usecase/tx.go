@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/metrics"
+)
+
+// endTx ends a transaction begun at began by method, such as
+// "patient.Create", and records how long it was open. It is deferred right
+// after the transaction begins: it rolls the transaction back unless it
+// was committed, like a deferred tx.Rollback.
+func endTx(tx *sql.Tx, method string, began time.Time) {
+	outcome := "rollback"
+	if err := tx.Rollback(); errors.Is(err, sql.ErrTxDone) {
+		outcome = "commit"
+	}
+
+	metrics.ObserveTransaction(method, outcome, time.Since(began))
+}
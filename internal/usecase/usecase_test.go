@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/config"
+	"github.com/BioSystems-Indonesia/lis/internal/dialect"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/fieldcrypt"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the migration log out of the test output.
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// testDatabase is a migrated database with the repositories the usecase
+// tests build their usecases from.
+type testDatabase struct {
+	name string
+	db   *sql.DB
+
+	patients       repository.PatientRepository
+	workOrders     repository.WorkOrderRepository
+	testCatalog    repository.TestCatalogRepository
+	issuedReports  repository.IssuedReportRepository
+	resultVersions repository.ResultVersionRepository
+	users          repository.UserRepository
+	sessions       repository.SessionRepository
+	auditLogs      repository.AuditLogRepository
+	apiKeys        repository.APIKeyRepository
+}
+
+// testDatabases returns a migrated SQLite database in a temporary file,
+// and the MySQL and PostgreSQL databases named by LIS_TEST_MYSQL_DSN
+// (with parseTime=true) and LIS_TEST_POSTGRES_DSN when they are set, as
+// the repository tests do. The SQLite database allows several
+// connections, so that concurrent transactions wait for each other as
+// they do in the server.
+func testDatabases(t testing.TB) []*testDatabase {
+	t.Helper()
+
+	// The migrations are read from the repository root.
+	t.Chdir(filepath.Join("..", ".."))
+
+	sqlite, err := config.NewDatabaseConnection(config.DatabaseConfig{
+		Dialect:      dialect.SQLite,
+		Path:         filepath.Join(t.TempDir(), "lis.db"),
+		MaxOpenConns: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	databases := []*testDatabase{newTestDatabase(t, "sqlite", dialect.SQLite, sqlite)}
+
+	for _, server := range []struct {
+		dialect dialect.Dialect
+		env     string
+	}{
+		{dialect.MySQL, "LIS_TEST_MYSQL_DSN"},
+		{dialect.Postgres, "LIS_TEST_POSTGRES_DSN"},
+	} {
+		dsn := os.Getenv(server.env)
+		if dsn == "" {
+			t.Logf("%s not set, skipping %s", server.env, server.dialect)
+			continue
+		}
+
+		db, err := sql.Open(server.dialect.Driver(), dsn)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", server.dialect, err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		databases = append(databases, newTestDatabase(t, string(server.dialect), server.dialect, db))
+	}
+
+	return databases
+}
+
+func newTestDatabase(t testing.TB, name string, d dialect.Dialect, db *sql.DB) *testDatabase {
+	t.Helper()
+
+	if err := config.RunMigrations(db, d); err != nil {
+		t.Fatalf("failed to migrate %s: %v", name, err)
+	}
+
+	return &testDatabase{
+		name:           name,
+		db:             db,
+		patients:       repository.NewPatientRepository(db, d, testKeyring(t)),
+		workOrders:     repository.NewWorkOrderRepository(db, d),
+		testCatalog:    repository.NewTestCatalogRepository(db, d),
+		issuedReports:  repository.NewIssuedReportRepository(db, d),
+		resultVersions: repository.NewResultVersionRepository(db, d),
+		users:          repository.NewUserRepository(db, d),
+		sessions:       repository.NewSessionRepository(db, d),
+		auditLogs:      repository.NewAuditLogRepository(db, d),
+		apiKeys:        repository.NewAPIKeyRepository(db, d),
+	}
+}
+
+func testKeyring(t testing.TB) *fieldcrypt.Keyring {
+	t.Helper()
+
+	key, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexKey, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := fieldcrypt.ParseKeyring("test:"+key, "", indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+// uniqueKey returns prefix followed by a suffix not used by earlier runs,
+// so that tests against a shared database do not collide.
+func uniqueKey(prefix string) string {
+	return prefix + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// asUser returns ctx with a user holding roles as its principal.
+func asUser(ctx context.Context, roles ...entitiy.Role) context.Context {
+	return auth.WithPrincipal(ctx, &auth.Principal{Username: "tester", Roles: roles})
+}
+
+// asAPIKey returns ctx with an API key limited to scopes as its principal.
+func asAPIKey(ctx context.Context, scopes ...auth.Permission) context.Context {
+	return auth.WithPrincipal(ctx, &auth.Principal{Username: "api-key:test", APIKeyID: "key-1", Scopes: scopes})
+}
+
+// mustTx runs fn in a committed transaction and fails the test on error.
+func mustTx(t testing.TB, database *testDatabase, fn func(ctx context.Context, tx *sql.Tx) error) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := database.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createWorkOrder creates a patient and a work order of testCodes for it.
+func createWorkOrder(t testing.TB, database *testDatabase, testCodes ...string) *entitiy.WorkOrder {
+	t.Helper()
+
+	patient := &entitiy.Patient{ID: uniqueKey("p-"), FirstName: "Budi", LastName: "Santoso", Birthdate: time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC), Sex: entitiy.Male}
+	workOrder := &entitiy.WorkOrder{NoOrder: uniqueKey("WO-"), PatientID: patient.ID, Priority: entitiy.PriorityRoutine, TestCode: testCodes}
+
+	ctx := context.Background()
+	tx, err := database.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err := database.patients.Create(ctx, tx, patient); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.workOrders.Create(ctx, tx, workOrder); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	return workOrder
+}
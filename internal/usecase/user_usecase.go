@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type UserUsecase interface {
+	Create(ctx context.Context, req *dto.UserRequest) (*dto.UserResponse, error)
+	GetByID(ctx context.Context, id string) (*dto.UserResponse, error)
+	Update(ctx context.Context, id string, req *dto.UserRequest) (*dto.UserResponse, error)
+	Unlock(ctx context.Context, id string) (*dto.UserResponse, error)
+	GetAll(ctx context.Context) ([]*dto.UserResponse, error)
+	EnsureAdmin(ctx context.Context, username, password string) error
+}
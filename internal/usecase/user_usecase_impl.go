@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+	"github.com/google/uuid"
+)
+
+type userUsecase struct {
+	db       *sql.DB
+	userRepo repository.UserRepository
+}
+
+func NewUserUsecase(db *sql.DB, userRepo repository.UserRepository) UserUsecase {
+	return &userUsecase{
+		db:       db,
+		userRepo: userRepo,
+	}
+}
+
+func (u *userUsecase) Create(ctx context.Context, req *dto.UserRequest) (*dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Create")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermUsersManage); err != nil {
+		return nil, err
+	}
+
+	if err := validateRoles(req.Roles); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "user.Create", time.Now())
+
+	if _, err := u.userRepo.GetByUsername(ctx, tx, req.Username); err == nil {
+		return nil, apperror.Conflict("username %s is already taken", req.Username)
+	}
+
+	user := req.ToEntity()
+	user.ID = uuid.New().String()
+
+	user.PasswordHash, err = auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.Create(ctx, tx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	created, err := u.userRepo.GetByID(ctx, tx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToUserResponse(created), nil
+}
+
+// GetByID returns a user. Every user may read their own account.
+func (u *userUsecase) GetByID(ctx context.Context, id string) (*dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.GetByID")
+	defer span.End()
+
+	if principal := auth.PrincipalFrom(ctx); principal == nil || principal.UserID != id {
+		if err := auth.Require(ctx, auth.PermUsersManage); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "user.GetByID", time.Now())
+
+	user, err := u.userRepo.GetByID(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return dto.ToUserResponse(user), nil
+}
+
+// Update changes the name, roles and active flag of a user and, when a
+// password is given, resets it.
+func (u *userUsecase) Update(ctx context.Context, id string, req *dto.UserRequest) (*dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Update")
+	defer span.End()
+
+	if err := validateRoles(req.Roles); err != nil {
+		return nil, err
+	}
+
+	return u.modify(ctx, "user.Update", id, func(user *entitiy.User) error {
+		// An administrator must not lock themselves out of user management.
+		if auth.PrincipalFrom(ctx).UserID == id && user.HasRole(entitiy.RoleLabAdmin) {
+			if req.Roles != nil && !containsRole(req.Roles, entitiy.RoleLabAdmin) {
+				return apperror.Forbidden("you cannot remove the lab_admin role from your own account")
+			}
+		}
+
+		req.UpdateEntity(user)
+
+		if req.Password != "" {
+			hash, err := auth.HashPassword(req.Password)
+			if err != nil {
+				return err
+			}
+			user.PasswordHash = hash
+		}
+
+		return nil
+	})
+}
+
+// Unlock clears a lockout before it expires.
+func (u *userUsecase) Unlock(ctx context.Context, id string) (*dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.Unlock")
+	defer span.End()
+
+	return u.modify(ctx, "user.Unlock", id, func(user *entitiy.User) error {
+		user.FailedAttempts = 0
+		user.LockedUntil = nil
+		return nil
+	})
+}
+
+func (u *userUsecase) GetAll(ctx context.Context) ([]*dto.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "userUsecase.GetAll")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermUsersManage); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "user.GetAll", time.Now())
+
+	users, err := u.userRepo.GetAll(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	return dto.ToUserResponseList(users), nil
+}
+
+// EnsureAdmin creates the first account when the users table is empty so
+// that a fresh installation can be logged into.
+func (u *userUsecase) EnsureAdmin(ctx context.Context, username, password string) error {
+	ctx, span := tracing.Start(ctx, "userUsecase.EnsureAdmin")
+	defer span.End()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "user.EnsureAdmin", time.Now())
+
+	count, err := u.userRepo.Count(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	if password == "" {
+		return fmt.Errorf("no users exist yet: set ADMIN_PASSWORD to create the initial %s account", username)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("invalid ADMIN_PASSWORD: %w", err)
+	}
+
+	admin := &entitiy.User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		FullName:     "Administrator",
+		PasswordHash: hash,
+		Active:       true,
+		Roles:        []entitiy.Role{entitiy.RoleLabAdmin},
+	}
+
+	if err := u.userRepo.Create(ctx, tx, admin); err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (u *userUsecase) modify(ctx context.Context, method string, id string, apply func(user *entitiy.User) error) (*dto.UserResponse, error) {
+	if err := auth.Require(ctx, auth.PermUsersManage); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, method, time.Now())
+
+	user, err := u.userRepo.GetByID(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := apply(user); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.Update(ctx, tx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := u.userRepo.SetRoles(ctx, tx, user.ID, user.Roles); err != nil {
+		return nil, fmt.Errorf("failed to update user roles: %w", err)
+	}
+
+	updated, err := u.userRepo.GetByID(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToUserResponse(updated), nil
+}
+
+func validateRoles(roles []entitiy.Role) error {
+	for _, role := range roles {
+		if !role.Valid() {
+			return apperror.Field("roles", "unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+func containsRole(roles []entitiy.Role, role entitiy.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
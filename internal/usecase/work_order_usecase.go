@@ -9,9 +9,15 @@ import (
 type WorkOrderUsecase interface {
 	Create(ctx context.Context, req *dto.WorkOrderRequest) (*dto.WorkOrderResponse, error)
 	GetByNoOrder(ctx context.Context, noOrder string) (*dto.WorkOrderResponse, error)
-	Update(ctx context.Context, noOrder string, req *dto.WorkOrderRequest) (*dto.WorkOrderResponse, error)
-	Delete(ctx context.Context, noOrder string) error
-	GetAll(ctx context.Context) ([]*dto.WorkOrderResponse, error)
-	GetByDoctor(ctx context.Context, doctor string) ([]*dto.WorkOrderResponse, error)
-	GetByAnalyst(ctx context.Context, analyst string) ([]*dto.WorkOrderResponse, error)
+	Update(ctx context.Context, noOrder string, req *dto.WorkOrderRequest, ifMatch string) (*dto.WorkOrderResponse, error)
+	Patch(ctx context.Context, noOrder string, req *dto.WorkOrderPatchRequest, ifMatch string) (*dto.WorkOrderResponse, error)
+	Delete(ctx context.Context, noOrder string, ifMatch string) error
+	GetAll(ctx context.Context, req *dto.WorkOrderListRequest) ([]*dto.WorkOrderResponse, *dto.PageMeta, error)
+	GetTests(ctx context.Context, noOrder string) ([]*dto.WorkOrderTestResponse, error)
+	Receive(ctx context.Context, noOrder string, req *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error)
+	RecordResults(ctx context.Context, noOrder string, req *dto.ResultRequest) ([]*dto.WorkOrderTestResponse, error)
+	Validate(ctx context.Context, noOrder string, req *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error)
+	Authorize(ctx context.Context, noOrder string, req *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error)
+	Amend(ctx context.Context, noOrder string, req *dto.AmendRequest) ([]*dto.WorkOrderTestResponse, error)
+	GetHistory(ctx context.Context, noOrder string) (*dto.ResultHistoryResponse, error)
 }
@@ -3,33 +3,60 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
 	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/metrics"
 	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
 	"github.com/google/uuid"
 )
 
 type workOrderUsecase struct {
-	db            *sql.DB
-	workOrderRepo repository.WorkOrderRepository
-	patientRepo   repository.PatientRepository
+	db                *sql.DB
+	workOrderRepo     repository.WorkOrderRepository
+	patientRepo       repository.PatientRepository
+	testCatalogRepo   repository.TestCatalogRepository
+	resultVersionRepo repository.ResultVersionRepository
+	userRepo          repository.UserRepository
+	audit             auditTrail
 }
 
-func NewWorkOrderUsecase(db *sql.DB, workOrderRepo repository.WorkOrderRepository, patientRepo repository.PatientRepository) WorkOrderUsecase {
+func NewWorkOrderUsecase(db *sql.DB, workOrderRepo repository.WorkOrderRepository, patientRepo repository.PatientRepository, testCatalogRepo repository.TestCatalogRepository, resultVersionRepo repository.ResultVersionRepository, userRepo repository.UserRepository, auditLogRepo repository.AuditLogRepository) WorkOrderUsecase {
 	return &workOrderUsecase{
-		db:            db,
-		workOrderRepo: workOrderRepo,
+		db:                db,
+		workOrderRepo:     workOrderRepo,
+		patientRepo:       patientRepo,
+		testCatalogRepo:   testCatalogRepo,
+		resultVersionRepo: resultVersionRepo,
+		userRepo:          userRepo,
+		audit:             auditTrail{repo: auditLogRepo},
 	}
 }
 
 func (u *workOrderUsecase) Create(ctx context.Context, req *dto.WorkOrderRequest) (*dto.WorkOrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Create")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermWorkOrdersWrite); err != nil {
+		return nil, err
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "workOrder.Create", time.Now())
 
 	patientID := uuid.New().String()
 	patient := req.Patient.ToEntity(patientID)
@@ -40,23 +67,44 @@ func (u *workOrderUsecase) Create(ctx context.Context, req *dto.WorkOrderRequest
 
 	workOrder := req.ToEntity(patientID)
 
+	if err := u.assignStaff(ctx, tx, workOrder); err != nil {
+		return nil, err
+	}
+
 	if err := u.workOrderRepo.Create(ctx, tx, workOrder); err != nil {
 		return nil, fmt.Errorf("failed to create work order: %w", err)
 	}
 
+	if err := u.audit.created(ctx, tx, entitiy.AuditEntityPatient, patient.ID, patient.ID, newPatientAudit(patient)); err != nil {
+		return nil, err
+	}
+
+	if err := u.audit.created(ctx, tx, entitiy.AuditEntityWorkOrder, workOrder.NoOrder, patient.ID, dto.ToWorkOrderResponse(workOrder, nil)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	metrics.WorkOrderCreated(string(workOrder.Priority))
+
 	return dto.ToWorkOrderResponse(workOrder, patient), nil
 }
 
 func (u *workOrderUsecase) GetByNoOrder(ctx context.Context, noOrder string) (*dto.WorkOrderResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.GetByNoOrder")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermWorkOrdersRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "workOrder.GetByNoOrder", time.Now())
 
 	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
 	if err != nil {
@@ -68,6 +116,10 @@ func (u *workOrderUsecase) GetByNoOrder(ctx context.Context, noOrder string) (*d
 		return nil, fmt.Errorf("failed to get patient: %w", err)
 	}
 
+	if err := u.audit.view(ctx, tx, entitiy.AuditEntityWorkOrder, workOrder.NoOrder, workOrder.PatientID); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -75,141 +127,723 @@ func (u *workOrderUsecase) GetByNoOrder(ctx context.Context, noOrder string) (*d
 	return dto.ToWorkOrderResponse(workOrder, patient), nil
 }
 
-func (u *workOrderUsecase) Update(ctx context.Context, noOrder string, req *dto.WorkOrderRequest) (*dto.WorkOrderResponse, error) {
+// Update replaces a work order and its patient details. A non-empty
+// ifMatch must match the ETag of the current work order.
+func (u *workOrderUsecase) Update(ctx context.Context, noOrder string, req *dto.WorkOrderRequest, ifMatch string) (*dto.WorkOrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Update")
+	defer span.End()
+
+	return u.modify(ctx, "workOrder.Update", noOrder, ifMatch, req.ValidateUpdate, func(workOrder *entitiy.WorkOrder, patient *entitiy.Patient) {
+		req.Patient.UpdateEntity(patient)
+		req.UpdateEntity(workOrder)
+	})
+}
+
+// Patch changes only the fields present in req.
+func (u *workOrderUsecase) Patch(ctx context.Context, noOrder string, req *dto.WorkOrderPatchRequest, ifMatch string) (*dto.WorkOrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Patch")
+	defer span.End()
+
+	return u.modify(ctx, "workOrder.Patch", noOrder, ifMatch, req.Validate, func(workOrder *entitiy.WorkOrder, patient *entitiy.Patient) {
+		if req.Patient != nil {
+			req.Patient.ApplyTo(patient)
+		}
+		req.ApplyTo(workOrder)
+	})
+}
+
+// Delete deletes a work order that has no results yet.
+func (u *workOrderUsecase) Delete(ctx context.Context, noOrder string, ifMatch string) error {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Delete")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermWorkOrdersDelete); err != nil {
+		return err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "workOrder.Delete", time.Now())
+
+	if err := u.workOrderRepo.Lock(ctx, tx, noOrder); err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	if ifMatch != "" {
+		patient, err := u.patientRepo.GetByID(ctx, tx, workOrder.PatientID)
+		if err != nil {
+			return fmt.Errorf("failed to get patient: %w", err)
+		}
+
+		if !dto.MatchesETag(ifMatch, dto.ETag(dto.ToWorkOrderResponse(workOrder, patient))) {
+			return apperror.ErrPreconditionFailed
+		}
+	}
+
+	// The result history is append-only, so a work order with results
+	// is kept; the database refuses the delete as well.
+	tests, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
+	if err != nil {
+		return fmt.Errorf("failed to get work order tests: %w", err)
+	}
+	for _, test := range tests {
+		if test.Status != entitiy.TestStatusPending && test.Status != entitiy.TestStatusReceived {
+			return apperror.Conflict("work order %s has results and cannot be deleted", noOrder)
+		}
+	}
+
+	if err := u.workOrderRepo.Delete(ctx, tx, noOrder); err != nil {
+		return fmt.Errorf("failed to delete work order: %w", err)
+	}
+
+	if err := u.audit.deleted(ctx, tx, entitiy.AuditEntityWorkOrder, noOrder, workOrder.PatientID, dto.ToWorkOrderResponse(workOrder, nil)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetAll returns one page of work orders. Only the work orders on the page
+// are recorded as viewed.
+func (u *workOrderUsecase) GetAll(ctx context.Context, req *dto.WorkOrderListRequest) ([]*dto.WorkOrderResponse, *dto.PageMeta, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.GetAll")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermWorkOrdersRead); err != nil {
+		return nil, nil, err
+	}
+
+	filter, err := req.ToFilter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "workOrder.GetAll", time.Now())
+
+	workOrders, total, err := u.workOrderRepo.GetAll(ctx, tx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get all work orders: %w", err)
+	}
+
+	patients, err := u.getPatientsForWorkOrders(ctx, tx, workOrders)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get patients: %w", err)
+	}
+
+	if err := u.auditWorkOrderViews(ctx, tx, workOrders); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToWorkOrderResponseList(workOrders, patients), dto.NewPageMeta(filter.Page, total), nil
+}
+
+func (u *workOrderUsecase) GetTests(ctx context.Context, noOrder string) ([]*dto.WorkOrderTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.GetTests")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermResultsRead); err != nil {
+		return nil, err
+	}
+
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "workOrder.GetTests", time.Now())
 
 	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get work order: %w", err)
 	}
 
-	patient, err := u.patientRepo.GetByID(ctx, tx, workOrder.PatientID)
+	if err := u.audit.view(ctx, tx, entitiy.AuditEntityWorkOrder, noOrder, workOrder.PatientID); err != nil {
+		return nil, err
+	}
+
+	tests, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get patient: %w", err)
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
 	}
 
-	req.Patient.UpdateEntity(patient)
-	if err := u.patientRepo.Update(ctx, tx, patient); err != nil {
-		return nil, fmt.Errorf("failed to update patient: %w", err)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToWorkOrderTestResponseList(tests), nil
+}
+
+func (u *workOrderUsecase) Receive(ctx context.Context, noOrder string, req *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Receive")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermSpecimensReceive); err != nil {
+		return nil, err
 	}
 
-	req.UpdateEntity(workOrder)
+	return u.applyTestStep(ctx, "workOrder.Receive", noOrder, req.TestCode, testStep{
+		name: "received",
+		from: []entitiy.TestStatus{entitiy.TestStatusPending},
+		apply: func(test *entitiy.WorkOrderTest, now time.Time) {
+			test.Status = entitiy.TestStatusReceived
+			test.ReceivedAt = &now
+		},
+	})
+}
+
+func (u *workOrderUsecase) RecordResults(ctx context.Context, noOrder string, req *dto.ResultRequest) ([]*dto.WorkOrderTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.RecordResults")
+	defer span.End()
 
-	if err := u.workOrderRepo.Update(ctx, tx, workOrder); err != nil {
-		return nil, fmt.Errorf("failed to update work order: %w", err)
+	if err := auth.Require(ctx, auth.PermResultsWrite); err != nil {
+		return nil, err
+	}
+
+	if len(req.Results) == 0 {
+		return nil, apperror.Field("results", "at least one result is required")
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "workOrder.RecordResults", time.Now())
+
+	workOrder, tests, err := u.getTestsByCode(ctx, tx, noOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	for _, item := range req.Results {
+		test, ok := tests[item.TestCode]
+		if !ok {
+			return nil, apperror.Field("test_code", "test %s is not part of work order %s", item.TestCode, noOrder)
+		}
+
+		if test.Status != entitiy.TestStatusReceived && test.Status != entitiy.TestStatusResulted {
+			return nil, apperror.Conflict("test %s cannot be resulted from status %s", test.TestCode, test.Status)
+		}
+
+		before := dto.ToWorkOrderTestResponse(test)
+		test.Status = entitiy.TestStatusResulted
+		test.ResultValue = item.Value
+		test.ResultFlag = item.Flag
+		test.ResultComment = item.Comment
+		test.ResultedAt = &now
+
+		if err := u.workOrderRepo.UpdateTest(ctx, tx, test); err != nil {
+			return nil, fmt.Errorf("failed to record result: %w", err)
+		}
+
+		if err := u.auditTestChange(ctx, tx, workOrder, before, test); err != nil {
+			return nil, err
+		}
+
+		if err := u.addResultVersion(ctx, tx, test, "", performer(ctx), now); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
+	}
+
+	testCodes := make([]string, len(req.Results))
+	for i, item := range req.Results {
+		testCodes[i] = item.TestCode
+	}
+
+	instruments, err := u.testCatalogRepo.GetInstruments(ctx, tx, testCodes)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToWorkOrderResponse(workOrder, patient), nil
+	for _, testCode := range testCodes {
+		metrics.ResultReceived(instruments[testCode])
+	}
+
+	return dto.ToWorkOrderTestResponseList(updated), nil
+}
+
+func (u *workOrderUsecase) Validate(ctx context.Context, noOrder string, req *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Validate")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermResultsValidate); err != nil {
+		return nil, err
+	}
+
+	return u.applyTestStep(ctx, "workOrder.Validate", noOrder, req.TestCode, testStep{
+		name: "validated",
+		from: []entitiy.TestStatus{entitiy.TestStatusResulted},
+		apply: func(test *entitiy.WorkOrderTest, now time.Time) {
+			test.Status = entitiy.TestStatusValidated
+			test.ValidatedAt = &now
+			test.ValidatedBy = performer(ctx)
+		},
+	})
 }
 
-func (u *workOrderUsecase) Delete(ctx context.Context, noOrder string) error {
+func (u *workOrderUsecase) Authorize(ctx context.Context, noOrder string, req *dto.TestStepRequest) ([]*dto.WorkOrderTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Authorize")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermResultsAuthorize); err != nil {
+		return nil, err
+	}
+
+	return u.applyTestStep(ctx, "workOrder.Authorize", noOrder, req.TestCode, testStep{
+		name: "authorized",
+		from: []entitiy.TestStatus{entitiy.TestStatusValidated},
+		apply: func(test *entitiy.WorkOrderTest, now time.Time) {
+			test.Status = entitiy.TestStatusAuthorized
+			test.AuthorizedAt = &now
+			test.AuthorizedBy = performer(ctx)
+		},
+	})
+}
+
+// Amend corrects a result that has already been released. The previous
+// value stays available in the result history and the test line is marked
+// as amended.
+func (u *workOrderUsecase) Amend(ctx context.Context, noOrder string, req *dto.AmendRequest) ([]*dto.WorkOrderTestResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.Amend")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermResultsAmend); err != nil {
+		return nil, err
+	}
+
+	if req.TestCode == "" {
+		return nil, apperror.Field("test_code", "is required")
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, apperror.Field("reason", "is required to amend a released result")
+	}
+
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "workOrder.Amend", time.Now())
 
-	if err := u.workOrderRepo.Delete(ctx, tx, noOrder); err != nil {
-		return fmt.Errorf("failed to delete work order: %w", err)
+	workOrder, tests, err := u.getTestsByCode(ctx, tx, noOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	test, ok := tests[req.TestCode]
+	if !ok {
+		return nil, apperror.Field("test_code", "test %s is not part of work order %s", req.TestCode, noOrder)
+	}
+
+	if test.Status != entitiy.TestStatusAuthorized {
+		return nil, apperror.Conflict("test %s has not been released; record the result instead of amending it", req.TestCode)
+	}
+
+	before := dto.ToWorkOrderTestResponse(test)
+	now := time.Now()
+	test.ResultValue = req.Value
+	test.ResultFlag = req.Flag
+	test.ResultComment = req.Comment
+	test.AmendedAt = &now
+	test.AmendedBy = performer(ctx)
+
+	if err := u.workOrderRepo.UpdateTest(ctx, tx, test); err != nil {
+		return nil, fmt.Errorf("failed to amend result: %w", err)
+	}
+
+	if err := u.auditTestChange(ctx, tx, workOrder, before, test); err != nil {
+		return nil, err
+	}
+
+	if err := u.addResultVersion(ctx, tx, test, req.Reason, test.AmendedBy, now); err != nil {
+		return nil, err
+	}
+
+	updated, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return dto.ToWorkOrderTestResponseList(updated), nil
 }
 
-func (u *workOrderUsecase) GetAll(ctx context.Context) ([]*dto.WorkOrderResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+func (u *workOrderUsecase) GetHistory(ctx context.Context, noOrder string) (*dto.ResultHistoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "workOrderUsecase.GetHistory")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermResultsRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, "workOrder.GetHistory", time.Now())
 
-	workOrders, err := u.workOrderRepo.GetAll(ctx, tx)
+	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all work orders: %w", err)
+		return nil, fmt.Errorf("failed to get work order: %w", err)
 	}
 
-	patients, err := u.getPatientsForWorkOrders(ctx, tx, workOrders)
+	if err := u.audit.view(ctx, tx, entitiy.AuditEntityWorkOrder, noOrder, workOrder.PatientID); err != nil {
+		return nil, err
+	}
+
+	tests, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get patients: %w", err)
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
+	}
+
+	versions, err := u.resultVersionRepo.GetByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get result history: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToWorkOrderResponseList(workOrders, patients), nil
+	return dto.ToResultHistoryResponse(noOrder, tests, versions), nil
 }
 
-func (u *workOrderUsecase) GetByDoctor(ctx context.Context, doctor string) ([]*dto.WorkOrderResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+func (u *workOrderUsecase) addResultVersion(ctx context.Context, tx *sql.Tx, test *entitiy.WorkOrderTest, reason, changedBy string, at time.Time) error {
+	version := &entitiy.ResultVersion{
+		NoOrder:   test.NoOrder,
+		TestCode:  test.TestCode,
+		Value:     test.ResultValue,
+		Flag:      test.ResultFlag,
+		Comment:   test.ResultComment,
+		Reason:    reason,
+		ChangedBy: changedBy,
+		ChangedAt: at,
+	}
+
+	if err := u.resultVersionRepo.Create(ctx, tx, version); err != nil {
+		return fmt.Errorf("failed to record result history: %w", err)
+	}
+
+	return nil
+}
+
+// modify validates a change, locks a work order and its patient, checks
+// ifMatch against the current ETag, applies the change and stores both.
+// method names the caller in the transaction metrics.
+func (u *workOrderUsecase) modify(ctx context.Context, method string, noOrder string, ifMatch string, validate func() error, apply func(workOrder *entitiy.WorkOrder, patient *entitiy.Patient)) (*dto.WorkOrderResponse, error) {
+	if err := auth.Require(ctx, auth.PermWorkOrdersWrite); err != nil {
+		return nil, err
+	}
+
+	if err := validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, method, time.Now())
 
-	workOrders, err := u.workOrderRepo.GetByDoctor(ctx, tx, doctor)
+	if err := u.workOrderRepo.Lock(ctx, tx, noOrder); err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get work orders by doctor: %w", err)
+		return nil, fmt.Errorf("failed to get work order: %w", err)
 	}
 
-	patients, err := u.getPatientsForWorkOrders(ctx, tx, workOrders)
+	if err := u.patientRepo.Lock(ctx, tx, workOrder.PatientID); err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	patient, err := u.patientRepo.GetByID(ctx, tx, workOrder.PatientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get patients: %w", err)
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	if !dto.MatchesETag(ifMatch, dto.ETag(dto.ToWorkOrderResponse(workOrder, patient))) {
+		return nil, apperror.ErrPreconditionFailed
+	}
+
+	tests, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
+	}
+
+	patientBefore := newPatientAudit(patient)
+	before := dto.ToWorkOrderResponse(workOrder, nil)
+
+	apply(workOrder, patient)
+
+	if err := checkRemovedTests(tests, workOrder.TestCode); err != nil {
+		return nil, err
+	}
+
+	if err := u.assignStaff(ctx, tx, workOrder); err != nil {
+		return nil, err
+	}
+
+	if err := u.patientRepo.Update(ctx, tx, patient); err != nil {
+		return nil, fmt.Errorf("failed to update patient: %w", err)
+	}
+
+	if err := u.workOrderRepo.Update(ctx, tx, workOrder); err != nil {
+		return nil, fmt.Errorf("failed to update work order: %w", err)
+	}
+
+	patient, err = u.patientRepo.GetByID(ctx, tx, workOrder.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+
+	workOrder, err = u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	if err := u.audit.updated(ctx, tx, entitiy.AuditEntityPatient, patient.ID, patient.ID, patientBefore, newPatientAudit(patient)); err != nil {
+		return nil, err
+	}
+
+	if err := u.audit.updated(ctx, tx, entitiy.AuditEntityWorkOrder, workOrder.NoOrder, workOrder.PatientID, before, dto.ToWorkOrderResponse(workOrder, nil)); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToWorkOrderResponseList(workOrders, patients), nil
+	return dto.ToWorkOrderResponse(workOrder, patient), nil
+}
+
+// assignStaff checks that the analyst and doctor of a work order are
+// active accounts with the matching role and copies their names onto it.
+func (u *workOrderUsecase) assignStaff(ctx context.Context, tx *sql.Tx, workOrder *entitiy.WorkOrder) error {
+	if workOrder.AnalystID != "" {
+		name, err := u.staffName(ctx, tx, workOrder.AnalystID, entitiy.RoleAnalyst)
+		if err != nil {
+			return err
+		}
+		workOrder.Analyst = name
+	}
+
+	if workOrder.DoctorID != "" {
+		name, err := u.staffName(ctx, tx, workOrder.DoctorID, entitiy.RoleDoctor)
+		if err != nil {
+			return err
+		}
+		workOrder.Doctor = name
+	}
+
+	return nil
+}
+
+func (u *workOrderUsecase) staffName(ctx context.Context, tx *sql.Tx, userID string, role entitiy.Role) (string, error) {
+	user, err := u.userRepo.GetByID(ctx, tx, userID)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return "", apperror.Field(string(role)+"_id", "no user with ID %s", userID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s: %w", role, err)
+	}
+
+	if !user.Active || !user.HasRole(role) {
+		return "", apperror.Field(string(role)+"_id", "user %s is not an active %s", user.Username, role)
+	}
+
+	return user.FullName, nil
+}
+
+// performer is the name a lifecycle step is signed with: the logged in
+// user. Callers have already passed auth.Require, so a principal exists.
+func performer(ctx context.Context) string {
+	return auth.PrincipalFrom(ctx).DisplayName()
+}
+
+// checkRemovedTests refuses to drop test lines that already have results,
+// since that would discard their history.
+func checkRemovedTests(tests []*entitiy.WorkOrderTest, testCodes []string) error {
+	wanted := make(map[string]bool, len(testCodes))
+	for _, testCode := range testCodes {
+		wanted[testCode] = true
+	}
+
+	for _, test := range tests {
+		if !wanted[test.TestCode] && test.ResultedAt != nil {
+			return apperror.Conflict("test %s already has a result and cannot be removed from the work order", test.TestCode)
+		}
+	}
+
+	return nil
+}
+
+// testStep describes a lifecycle transition of a test line: the statuses
+// it may start from and how it changes the line.
+type testStep struct {
+	name  string
+	from  []entitiy.TestStatus
+	apply func(test *entitiy.WorkOrderTest, now time.Time)
+}
+
+func (s testStep) allowed(status entitiy.TestStatus) bool {
+	for _, from := range s.from {
+		if from == status {
+			return true
+		}
+	}
+	return false
 }
 
-func (u *workOrderUsecase) GetByAnalyst(ctx context.Context, analyst string) ([]*dto.WorkOrderResponse, error) {
-	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+// applyTestStep applies step to the given test codes of a work order. With
+// no test codes it applies to every line currently eligible for the step.
+// method names the caller in the transaction metrics.
+func (u *workOrderUsecase) applyTestStep(ctx context.Context, method string, noOrder string, testCodes []string, step testStep) ([]*dto.WorkOrderTestResponse, error) {
+	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback()
+	defer endTx(tx, method, time.Now())
 
-	workOrders, err := u.workOrderRepo.GetByAnalyst(ctx, tx, analyst)
+	workOrder, tests, err := u.getTestsByCode(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get work orders by analyst: %w", err)
+		return nil, err
 	}
 
-	patients, err := u.getPatientsForWorkOrders(ctx, tx, workOrders)
+	var selected []*entitiy.WorkOrderTest
+	if len(testCodes) == 0 {
+		for _, test := range tests {
+			if step.allowed(test.Status) {
+				selected = append(selected, test)
+			}
+		}
+	} else {
+		for _, testCode := range testCodes {
+			test, ok := tests[testCode]
+			if !ok {
+				return nil, apperror.Field("test_code", "test %s is not part of work order %s", testCode, noOrder)
+			}
+			if !step.allowed(test.Status) {
+				return nil, apperror.Conflict("test %s cannot be %s from status %s", testCode, step.name, test.Status)
+			}
+			selected = append(selected, test)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, apperror.Conflict("no tests of work order %s can be %s", noOrder, step.name)
+	}
+
+	now := time.Now()
+	for _, test := range selected {
+		before := dto.ToWorkOrderTestResponse(test)
+		step.apply(test, now)
+
+		if err := u.workOrderRepo.UpdateTest(ctx, tx, test); err != nil {
+			return nil, fmt.Errorf("failed to update work order test: %w", err)
+		}
+
+		if err := u.auditTestChange(ctx, tx, workOrder, before, test); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get patients: %w", err)
+		return nil, fmt.Errorf("failed to get work order tests: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return dto.ToWorkOrderResponseList(workOrders, patients), nil
+	return dto.ToWorkOrderTestResponseList(updated), nil
 }
 
+// getTestsByCode locks the work order and returns it with its tests keyed
+// by test code. The lock keeps the tests from changing between the read
+// and the write back of the step that changes them.
+func (u *workOrderUsecase) getTestsByCode(ctx context.Context, tx *sql.Tx, noOrder string) (*entitiy.WorkOrder, map[string]*entitiy.WorkOrderTest, error) {
+	if err := u.workOrderRepo.Lock(ctx, tx, noOrder); err != nil {
+		return nil, nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	workOrder, err := u.workOrderRepo.GetByNoOrder(ctx, tx, noOrder)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	tests, err := u.workOrderRepo.GetTests(ctx, tx, noOrder)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get work order tests: %w", err)
+	}
+
+	byCode := make(map[string]*entitiy.WorkOrderTest, len(tests))
+	for _, test := range tests {
+		byCode[test.TestCode] = test
+	}
+
+	return workOrder, byCode, nil
+}
+
+func (u *workOrderUsecase) auditTestChange(ctx context.Context, tx *sql.Tx, workOrder *entitiy.WorkOrder, before *dto.WorkOrderTestResponse, test *entitiy.WorkOrderTest) error {
+	entityID := workOrder.NoOrder + "/" + test.TestCode
+	return u.audit.updated(ctx, tx, entitiy.AuditEntityWorkOrderTest, entityID, workOrder.PatientID, before, dto.ToWorkOrderTestResponse(test))
+}
+
+func (u *workOrderUsecase) auditWorkOrderViews(ctx context.Context, tx *sql.Tx, workOrders []*entitiy.WorkOrder) error {
+	viewed := make([]auditedEntity, len(workOrders))
+	for i, workOrder := range workOrders {
+		viewed[i] = auditedEntity{ID: workOrder.NoOrder, PatientID: workOrder.PatientID}
+	}
+	return u.audit.views(ctx, tx, entitiy.AuditEntityWorkOrder, viewed)
+}
+
+// getPatientsForWorkOrders loads the patients of workOrders, keyed by
+// patient ID, in one batch.
 func (u *workOrderUsecase) getPatientsForWorkOrders(ctx context.Context, tx *sql.Tx, workOrders []*entitiy.WorkOrder) (map[string]*entitiy.Patient, error) {
-	patients := make(map[string]*entitiy.Patient)
+	seen := make(map[string]bool, len(workOrders))
+	var ids []string
 
 	for _, wo := range workOrders {
-		patient, err := u.patientRepo.GetByID(ctx, tx, wo.PatientID)
-		if err != nil {
+		if seen[wo.PatientID] {
 			continue
 		}
-		patients[wo.PatientID] = patient
+		seen[wo.PatientID] = true
+		ids = append(ids, wo.PatientID)
 	}
 
-	return patients, nil
+	return u.patientRepo.GetByIDs(ctx, tx, ids)
 }
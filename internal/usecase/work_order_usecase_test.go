@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+)
+
+// lockCheckingRepository holds each read of the tests of a work order for
+// a while, so that a step racing with another reads before the other
+// writes unless the work order is locked, and counts the reads made in a
+// transaction that has not locked the work order. SQLite serializes
+// transactions anyway, so the count is what catches a missing lock there.
+type lockCheckingRepository struct {
+	repository.WorkOrderRepository
+	locked        sync.Map
+	unlockedReads atomic.Int32
+}
+
+func (r *lockCheckingRepository) Lock(ctx context.Context, tx *sql.Tx, noOrder string) error {
+	r.locked.Store(tx, true)
+	return r.WorkOrderRepository.Lock(ctx, tx, noOrder)
+}
+
+func (r *lockCheckingRepository) GetTests(ctx context.Context, tx *sql.Tx, noOrder string) ([]*entitiy.WorkOrderTest, error) {
+	if _, ok := r.locked.Load(tx); !ok {
+		r.unlockedReads.Add(1)
+	}
+
+	tests, err := r.WorkOrderRepository.GetTests(ctx, tx, noOrder)
+	time.Sleep(50 * time.Millisecond)
+	return tests, err
+}
+
+// TestConcurrentTestSteps runs two steps on the same test of a work order
+// at once. The steps lock the work order, so the second one sees the test
+// as the first one left it and is refused, instead of overwriting it.
+func TestConcurrentTestSteps(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		t.Run(database.name, func(t *testing.T) {
+			repo := &lockCheckingRepository{WorkOrderRepository: database.workOrders}
+			workOrders := NewWorkOrderUsecase(database.db, repo, database.patients, database.testCatalog, database.resultVersions, database.users, database.auditLogs)
+
+			tests := []struct {
+				name  string
+				setup func(ctx context.Context, noOrder string) error
+				step  func(ctx context.Context, noOrder string) error
+			}{
+				{"receive", nil, func(ctx context.Context, noOrder string) error {
+					_, err := workOrders.Receive(asUser(ctx, entitiy.RolePhlebotomist), noOrder, &dto.TestStepRequest{TestCode: []string{"GLU"}})
+					return err
+				}},
+				{"validate", func(ctx context.Context, noOrder string) error {
+					if _, err := workOrders.Receive(asUser(ctx, entitiy.RoleAnalyst), noOrder, &dto.TestStepRequest{}); err != nil {
+						return err
+					}
+					_, err := workOrders.RecordResults(asUser(ctx, entitiy.RoleAnalyst), noOrder, &dto.ResultRequest{Results: []dto.ResultItem{{TestCode: "GLU", Value: "5.4"}}})
+					return err
+				}, func(ctx context.Context, noOrder string) error {
+					_, err := workOrders.Validate(asUser(ctx, entitiy.RoleValidator), noOrder, &dto.TestStepRequest{TestCode: []string{"GLU"}})
+					return err
+				}},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ctx := context.Background()
+					noOrder := createWorkOrder(t, database, "GLU").NoOrder
+
+					if tt.setup != nil {
+						if err := tt.setup(ctx, noOrder); err != nil {
+							t.Fatal(err)
+						}
+					}
+
+					errs := make([]error, 2)
+					var wg sync.WaitGroup
+					for i := range errs {
+						wg.Add(1)
+						go func(i int) {
+							defer wg.Done()
+							errs[i] = tt.step(ctx, noOrder)
+						}(i)
+					}
+					wg.Wait()
+
+					var succeeded, refused int
+					for _, err := range errs {
+						switch {
+						case err == nil:
+							succeeded++
+						case errors.Is(err, apperror.ErrConflict):
+							refused++
+						default:
+							t.Fatal(err)
+						}
+					}
+					if succeeded != 1 || refused != 1 {
+						t.Errorf("%d steps succeeded and %d were refused, want 1 and 1", succeeded, refused)
+					}
+					if n := repo.unlockedReads.Load(); n > 0 {
+						t.Errorf("tests were read %d times without locking the work order", n)
+					}
+				})
+			}
+		})
+	}
+}
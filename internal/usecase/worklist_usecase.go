@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+)
+
+type WorklistUsecase interface {
+	Get(ctx context.Context, req *dto.WorklistRequest) ([]*dto.WorklistItemResponse, error)
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/apperror"
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/dto"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+type worklistUsecase struct {
+	db            *sql.DB
+	workOrderRepo repository.WorkOrderRepository
+	audit         auditTrail
+}
+
+func NewWorklistUsecase(db *sql.DB, workOrderRepo repository.WorkOrderRepository, auditLogRepo repository.AuditLogRepository) WorklistUsecase {
+	return &worklistUsecase{
+		db:            db,
+		workOrderRepo: workOrderRepo,
+		audit:         auditTrail{repo: auditLogRepo},
+	}
+}
+
+func (u *worklistUsecase) Get(ctx context.Context, req *dto.WorklistRequest) ([]*dto.WorklistItemResponse, error) {
+	ctx, span := tracing.Start(ctx, "worklistUsecase.Get")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermWorkOrdersRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "worklist.Get", time.Now())
+
+	items, err := u.workOrderRepo.GetWorklist(ctx, tx, req.ToFilter())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worklist: %w", err)
+	}
+	if len(items) > dto.MaxWorklistItems {
+		return nil, apperror.Invalid("the worklist has more than %d tests; narrow it with from, to, department, instrument or priority", dto.MaxWorklistItems)
+	}
+
+	// The worklist shows patient names; record one view per work order.
+	seen := make(map[string]bool)
+	var viewed []auditedEntity
+	for _, item := range items {
+		if !seen[item.NoOrder] {
+			seen[item.NoOrder] = true
+			viewed = append(viewed, auditedEntity{ID: item.NoOrder, PatientID: item.PatientID})
+		}
+	}
+
+	if err := u.audit.views(ctx, tx, entitiy.AuditEntityWorkOrder, viewed); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dto.ToWorklistResponseList(items), nil
+}
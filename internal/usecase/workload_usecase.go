@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+)
+
+type WorkloadUsecase interface {
+	GetWorkload(ctx context.Context) ([]*entitiy.WorkloadCount, error)
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/BioSystems-Indonesia/lis/internal/auth"
+	"github.com/BioSystems-Indonesia/lis/internal/domain/entitiy"
+	"github.com/BioSystems-Indonesia/lis/internal/repository"
+	"github.com/BioSystems-Indonesia/lis/internal/tracing"
+)
+
+type workloadUsecase struct {
+	db            *sql.DB
+	workOrderRepo repository.WorkOrderRepository
+}
+
+func NewWorkloadUsecase(db *sql.DB, workOrderRepo repository.WorkOrderRepository) WorkloadUsecase {
+	return &workloadUsecase{
+		db:            db,
+		workOrderRepo: workOrderRepo,
+	}
+}
+
+// GetWorkload counts the test lines awaiting work, for the lab gauges of
+// the metrics endpoint.
+func (u *workloadUsecase) GetWorkload(ctx context.Context) ([]*entitiy.WorkloadCount, error) {
+	ctx, span := tracing.Start(ctx, "workloadUsecase.GetWorkload")
+	defer span.End()
+
+	if err := auth.Require(ctx, auth.PermMetricsRead); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer endTx(tx, "workload.GetWorkload", time.Now())
+
+	counts, err := u.workOrderRepo.GetWorkload(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,168 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Required rejects an empty or blank string.
+func Required(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// MaxLength rejects strings longer than n characters.
+func MaxLength(n int) Rule[string] {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+// OneOf rejects values other than the given ones.
+func OneOf[T comparable](values ...T) Rule[T] {
+	return func(value T) string {
+		for _, v := range values {
+			if value == v {
+				return ""
+			}
+		}
+
+		names := make([]string, len(values))
+		for i, v := range values {
+			names[i] = fmt.Sprint(v)
+		}
+		return "must be one of " + strings.Join(names, ", ")
+	}
+}
+
+// Email rejects a string that is not a plain email address. The empty
+// string passes; combine with Required for a mandatory address.
+func Email(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != strings.TrimSpace(value) || address.Name != "" {
+		return "must be a valid email address"
+	}
+
+	_, domain, _ := strings.Cut(address.Address, "@")
+	if !strings.Contains(domain, ".") {
+		return "must be a valid email address"
+	}
+
+	return ""
+}
+
+var indonesianPhone = regexp.MustCompile(`^(\+62|62|0)[2-9][0-9]{7,11}$`)
+
+// Phone rejects a string that is not an Indonesian phone number: a mobile
+// or landline number in the national (0812...) or international
+// (+62812...) form. Spaces, dashes, dots and parentheses are allowed as
+// separators. The empty string passes.
+func Phone(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	stripped := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, value)
+
+	if !indonesianPhone.MatchString(stripped) {
+		return "must be an Indonesian phone number, e.g. 081234567890 or +6281234567890"
+	}
+	return ""
+}
+
+// RequiredTime rejects the zero time.
+func RequiredTime(value time.Time) string {
+	if value.IsZero() {
+		return "is required"
+	}
+	return ""
+}
+
+// NotInFuture rejects times after now.
+func NotInFuture(value time.Time) string {
+	if value.After(time.Now()) {
+		return "must not be in the future"
+	}
+	return ""
+}
+
+// NotBefore rejects times before min.
+func NotBefore(min time.Time) Rule[time.Time] {
+	return func(value time.Time) string {
+		if value.Before(min) {
+			return "must not be before " + min.Format(time.DateOnly)
+		}
+		return ""
+	}
+}
+
+// NotEmpty rejects an empty slice.
+func NotEmpty[T any](values []T) string {
+	if len(values) == 0 {
+		return "must not be empty"
+	}
+	return ""
+}
+
+// Unique rejects a slice holding the same value twice.
+func Unique[T comparable](values []T) string {
+	seen := make(map[T]bool, len(values))
+	for _, value := range values {
+		if seen[value] {
+			return fmt.Sprintf("contains %v more than once", value)
+		}
+		seen[value] = true
+	}
+	return ""
+}
+
+// Each applies rules to every element of a slice and reports the first
+// failing element by its index.
+func Each[T any](rules ...Rule[T]) Rule[[]T] {
+	return func(values []T) string {
+		for i, value := range values {
+			for _, rule := range rules {
+				if message := rule(value); message != "" {
+					return fmt.Sprintf("item %d %s", i+1, message)
+				}
+			}
+		}
+		return ""
+	}
+}
+
+// IfSet applies rules only to a non-zero value, for optional fields that
+// have a default.
+func IfSet[T comparable](rules ...Rule[T]) Rule[T] {
+	return func(value T) string {
+		var zero T
+		if value == zero {
+			return ""
+		}
+		for _, rule := range rules {
+			if message := rule(value); message != "" {
+				return message
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,71 @@
+// Package validation checks request values against declarative rules and
+// reports every invalid field at once. It is used by the HTTP handlers and
+// the usecases alike, so input arriving by other routes than the REST API
+// is held to the same rules.
+//
+// A request type lists its fields with their rules:
+//
+//	return validation.Validate(
+//		validation.Field("first_name", req.FirstName, validation.Required, validation.MaxLength(100)),
+//		validation.Field("email", req.Email, validation.Email),
+//	)
+package validation
+
+import "github.com/BioSystems-Indonesia/lis/internal/apperror"
+
+// Rule checks a value and returns why it is invalid, or "" when it is
+// valid.
+type Rule[T any] func(value T) string
+
+// Result holds the errors found in one or more fields.
+type Result []apperror.FieldError
+
+// Field checks value against rules in order and reports the first rule
+// that fails, so a field gets at most one error.
+func Field[T any](name string, value T, rules ...Rule[T]) Result {
+	for _, rule := range rules {
+		if message := rule(value); message != "" {
+			return Result{{Field: name, Message: message}}
+		}
+	}
+	return nil
+}
+
+// Optional is Field for a field that may be absent, such as a field of a
+// partial update. A nil value is not checked.
+func Optional[T any](name string, value *T, rules ...Rule[T]) Result {
+	if value == nil {
+		return nil
+	}
+	return Field(name, *value, rules...)
+}
+
+// Nested prefixes the field names of the errors in err, as returned by
+// Validate, with name and a dot. Other errors are reported against name.
+func Nested(name string, err error) Result {
+	if err == nil {
+		return nil
+	}
+
+	fields := apperror.Fields(err)
+	if len(fields) == 0 {
+		return Result{{Field: name, Message: apperror.Message(err)}}
+	}
+
+	result := make(Result, len(fields))
+	for i, field := range fields {
+		result[i] = apperror.FieldError{Field: name + "." + field.Field, Message: field.Message}
+	}
+	return result
+}
+
+// Validate combines the results of the fields of a value. It returns nil
+// when every field is valid and otherwise an apperror.ErrValidation error
+// listing all invalid fields.
+func Validate(results ...Result) error {
+	var fields []apperror.FieldError
+	for _, result := range results {
+		fields = append(fields, result...)
+	}
+	return apperror.Validation(fields...)
+}
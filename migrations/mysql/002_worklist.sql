@@ -0,0 +1,24 @@
+-- Create test_catalog table (test definitions with bench assignment)
+CREATE TABLE IF NOT EXISTS test_catalog (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    department VARCHAR(100) NOT NULL,
+    instrument VARCHAR(100),
+    unit VARCHAR(30),
+    reference_range VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_department (department),
+    INDEX idx_instrument (instrument)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- Add priority to work orders
+ALTER TABLE work_orders
+    ADD COLUMN priority ENUM('routine', 'urgent', 'stat') NOT NULL DEFAULT 'routine' AFTER doctor,
+    ADD INDEX idx_priority (priority),
+    ADD INDEX idx_created_at (created_at);
+
+-- Add per-test status to work order test lines
+ALTER TABLE work_order_test_codes
+    ADD COLUMN status ENUM('pending', 'received', 'resulted', 'validated', 'authorized') NOT NULL DEFAULT 'pending' AFTER test_code,
+    ADD INDEX idx_status (status);
@@ -0,0 +1,12 @@
+-- Record results and lifecycle timestamps per test line
+ALTER TABLE work_order_test_codes
+    ADD COLUMN result_value VARCHAR(100) AFTER status,
+    ADD COLUMN result_flag VARCHAR(10) AFTER result_value,
+    ADD COLUMN result_comment TEXT AFTER result_flag,
+    ADD COLUMN received_at TIMESTAMP NULL AFTER result_comment,
+    ADD COLUMN resulted_at TIMESTAMP NULL AFTER received_at,
+    ADD COLUMN validated_at TIMESTAMP NULL AFTER resulted_at,
+    ADD COLUMN validated_by VARCHAR(100) AFTER validated_at,
+    ADD COLUMN authorized_at TIMESTAMP NULL AFTER validated_by,
+    ADD COLUMN authorized_by VARCHAR(100) AFTER authorized_at,
+    ADD INDEX idx_authorized_at (authorized_at);
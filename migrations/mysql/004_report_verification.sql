@@ -0,0 +1,12 @@
+-- Create issued_reports table (one row per distinct issued report content)
+CREATE TABLE IF NOT EXISTS issued_reports (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    no_order VARCHAR(50) NOT NULL,
+    verification_code VARCHAR(20) NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    status ENUM('preliminary', 'final') NOT NULL,
+    issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (no_order) REFERENCES work_orders (no_order) ON DELETE CASCADE,
+    UNIQUE INDEX idx_verification_code (verification_code),
+    INDEX idx_no_order (no_order)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
@@ -0,0 +1,24 @@
+-- Create result_versions table (append-only history of every result change)
+CREATE TABLE IF NOT EXISTS result_versions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    no_order VARCHAR(50) NOT NULL,
+    test_code VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    result_value VARCHAR(100),
+    result_flag VARCHAR(10),
+    result_comment TEXT,
+    reason TEXT,
+    changed_by VARCHAR(100),
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (no_order) REFERENCES work_orders (no_order) ON DELETE CASCADE,
+    UNIQUE INDEX idx_no_order_test_version (no_order, test_code, version)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- Mark test lines amended after release
+ALTER TABLE work_order_test_codes
+    ADD COLUMN amended_at TIMESTAMP NULL AFTER authorized_by,
+    ADD COLUMN amended_by VARCHAR(100) AFTER amended_at;
+
+-- Reports issued after an amendment carry the amended status
+ALTER TABLE issued_reports
+    MODIFY COLUMN status ENUM('preliminary', 'final', 'amended') NOT NULL;
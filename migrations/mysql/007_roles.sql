@@ -0,0 +1,20 @@
+-- Create user_roles table
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id VARCHAR(50) NOT NULL,
+    role ENUM('receptionist', 'phlebotomist', 'analyst', 'validator', 'pathologist', 'lab_admin', 'doctor') NOT NULL,
+    PRIMARY KEY (user_id, role),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
+
+-- Accounts created before roles existed had full access; keep them usable
+INSERT INTO user_roles (user_id, role)
+SELECT id, 'lab_admin' FROM users;
+
+-- Reference analyst and ordering doctor accounts from work orders
+ALTER TABLE work_orders
+    ADD COLUMN analyst_id VARCHAR(50) NULL AFTER analyst,
+    ADD COLUMN doctor_id VARCHAR(50) NULL AFTER doctor,
+    ADD INDEX idx_analyst_id (analyst_id),
+    ADD INDEX idx_doctor_id (doctor_id),
+    ADD CONSTRAINT fk_work_orders_analyst FOREIGN KEY (analyst_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_work_orders_doctor FOREIGN KEY (doctor_id) REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,20 @@
+-- Create audit_logs table (append-only: the application never updates or
+-- deletes rows; restrict the database account to INSERT and SELECT on it).
+-- There are no foreign keys so entries outlive the records they describe.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    occurred_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    user_id VARCHAR(50),
+    username VARCHAR(100),
+    action ENUM('view', 'create', 'update', 'delete') NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    patient_id VARCHAR(50),
+    changes JSON,
+    client_ip VARCHAR(64),
+    request_id VARCHAR(64),
+    INDEX idx_patient_occurred (patient_id, occurred_at),
+    INDEX idx_user_occurred (user_id, occurred_at),
+    INDEX idx_entity (entity_type, entity_id),
+    INDEX idx_occurred_at (occurred_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
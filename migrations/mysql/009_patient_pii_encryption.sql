@@ -0,0 +1,16 @@
+-- Widen contact columns for encrypted values and add national ID
+ALTER TABLE patients
+    DROP INDEX idx_phone,
+    DROP INDEX idx_email,
+    MODIFY COLUMN phone VARCHAR(512),
+    MODIFY COLUMN email VARCHAR(512),
+    ADD COLUMN national_id VARCHAR(512) NULL AFTER email;
+
+-- Blind indexes (keyed hashes) for exact lookups on encrypted columns
+ALTER TABLE patients
+    ADD COLUMN phone_bidx CHAR(64) NULL AFTER national_id,
+    ADD COLUMN email_bidx CHAR(64) NULL AFTER phone_bidx,
+    ADD COLUMN national_id_bidx CHAR(64) NULL AFTER email_bidx,
+    ADD INDEX idx_phone_bidx (phone_bidx),
+    ADD INDEX idx_email_bidx (email_bidx),
+    ADD INDEX idx_national_id_bidx (national_id_bidx);
@@ -0,0 +1,12 @@
+-- Create patient_name_tokens table (one row per word of a patient's first
+-- and last name with its phonetic key, for patient search). Patients
+-- registered before this migration are indexed by `lis search reindex`.
+CREATE TABLE IF NOT EXISTS patient_name_tokens (
+    patient_id VARCHAR(50) NOT NULL,
+    token VARCHAR(100) COLLATE utf8mb4_bin NOT NULL,
+    name_key VARCHAR(100) COLLATE utf8mb4_bin NOT NULL,
+    PRIMARY KEY (patient_id, token),
+    INDEX idx_token (token),
+    INDEX idx_name_key (name_key),
+    FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci;
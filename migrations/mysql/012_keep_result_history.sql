@@ -0,0 +1,9 @@
+-- Keep issued reports and result history when a work order is deleted:
+-- a work order they refer to can no longer be deleted
+ALTER TABLE issued_reports DROP FOREIGN KEY issued_reports_ibfk_1;
+ALTER TABLE issued_reports
+    ADD CONSTRAINT fk_issued_reports_no_order FOREIGN KEY (no_order) REFERENCES work_orders (no_order) ON DELETE RESTRICT;
+
+ALTER TABLE result_versions DROP FOREIGN KEY result_versions_ibfk_1;
+ALTER TABLE result_versions
+    ADD CONSTRAINT fk_result_versions_no_order FOREIGN KEY (no_order) REFERENCES work_orders (no_order) ON DELETE RESTRICT;
@@ -0,0 +1,199 @@
+-- PostgreSQL schema, equivalent to the MySQL migrations up to
+-- 010_api_keys.sql. MySQL ENUM columns are VARCHAR with a CHECK
+-- constraint, and updated_at is set by the repositories on every update.
+
+-- Create users table
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(50) PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    full_name VARCHAR(150) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ NULL,
+    last_login_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+
+-- Create user_roles table
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id VARCHAR(50) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('receptionist', 'phlebotomist', 'analyst', 'validator', 'pathologist', 'lab_admin', 'doctor')),
+    PRIMARY KEY (user_id, role)
+);
+
+-- Create sessions table (one row per refresh token)
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(50) PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    refresh_token_hash CHAR(64) NOT NULL,
+    client_ip VARCHAR(64),
+    user_agent VARCHAR(255),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_refresh_token_hash ON sessions (refresh_token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+-- Create patients table (contact columns hold encrypted values; the
+-- *_bidx columns are blind indexes for exact lookups)
+CREATE TABLE IF NOT EXISTS patients (
+    id VARCHAR(50) PRIMARY KEY,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    birthdate DATE NOT NULL,
+    sex VARCHAR(10) NOT NULL CHECK (sex IN ('male', 'female')),
+    address TEXT,
+    phone VARCHAR(512),
+    email VARCHAR(512),
+    national_id VARCHAR(512) NULL,
+    phone_bidx CHAR(64) NULL,
+    email_bidx CHAR(64) NULL,
+    national_id_bidx CHAR(64) NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_patients_first_name ON patients (first_name);
+CREATE INDEX IF NOT EXISTS idx_patients_last_name ON patients (last_name);
+CREATE INDEX IF NOT EXISTS idx_patients_phone_bidx ON patients (phone_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_email_bidx ON patients (email_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_national_id_bidx ON patients (national_id_bidx);
+
+-- Create work_orders table
+CREATE TABLE IF NOT EXISTS work_orders (
+    no_order VARCHAR(50) PRIMARY KEY,
+    patient_id VARCHAR(50) NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    analyst VARCHAR(100) NOT NULL,
+    analyst_id VARCHAR(50) NULL REFERENCES users (id) ON DELETE SET NULL,
+    doctor VARCHAR(100) NOT NULL,
+    doctor_id VARCHAR(50) NULL REFERENCES users (id) ON DELETE SET NULL,
+    priority VARCHAR(10) NOT NULL DEFAULT 'routine' CHECK (priority IN ('routine', 'urgent', 'stat')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_orders_patient_id ON work_orders (patient_id);
+CREATE INDEX IF NOT EXISTS idx_work_orders_analyst ON work_orders (analyst);
+CREATE INDEX IF NOT EXISTS idx_work_orders_analyst_id ON work_orders (analyst_id);
+CREATE INDEX IF NOT EXISTS idx_work_orders_doctor ON work_orders (doctor);
+CREATE INDEX IF NOT EXISTS idx_work_orders_doctor_id ON work_orders (doctor_id);
+CREATE INDEX IF NOT EXISTS idx_work_orders_priority ON work_orders (priority);
+CREATE INDEX IF NOT EXISTS idx_work_orders_created_at ON work_orders (created_at);
+
+-- Create work_order_test_codes table (one row per test line, with its
+-- result and lifecycle timestamps)
+CREATE TABLE IF NOT EXISTS work_order_test_codes (
+    id SERIAL PRIMARY KEY,
+    no_order VARCHAR(50) NOT NULL REFERENCES work_orders (no_order) ON DELETE CASCADE,
+    test_code VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'received', 'resulted', 'validated', 'authorized')),
+    result_value VARCHAR(100),
+    result_flag VARCHAR(10),
+    result_comment TEXT,
+    received_at TIMESTAMPTZ NULL,
+    resulted_at TIMESTAMPTZ NULL,
+    validated_at TIMESTAMPTZ NULL,
+    validated_by VARCHAR(100),
+    authorized_at TIMESTAMPTZ NULL,
+    authorized_by VARCHAR(100),
+    amended_at TIMESTAMPTZ NULL,
+    amended_by VARCHAR(100),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_test_codes_no_order ON work_order_test_codes (no_order);
+CREATE INDEX IF NOT EXISTS idx_work_order_test_codes_test_code ON work_order_test_codes (test_code);
+CREATE INDEX IF NOT EXISTS idx_work_order_test_codes_status ON work_order_test_codes (status);
+CREATE INDEX IF NOT EXISTS idx_work_order_test_codes_authorized_at ON work_order_test_codes (authorized_at);
+
+-- Create test_catalog table (test definitions with bench assignment)
+CREATE TABLE IF NOT EXISTS test_catalog (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    department VARCHAR(100) NOT NULL,
+    instrument VARCHAR(100),
+    unit VARCHAR(30),
+    reference_range VARCHAR(100),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_catalog_department ON test_catalog (department);
+CREATE INDEX IF NOT EXISTS idx_test_catalog_instrument ON test_catalog (instrument);
+
+-- Create issued_reports table (one row per distinct issued report content)
+CREATE TABLE IF NOT EXISTS issued_reports (
+    id SERIAL PRIMARY KEY,
+    no_order VARCHAR(50) NOT NULL REFERENCES work_orders (no_order) ON DELETE CASCADE,
+    verification_code VARCHAR(20) NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('preliminary', 'final', 'amended')),
+    issued_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issued_reports_verification_code ON issued_reports (verification_code);
+CREATE INDEX IF NOT EXISTS idx_issued_reports_no_order ON issued_reports (no_order);
+
+-- Create result_versions table (append-only history of every result change)
+CREATE TABLE IF NOT EXISTS result_versions (
+    id SERIAL PRIMARY KEY,
+    no_order VARCHAR(50) NOT NULL REFERENCES work_orders (no_order) ON DELETE CASCADE,
+    test_code VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    result_value VARCHAR(100),
+    result_flag VARCHAR(10),
+    result_comment TEXT,
+    reason TEXT,
+    changed_by VARCHAR(100),
+    changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_result_versions_no_order_test_version ON result_versions (no_order, test_code, version);
+
+-- Create audit_logs table (append-only: the application never updates or
+-- deletes rows; restrict the database account to INSERT and SELECT on it).
+-- There are no foreign keys so entries outlive the records they describe.
+-- changes holds a JSON object as text.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id VARCHAR(50),
+    username VARCHAR(100),
+    action VARCHAR(10) NOT NULL CHECK (action IN ('view', 'create', 'update', 'delete')),
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    patient_id VARCHAR(50),
+    changes TEXT,
+    client_ip VARCHAR(64),
+    request_id VARCHAR(64)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_patient_occurred ON audit_logs (patient_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_occurred ON audit_logs (user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_occurred_at ON audit_logs (occurred_at);
+
+-- Create api_keys table (long-lived keys for other systems; only the
+-- SHA-256 hash of a key is stored, scopes are a comma-separated list)
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(1000) NOT NULL,
+    created_by VARCHAR(50) REFERENCES users (id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NULL,
+    last_used_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
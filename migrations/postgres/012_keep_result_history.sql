@@ -0,0 +1,9 @@
+-- Keep issued reports and result history when a work order is deleted:
+-- a work order they refer to can no longer be deleted
+ALTER TABLE issued_reports
+    DROP CONSTRAINT issued_reports_no_order_fkey,
+    ADD CONSTRAINT issued_reports_no_order_fkey FOREIGN KEY (no_order) REFERENCES work_orders (no_order) ON DELETE RESTRICT;
+
+ALTER TABLE result_versions
+    DROP CONSTRAINT result_versions_no_order_fkey,
+    ADD CONSTRAINT result_versions_no_order_fkey FOREIGN KEY (no_order) REFERENCES work_orders (no_order) ON DELETE RESTRICT;
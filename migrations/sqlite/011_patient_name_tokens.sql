@@ -0,0 +1,12 @@
+-- Create patient_name_tokens table (one row per word of a patient's first
+-- and last name with its phonetic key, for patient search). Patients
+-- registered before this migration are indexed by `lis search reindex`.
+CREATE TABLE IF NOT EXISTS patient_name_tokens (
+    patient_id VARCHAR(50) NOT NULL REFERENCES patients (id) ON DELETE CASCADE,
+    token VARCHAR(100) NOT NULL,
+    name_key VARCHAR(100) NOT NULL,
+    PRIMARY KEY (patient_id, token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_name_tokens_token ON patient_name_tokens (token);
+CREATE INDEX IF NOT EXISTS idx_patient_name_tokens_name_key ON patient_name_tokens (name_key);
@@ -0,0 +1,43 @@
+-- Keep issued reports and result history when a work order is deleted:
+-- a work order they refer to can no longer be deleted. SQLite cannot
+-- alter a foreign key, so the tables are rebuilt.
+CREATE TABLE issued_reports_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    no_order VARCHAR(50) NOT NULL REFERENCES work_orders (no_order) ON DELETE RESTRICT,
+    verification_code VARCHAR(20) NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('preliminary', 'final', 'amended')),
+    issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO issued_reports_new (id, no_order, verification_code, content_hash, status, issued_at)
+SELECT id, no_order, verification_code, content_hash, status, issued_at FROM issued_reports;
+
+DROP TABLE issued_reports;
+
+ALTER TABLE issued_reports_new RENAME TO issued_reports;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issued_reports_verification_code ON issued_reports (verification_code);
+CREATE INDEX IF NOT EXISTS idx_issued_reports_no_order ON issued_reports (no_order);
+
+CREATE TABLE result_versions_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    no_order VARCHAR(50) NOT NULL REFERENCES work_orders (no_order) ON DELETE RESTRICT,
+    test_code VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    result_value VARCHAR(100),
+    result_flag VARCHAR(10),
+    result_comment TEXT,
+    reason TEXT,
+    changed_by VARCHAR(100),
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO result_versions_new (id, no_order, test_code, version, result_value, result_flag, result_comment, reason, changed_by, changed_at)
+SELECT id, no_order, test_code, version, result_value, result_flag, result_comment, reason, changed_by, changed_at FROM result_versions;
+
+DROP TABLE result_versions;
+
+ALTER TABLE result_versions_new RENAME TO result_versions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_result_versions_no_order_test_version ON result_versions (no_order, test_code, version);
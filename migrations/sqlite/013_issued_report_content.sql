@@ -0,0 +1,3 @@
+-- Keep what an issued report showed, so that its verification code
+-- answers with the issued content rather than the current one
+ALTER TABLE issued_reports ADD COLUMN content TEXT;